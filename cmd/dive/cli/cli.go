@@ -47,6 +47,9 @@ func create(id clio.Identification) (clio.Application, *cobra.Command) {
 		clio.VersionCommand(id),
 		clio.ConfigCommand(app, nil),
 		command.Build(app),
+		command.Share(app),
+		command.Tree(app),
+		command.Layers(app),
 	)
 
 	return app, rootCmd
@@ -3,6 +3,8 @@ package cli
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -36,6 +38,24 @@ func Test_CI_Fail(t *testing.T) {
 
 }
 
+func Test_CI_MarkdownGate(t *testing.T) {
+	dest := t.TempDir()
+	config := filepath.Join(dest, "dive-ci.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("rules:\n  lowest-efficiency: '0.99'\n"), 0600))
+	t.Setenv("DIVE_CONFIG", config)
+
+	file := filepath.Join(dest, "summary.md")
+	rootCmd := getTestCommand(t, "docker-archive://"+repoPath(t, ".data/test-docker-image.tar")+" --ci --markdown "+file)
+	Capture().WithStdout().WithStderr().WithSuppress().Run(t, func() {
+		// the summary is written, but the failing gate should still result in a non-zero exit code
+		require.Error(t, rootCmd.Execute())
+	})
+
+	contents, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), ":x:")
+}
+
 func Test_CI_LegacyRules(t *testing.T) {
 	t.Setenv("DIVE_CONFIG", "./testdata/config/dive-ci-legacy.yaml")
 
@@ -10,10 +10,11 @@ import (
 
 func Test_JsonOutput(t *testing.T) {
 
+	// the versioned format is covered by the export package snapshots, this guards the legacy format
 	t.Run("json output", func(t *testing.T) {
 		dest := t.TempDir()
 		file := filepath.Join(dest, "output.json")
-		rootCmd := getTestCommand(t, "busybox:1.37.0@sha256:ad9fa4d07136a83e69a54ef00102f579d04eba431932de3b0f098cc5d5948f9f --json "+file)
+		rootCmd := getTestCommand(t, "busybox:1.37.0@sha256:ad9fa4d07136a83e69a54ef00102f579d04eba431932de3b0f098cc5d5948f9f --json-format legacy --json "+file)
 		combined := Capture().WithStdout().WithStderr().Run(t, func() {
 			require.NoError(t, rootCmd.Execute())
 		})
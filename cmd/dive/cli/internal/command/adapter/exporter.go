@@ -9,55 +9,78 @@ import (
 	"github.com/wagoodman/dive/internal/bus"
 	"github.com/wagoodman/dive/internal/bus/event/payload"
 	"github.com/wagoodman/dive/internal/log"
-	"os"
+	"io"
 )
 
+// Output is a single export format: a rendering of the analysis that is written to a file.
+type Output struct {
+	// Name describes the output in logs and errors (e.g. "SBOM")
+	Name string
+	// Title is shown for the task while the output is written
+	Title payload.Title
+	// Render writes the contents of the file
+	Render func(ctx context.Context, analysis *image.Analysis, w io.Writer) error
+}
+
 type Exporter interface {
-	ExportTo(ctx context.Context, img *image.Analysis, path string) error
+	ExportTo(ctx context.Context, img *image.Analysis, output Output, path string) error
 }
 
-type jsonExporter struct {
+type fileExporter struct {
 	filesystem afero.Fs
 }
 
+// NewExporter writes outputs of the analysis to files on the given filesystem.
 func NewExporter(fs afero.Fs) Exporter {
-	return &jsonExporter{
+	return &fileExporter{
 		filesystem: fs,
 	}
 }
 
-func (e *jsonExporter) ExportTo(ctx context.Context, analysis *image.Analysis, path string) error {
-	log.WithFields("path", path).Infof("exporting analysis")
+func (e *fileExporter) ExportTo(ctx context.Context, analysis *image.Analysis, output Output, path string) error {
+	log.WithFields("path", path).Infof("exporting %s", output.Name)
 
 	mon := bus.StartTask(payload.GenericTask{
-		Title: payload.Title{
-			Default:      "Exporting details",
-			WhileRunning: "Exporting details",
-			OnSuccess:    "Exported details",
-		},
+		Title:              output.Title,
 		HideOnSuccess:      false,
 		HideStageOnSuccess: false,
 		ID:                 analysis.Image,
 		Context:            fmt.Sprintf("[file: %s]", path),
 	})
 
-	bytes, err := export.NewExport(analysis).Marshal()
+	file, err := e.filesystem.Create(path)
 	if err != nil {
 		mon.SetError(err)
-		return fmt.Errorf("cannot marshal export payload: %w", err)
-	} else {
-		mon.SetCompleted()
-	}
-
-	file, err := e.filesystem.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		return fmt.Errorf("cannot open export file: %w", err)
+		return fmt.Errorf("cannot open %s file: %w", output.Name, err)
 	}
 	defer file.Close()
 
-	_, err = file.Write(bytes)
-	if err != nil {
-		return fmt.Errorf("cannot write to export file: %w", err)
+	if err := output.Render(ctx, analysis, file); err != nil {
+		mon.SetError(err)
+		return fmt.Errorf("cannot write %s: %w", output.Name, err)
 	}
+	if err := file.Close(); err != nil {
+		mon.SetError(err)
+		return fmt.Errorf("cannot write %s: %w", output.Name, err)
+	}
+	mon.SetCompleted()
 	return nil
 }
+
+// JSONOutput writes the analysis as JSON in the given format (see export.Formats).
+func JSONOutput(format string) Output {
+	return Output{
+		Name: "analysis",
+		Title: payload.Title{
+			Default:      "Exporting details",
+			WhileRunning: "Exporting details",
+			OnSuccess:    "Exported details",
+		},
+		Render: func(_ context.Context, analysis *image.Analysis, w io.Writer) error {
+			if format == export.FormatLegacy {
+				return export.NewLegacyExport(analysis).Encode(w)
+			}
+			return export.NewExport(analysis).Encode(w)
+		},
+	}
+}
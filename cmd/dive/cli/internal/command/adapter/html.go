@@ -0,0 +1,30 @@
+package adapter
+
+import (
+	"context"
+	"io"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/report"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+)
+
+// HTMLOutput writes a self-contained HTML report of the analysis.
+func HTMLOutput() Output {
+	return Output{
+		Name: "HTML report",
+		Title: payload.Title{
+			Default:      "Rendering report",
+			WhileRunning: "Rendering report",
+			OnSuccess:    "Rendered report",
+		},
+		Render: func(_ context.Context, analysis *image.Analysis, w io.Writer) error {
+			bytes, err := report.Document{Analysis: analysis}.HTML()
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(bytes)
+			return err
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package adapter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/snapshot"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+type ExportLoader interface {
+	Load(ctx context.Context, path string) (*image.Image, error)
+}
+
+type exportLoader struct {
+	filesystem afero.Fs
+}
+
+// NewExportLoader rebuilds an image from a previous JSON export (see --json) or snapshot (see --snapshot), without the
+// engine or the archive.
+func NewExportLoader(fs afero.Fs) ExportLoader {
+	return &exportLoader{
+		filesystem: fs,
+	}
+}
+
+func (l *exportLoader) Load(_ context.Context, path string) (*image.Image, error) {
+	log.WithFields("path", path).Infof("loading exported analysis")
+
+	mon := bus.StartTask(payload.GenericTask{
+		Title: payload.Title{
+			Default:      "Loading export",
+			WhileRunning: "Loading export",
+			OnSuccess:    "Loaded export",
+		},
+		HideOnSuccess:      false,
+		HideStageOnSuccess: false,
+		ID:                 path,
+		Context:            fmt.Sprintf("[file: %s]", path),
+	})
+
+	img, err := l.load(path)
+	if err != nil {
+		mon.SetError(err)
+		return nil, err
+	}
+	mon.SetCompleted()
+
+	if img.Request == "" {
+		// the legacy format does not record the image name
+		img.Request = path
+	}
+	return img, nil
+}
+
+func (l *exportLoader) load(path string) (*image.Image, error) {
+	file, err := l.filesystem.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read export: %w", err)
+	}
+	defer file.Close()
+
+	if br := bufio.NewReader(file); snapshot.Is(br) {
+		img, err := snapshot.Read(br)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read snapshot: %w", err)
+		}
+		log.WithFields("image", img.Request, "digest", img.Digest).Debug("loaded snapshot")
+		return img, nil
+	}
+
+	// the format check may have buffered the start of the file already
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("cannot read export: %w", err)
+	}
+	exp, err := export.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse export: %w", err)
+	}
+
+	img, err := exp.ToImage()
+	if err != nil {
+		return nil, fmt.Errorf("cannot rebuild image from export: %w", err)
+	}
+	return img, nil
+}
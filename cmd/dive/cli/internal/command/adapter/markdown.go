@@ -0,0 +1,55 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/ci"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/markdown"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+)
+
+// MarkdownOutput writes a markdown summary of the analysis, including the results of the given CI rules and the
+// changes since the JSON export at the baseline path (when given, read from the given filesystem).
+func MarkdownOutput(fs afero.Fs, rules []ci.Rule, baselinePath string) Output {
+	return Output{
+		Name: "markdown summary",
+		Title: payload.Title{
+			Default:      "Summarizing analysis",
+			WhileRunning: "Summarizing analysis",
+			OnSuccess:    "Summarized analysis",
+		},
+		Render: func(ctx context.Context, analysis *image.Analysis, w io.Writer) error {
+			var baseline *export.Export
+			if baselinePath != "" {
+				contents, err := afero.ReadFile(fs, baselinePath)
+				if err != nil {
+					return fmt.Errorf("cannot read baseline export: %w", err)
+				}
+				baseline, err = export.Unmarshal(contents)
+				if err != nil {
+					return fmt.Errorf("cannot parse baseline export: %w", err)
+				}
+			}
+
+			// the CI rules are evaluated for the summary only, the terminal report is not shown
+			eval := ci.NewEvaluator(rules).Evaluate(ctx, analysis)
+
+			bytes, err := markdown.Summary{
+				Analysis:   analysis,
+				Evaluation: &eval,
+				Baseline:   baseline,
+			}.Render()
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(bytes)
+			return err
+		},
+	}
+}
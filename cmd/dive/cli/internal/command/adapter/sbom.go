@@ -0,0 +1,40 @@
+package adapter
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/sbom"
+	"github.com/wagoodman/dive/dive/catalog"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// SBOMOutput writes a software bill of materials of the installed packages in the given format (see sbom.Formats).
+func SBOMOutput(format string) Output {
+	return Output{
+		Name: format + " SBOM",
+		Title: payload.Title{
+			Default:      "Cataloging packages",
+			WhileRunning: "Cataloging packages",
+			OnSuccess:    "Cataloged packages",
+		},
+		Render: func(_ context.Context, analysis *image.Analysis, w io.Writer) error {
+			pkgs := catalog.Packages(analysis.Layers)
+			log.WithFields("packages", len(pkgs)).Debug("cataloged packages")
+
+			bytes, err := sbom.Document{
+				Image:    analysis.Image,
+				Packages: pkgs,
+				Created:  time.Now(),
+			}.Encode(format)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(bytes)
+			return err
+		},
+	}
+}
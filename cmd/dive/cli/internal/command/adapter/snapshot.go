@@ -0,0 +1,26 @@
+package adapter
+
+import (
+	"context"
+	"io"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/snapshot"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+)
+
+// SnapshotOutput writes the analysis as a compressed binary snapshot (which can be explored with --from-export). The
+// snapshot is encoded straight to the file, since it may be large.
+func SnapshotOutput() Output {
+	return Output{
+		Name: "snapshot",
+		Title: payload.Title{
+			Default:      "Writing snapshot",
+			WhileRunning: "Writing snapshot",
+			OnSuccess:    "Wrote snapshot",
+		},
+		Render: func(_ context.Context, analysis *image.Analysis, w io.Writer) error {
+			return snapshot.Write(w, analysis)
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/treemap"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+)
+
+// TreemapOutput writes a standalone HTML treemap of the image filetree.
+func TreemapOutput() Output {
+	return Output{
+		Name: "treemap",
+		Title: payload.Title{
+			Default:      "Rendering treemap",
+			WhileRunning: "Rendering treemap",
+			OnSuccess:    "Rendered treemap",
+		},
+		Render: func(_ context.Context, analysis *image.Analysis, w io.Writer) error {
+			tree, err := imageTree(analysis.RefTrees)
+			if err != nil {
+				return fmt.Errorf("cannot build image tree: %w", err)
+			}
+
+			bytes, err := treemap.Document{
+				Image: analysis.Image,
+				Tree:  tree,
+			}.HTML()
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(bytes)
+			return err
+		},
+	}
+}
+
+// imageTree stacks every layer into the final image tree, marking each path by how it differs from the base layer
+// (the same tree shown in the TUI when comparing all layers with the last layer selected).
+func imageTree(trees []*filetree.FileTree) (*filetree.FileTree, error) {
+	if len(trees) == 0 {
+		return nil, fmt.Errorf("image has no layers")
+	}
+	last := len(trees) - 1
+	comparer := filetree.NewComparer(trees)
+	return comparer.GetTree(filetree.NewTreeIndexKey(0, 0, min(1, last), last))
+}
@@ -1,18 +1,37 @@
 package command
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/anchore/clio"
 	"github.com/spf13/cobra"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/adapter"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/watch"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/options"
 	"github.com/wagoodman/dive/dive"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+	"github.com/wagoodman/dive/internal/log"
+	"strings"
+)
+
+const (
+	watchFlag  = "--watch"
+	buildxFlag = "--buildx"
 )
 
 type buildOptions struct {
 	options.Application `yaml:",inline" mapstructure:",squash"`
 
-	// reserved for future use of build-only flags
+	// Watch keeps the UI open, rebuilding the image whenever the Dockerfile or build context changes. Flag parsing is
+	// disabled for this command (all arguments are passed to `docker build`), so this is taken from the arguments.
+	Watch bool `yaml:"-" mapstructure:"-"`
+
+	// Buildx builds the image with `docker buildx build` (or `podman build`), noting which layers were reused from the
+	// build cache. Like Watch, this is taken from the arguments.
+	Buildx bool `yaml:"-" mapstructure:"-"`
 }
 
 func Build(app clio.Application) *cobra.Command {
@@ -20,27 +39,142 @@ func Build(app clio.Application) *cobra.Command {
 		Application: options.DefaultApplication(),
 	}
 	return app.SetupCommand(&cobra.Command{
-		Use:                "build [any valid `docker build` arguments]",
-		Short:              "Builds and analyzes a docker image from a Dockerfile (this is a thin wrapper for the `docker build` command).",
+		Use:   "build [--watch] [--buildx] [any valid `docker build` arguments]",
+		Short: "Builds and analyzes a docker image from a Dockerfile (this is a thin wrapper for the `docker build` command).",
+		Long: `Builds and analyzes a docker image from a Dockerfile (this is a thin wrapper for the ` + "`docker build`" + ` command).
+
+With --watch the UI stays open while the Dockerfile and build context are watched for changes. Each change rebuilds
+the image and refreshes the analysis in place, marking the layers that differ from the previous build.
+
+With --buildx the image is built with ` + "`docker buildx build`" + ` (or ` + "`podman build`" + ` with the podman source) and
+exported straight to an OCI archive (unless --load is given). The layer list then shows which layers were reused from
+the build cache and which were rebuilt, highlighting the first rebuilt layer (the step that invalidated the cache).`,
 		DisableFlagParsing: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			args, opts.Watch = removeArg(args, watchFlag)
+			args, opts.Buildx = removeArg(args, buildxFlag)
+
 			if err := setUI(app, opts.Application); err != nil {
 				return fmt.Errorf("failed to set UI: %w", err)
 			}
 
-			resolver, err := dive.GetImageResolver(opts.Analysis.Source)
+			if opts.Watch && (opts.CI.Enabled || opts.Export.Requested()) {
+				return fmt.Errorf("%s cannot be used with CI mode or exports", watchFlag)
+			}
+
+			getResolver := dive.GetImageResolver
+			if opts.Buildx {
+				getResolver = dive.GetBuildxImageResolver
+			}
+			resolver, err := getResolver(opts.Analysis.Source)
 			if err != nil {
 				return fmt.Errorf("cannot determine image provider for build: %w", err)
 			}
 
 			ctx := cmd.Context()
 
+			var paths []string
+			if opts.Watch {
+				// fail before building when there is nothing to watch
+				paths, err = watch.Paths(args)
+				if err != nil {
+					return err
+				}
+			}
+
+			if opts.Export.NeedsPackageFiles() {
+				ctx = image.WithPackageFiles(ctx)
+			}
+
 			img, err := adapter.ImageResolver(resolver).Build(ctx, args)
 			if err != nil {
 				return fmt.Errorf("cannot build image: %w", err)
 			}
 
+			if opts.Watch {
+				return runWatch(ctx, img, resolver, args, paths)
+			}
+
 			return run(cmd.Context(), opts.Application, img, resolver)
 		},
 	}, opts)
 }
+
+// removeArg returns the given arguments without any occurrence of the given argument, and whether it was present.
+func removeArg(args []string, arg string) ([]string, bool) {
+	var result []string
+	var found bool
+	for _, a := range args {
+		if a == arg {
+			found = true
+			continue
+		}
+		result = append(result, a)
+	}
+	return result, found
+}
+
+// runWatch explores the analysis of the given image, rebuilding the image (and refreshing the explored analysis)
+// whenever any of the given paths change.
+func runWatch(ctx context.Context, img *image.Image, resolver image.Resolver, args, paths []string) error {
+	analysis, err := adapter.NewAnalyzer().Analyze(ctx, img)
+	if err != nil {
+		return fmt.Errorf("cannot analyze image: %w", err)
+	}
+
+	watcher, err := watch.New(watch.Debounce, paths...)
+	if err != nil {
+		return err
+	}
+
+	rebuilds := make(chan payload.Rebuild)
+	go func() {
+		defer watcher.Close()
+		defer close(rebuilds)
+
+		send := func(rebuild payload.Rebuild) {
+			select {
+			case rebuilds <- rebuild:
+			case <-ctx.Done():
+			}
+		}
+
+		err := watcher.Run(ctx, func(changed string) {
+			log.WithFields("path", changed).Debug("build context changed, rebuilding")
+			send(payload.Rebuild{Trigger: changed})
+			send(rebuild(ctx, resolver, args, changed))
+		})
+		if err != nil {
+			log.WithFields("error", err).Warn("stopped watching the build context")
+		}
+	}()
+
+	bus.WatchAnalysis(*analysis, resolver, rebuilds)
+
+	return nil
+}
+
+// rebuild builds and analyzes the image again. The build output is captured (so it does not interfere with the UI),
+// where only the last line is reported when the build fails.
+func rebuild(ctx context.Context, resolver image.Resolver, args []string, trigger string) payload.Rebuild {
+	var out bytes.Buffer
+	img, err := resolver.Build(image.WithBuildOutput(ctx, &out), args)
+	if err != nil {
+		if output := lastLine(out.String()); output != "" {
+			err = fmt.Errorf("%w: %s", err, output)
+		}
+		return payload.Rebuild{Trigger: trigger, Err: fmt.Errorf("cannot build image: %w", err)}
+	}
+
+	analysis, err := image.Analyze(ctx, img)
+	if err != nil {
+		return payload.Rebuild{Trigger: trigger, Err: fmt.Errorf("cannot analyze image: %w", err)}
+	}
+
+	return payload.Rebuild{Trigger: trigger, Analysis: analysis}
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
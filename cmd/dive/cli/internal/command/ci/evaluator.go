@@ -13,8 +13,10 @@ import (
 )
 
 type Evaluation struct {
-	Report string
-	Pass   bool
+	Report  string
+	Pass    bool
+	Results map[string]RuleResult
+	Tally   ResultTally
 }
 
 type Evaluator struct {
@@ -150,8 +152,10 @@ func (e Evaluator) Evaluate(ctx context.Context, analysis *image.Analysis) Evalu
 	}
 
 	return Evaluation{
-		Report: e.report(analysis),
-		Pass:   e.Pass,
+		Report:  e.report(analysis),
+		Pass:    e.Pass,
+		Results: e.Results,
+		Tally:   e.Tally,
 	}
 }
 
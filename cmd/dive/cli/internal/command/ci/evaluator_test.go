@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/wagoodman/dive/dive/image"
 	"github.com/wagoodman/dive/dive/image/docker"
 )
 
@@ -175,6 +176,189 @@ func Test_Evaluator_Misconfigurations(t *testing.T) {
 	}
 }
 
+func Test_HighestCompressedSizeRule(t *testing.T) {
+	// 4132 bytes of gzip compressed layers
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-oci-gzip-image.tar"))
+
+	tests := []struct {
+		configValue    string
+		expectedStatus RuleStatus
+	}{
+		{configValue: "disabled", expectedStatus: RuleDisabled},
+		{configValue: "4KB", expectedStatus: RuleFailed},
+		{configValue: "5KB", expectedStatus: RulePassed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.configValue, func(t *testing.T) {
+			rule, err := NewHighestCompressedSizeRule(test.configValue)
+			require.NoError(t, err)
+
+			status, _ := rule.Evaluate(result)
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+
+	// the compressed size of uncompressed layers is unknown
+	rule, err := NewHighestCompressedSizeRule("2MB")
+	require.NoError(t, err)
+	status, _ := rule.Evaluate(docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar")))
+	require.Equal(t, RuleStatus(RuleWarning), status)
+
+	_, err = NewHighestCompressedSizeRule("not_a_size")
+	require.Error(t, err)
+}
+
+func Test_HighestDuplicateBytesRule(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	tests := []struct {
+		configValue    string
+		expectedStatus RuleStatus
+	}{
+		{configValue: "disabled", expectedStatus: RuleDisabled},
+		{configValue: "10kB", expectedStatus: RuleFailed},
+		{configValue: "50kB", expectedStatus: RulePassed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.configValue, func(t *testing.T) {
+			rule, err := NewHighestDuplicateBytesRule(test.configValue)
+			require.NoError(t, err)
+
+			status, _ := rule.Evaluate(result)
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+
+	_, err := NewHighestDuplicateBytesRule("not_a_size")
+	require.Error(t, err)
+}
+
+func Test_HighestLayerWastedBytesRule(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	tests := []struct {
+		configValue    string
+		expectedStatus RuleStatus
+	}{
+		{configValue: "disabled", expectedStatus: RuleDisabled},
+		{configValue: "5kB", expectedStatus: RuleFailed},
+		{configValue: "10kB", expectedStatus: RulePassed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.configValue, func(t *testing.T) {
+			rule, err := NewHighestLayerWastedBytesRule(test.configValue)
+			require.NoError(t, err)
+
+			status, _ := rule.Evaluate(result)
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+
+	rule, err := NewHighestLayerWastedBytesRule("5kB")
+	require.NoError(t, err)
+	_, message := rule.Evaluate(result)
+	// each of the files copied into /root/example (layers 3 to 6) is later removed, as is the file moved by layer 7
+	require.Contains(t, message, "layer 3 wasted-bytes=6405, layer 4 wasted-bytes=6405")
+
+	_, err = NewHighestLayerWastedBytesRule("not_a_size")
+	require.Error(t, err)
+}
+
+func Test_RequireNonRootUserRule(t *testing.T) {
+	tests := []struct {
+		configValue    string
+		user           string
+		expectedStatus RuleStatus
+	}{
+		{configValue: "disabled", user: "", expectedStatus: RuleDisabled},
+		{configValue: "false", user: "", expectedStatus: RuleDisabled},
+		{configValue: "true", user: "", expectedStatus: RuleFailed},
+		{configValue: "true", user: "root", expectedStatus: RuleFailed},
+		{configValue: "true", user: "0:0", expectedStatus: RuleFailed},
+		{configValue: "true", user: "nobody", expectedStatus: RulePassed},
+		{configValue: "true", user: "1000:1000", expectedStatus: RulePassed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.configValue+"/"+test.user, func(t *testing.T) {
+			rule, err := NewRequireNonRootUserRule(test.configValue)
+			require.NoError(t, err)
+
+			status, _ := rule.Evaluate(&image.Analysis{Metadata: image.Metadata{User: test.user}})
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+
+	_, err := NewRequireNonRootUserRule("not_a_bool")
+	require.Error(t, err)
+}
+
+func Test_RequiredLabelsRule(t *testing.T) {
+	labels := map[string]string{
+		"org.opencontainers.image.source":  "https://github.com/wagoodman/dive",
+		"org.opencontainers.image.version": "1.0.0",
+	}
+
+	tests := []struct {
+		configValue    string
+		expectedStatus RuleStatus
+	}{
+		{configValue: "disabled", expectedStatus: RuleDisabled},
+		{configValue: "org.opencontainers.image.source", expectedStatus: RulePassed},
+		{configValue: "org.opencontainers.image.source, org.opencontainers.image.version", expectedStatus: RulePassed},
+		{configValue: "org.opencontainers.image.source,org.opencontainers.image.licenses", expectedStatus: RuleFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.configValue, func(t *testing.T) {
+			rule, err := NewRequiredLabelsRule(test.configValue)
+			require.NoError(t, err)
+
+			status, _ := rule.Evaluate(&image.Analysis{Metadata: image.Metadata{Labels: labels}})
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+
+	_, err := NewRequiredLabelsRule(" , ")
+	require.Error(t, err)
+}
+
+func Test_DisallowedFilesRule(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	tests := []struct {
+		configValue    string
+		expectedStatus RuleStatus
+	}{
+		{configValue: "disabled", expectedStatus: RuleDisabled},
+		{configValue: "**/*.pem", expectedStatus: RulePassed},
+		{configValue: "/root/saved.txt", expectedStatus: RuleFailed},
+		// the example directory is removed by a later layer
+		{configValue: "/root/example/**", expectedStatus: RulePassed},
+		{configValue: "type:file size>1MB", expectedStatus: RuleFailed},
+		{configValue: "layer>=1 type:file size>1MB", expectedStatus: RulePassed},
+		{configValue: "layer>=1 mode:+x type:file", expectedStatus: RuleFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.configValue, func(t *testing.T) {
+			rule, err := NewDisallowedFilesRule(test.configValue)
+			require.NoError(t, err)
+
+			status, _ := rule.Evaluate(result)
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+
+	for _, configValue := range []string{"size>", "diff:added"} {
+		_, err := NewDisallowedFilesRule(configValue)
+		require.Error(t, err, configValue)
+	}
+}
+
 func repoPath(t testing.TB, path string) string {
 	t.Helper()
 	root := repoRoot(t)
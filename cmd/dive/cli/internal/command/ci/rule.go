@@ -27,21 +27,51 @@ type RuleResult struct {
 	message string
 }
 
+// Status is the outcome of evaluating the rule.
+func (r RuleResult) Status() RuleStatus {
+	return r.status
+}
+
+// Message describes the outcome of evaluating the rule (e.g. why it failed).
+func (r RuleResult) Message() string {
+	return r.message
+}
+
+// Label is the unstyled name of the status (e.g. "PASS").
+func (status RuleStatus) Label() string {
+	switch status {
+	case RulePassed:
+		return "PASS"
+	case RuleFailed:
+		return "FAIL"
+	case RuleWarning:
+		return "WARN"
+	case RuleDisabled:
+		return "SKIP"
+	case RuleMisconfigured:
+		return "MISCONFIGURED"
+	case RuleConfigured:
+		return "CONFIGURED"
+	default:
+		return "Unknown"
+	}
+}
+
 func (status RuleStatus) String(f format) string {
 	switch status {
 	case RulePassed:
-		return f.Success.Render("PASS")
+		return f.Success.Render(status.Label())
 	case RuleFailed:
-		return f.Failure.Render("FAIL")
+		return f.Failure.Render(status.Label())
 	case RuleWarning:
-		return f.Warning.Render("WARN")
+		return f.Warning.Render(status.Label())
 	case RuleDisabled:
-		return f.Disabled.Render("SKIP")
+		return f.Disabled.Render(status.Label())
 	case RuleMisconfigured:
-		return f.Warning.Render("MISCONFIGURED")
+		return f.Warning.Render(status.Label())
 	case RuleConfigured:
 		return "CONFIGURED   "
 	default:
-		return f.Warning.Render("Unknown")
+		return f.Warning.Render(status.Label())
 	}
 }
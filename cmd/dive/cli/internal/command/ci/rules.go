@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/dustin/go-humanize"
+	"github.com/wagoodman/dive/dive/filetree"
 	"github.com/wagoodman/dive/dive/image"
 	"strconv"
 	"strings"
@@ -13,6 +14,12 @@ const (
 	ciKeyLowestEfficiencyThreshold = "lowestEfficiency"
 	ciKeyHighestWastedBytes        = "highestWastedBytes"
 	ciKeyHighestUserWastedPercent  = "highestUserWastedPercent"
+	ciKeyHighestCompressedSize     = "highestCompressedSize"
+	ciKeyHighestDuplicateBytes     = "highestDuplicateBytes"
+	ciKeyHighestLayerWastedBytes   = "highestLayerWastedBytes"
+	ciKeyRequireNonRootUser        = "requireNonRootUser"
+	ciKeyRequiredLabels            = "requiredLabels"
+	ciKeyDisallowedFiles           = "disallowedFiles"
 )
 
 func Rules(lowerEfficiency, highestWastedBytes, highestUserWastedPercent string) ([]Rule, error) {
@@ -91,6 +98,42 @@ type HighestUserWastedPercentRule struct {
 	threshold float64
 }
 
+// HighestCompressedSizeRule checks if the total compressed (wire) size of all layers is below threshold
+type HighestCompressedSizeRule struct {
+	BaseRule
+	threshold uint64
+}
+
+// HighestDuplicateBytesRule checks if bytes spent on duplicate content (at different paths) are below threshold
+type HighestDuplicateBytesRule struct {
+	BaseRule
+	threshold uint64
+}
+
+// HighestLayerWastedBytesRule checks if the bytes each layer writes that are whited out or overwritten by a later layer
+// are below threshold
+type HighestLayerWastedBytesRule struct {
+	BaseRule
+	threshold uint64
+}
+
+// RequireNonRootUserRule checks that the image config sets a user other than root
+type RequireNonRootUserRule struct {
+	BaseRule
+}
+
+// RequiredLabelsRule checks that the image config has all the given labels
+type RequiredLabelsRule struct {
+	BaseRule
+	labels []string
+}
+
+// DisallowedFilesRule checks that no path in the final image matches the given filter query
+type DisallowedFilesRule struct {
+	BaseRule
+	query string
+}
+
 func NewLowestEfficiencyRule(configValue string) (Rule, error) {
 	if isRuleDisabled(configValue) {
 		return DisabledRule(ciKeyLowestEfficiencyThreshold), nil
@@ -190,6 +233,241 @@ func (r *HighestUserWastedPercentRule) Evaluate(analysis *image.Analysis) (RuleS
 	return RulePassed, ""
 }
 
+// NewHighestCompressedSizeRule creates a new rule to check the total compressed size of all layers
+func NewHighestCompressedSizeRule(configValue string) (Rule, error) {
+	if isRuleDisabled(configValue) {
+		return DisabledRule(ciKeyHighestCompressedSize), nil
+	}
+
+	threshold, err := humanize.ParseBytes(configValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid highestCompressedSize config value, given %q: %v",
+			configValue, err)
+	}
+
+	return &HighestCompressedSizeRule{
+		BaseRule: BaseRule{
+			key:         ciKeyHighestCompressedSize,
+			configValue: configValue,
+		},
+		threshold: threshold,
+	}, nil
+}
+
+func (r *HighestCompressedSizeRule) Evaluate(analysis *image.Analysis) (RuleStatus, string) {
+	if analysis.CompressedSizeBytes == 0 {
+		return RuleWarning, "the compressed size is unknown (the image archive holds uncompressed layers)"
+	}
+	if analysis.CompressedSizeBytes > r.threshold {
+		return RuleFailed, fmt.Sprintf(
+			"image is too large to pull (compressed-bytes=%d > threshold=%v)",
+			analysis.CompressedSizeBytes, r.threshold)
+	}
+	return RulePassed, ""
+}
+
+// NewHighestDuplicateBytesRule creates a new rule to check bytes spent on duplicate content
+func NewHighestDuplicateBytesRule(configValue string) (Rule, error) {
+	if isRuleDisabled(configValue) {
+		return DisabledRule(ciKeyHighestDuplicateBytes), nil
+	}
+
+	threshold, err := humanize.ParseBytes(configValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid highestDuplicateBytes config value, given %q: %v",
+			configValue, err)
+	}
+
+	return &HighestDuplicateBytesRule{
+		BaseRule: BaseRule{
+			key:         ciKeyHighestDuplicateBytes,
+			configValue: configValue,
+		},
+		threshold: threshold,
+	}, nil
+}
+
+func (r *HighestDuplicateBytesRule) Evaluate(analysis *image.Analysis) (RuleStatus, string) {
+	if analysis.DuplicateBytes > r.threshold {
+		return RuleFailed, fmt.Sprintf(
+			"too many bytes spent on duplicate content (duplicate-bytes=%d > threshold=%v)",
+			analysis.DuplicateBytes, r.threshold)
+	}
+	return RulePassed, ""
+}
+
+// NewHighestLayerWastedBytesRule creates a new rule to check the wasted bytes of each layer
+func NewHighestLayerWastedBytesRule(configValue string) (Rule, error) {
+	if isRuleDisabled(configValue) {
+		return DisabledRule(ciKeyHighestLayerWastedBytes), nil
+	}
+
+	threshold, err := humanize.ParseBytes(configValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid highestLayerWastedBytes config value, given %q: %v",
+			configValue, err)
+	}
+
+	return &HighestLayerWastedBytesRule{
+		BaseRule: BaseRule{
+			key:         ciKeyHighestLayerWastedBytes,
+			configValue: configValue,
+		},
+		threshold: threshold,
+	}, nil
+}
+
+func (r *HighestLayerWastedBytesRule) Evaluate(analysis *image.Analysis) (RuleStatus, string) {
+	var offenders []string
+	for idx, stat := range analysis.LayerStats {
+		if wasted := uint64(stat.OverwrittenBytes); wasted > r.threshold {
+			offenders = append(offenders, fmt.Sprintf("layer %d wasted-bytes=%d", idx, wasted))
+		}
+	}
+	if len(offenders) > 0 {
+		return RuleFailed, fmt.Sprintf(
+			"too many bytes of a layer are whited out or overwritten by later layers (%s > threshold=%v)",
+			strings.Join(offenders, ", "), r.threshold)
+	}
+	return RulePassed, ""
+}
+
+// NewRequireNonRootUserRule creates a new rule to check that containers from the image do not run as root
+func NewRequireNonRootUserRule(configValue string) (Rule, error) {
+	if isRuleDisabled(configValue) {
+		return DisabledRule(ciKeyRequireNonRootUser), nil
+	}
+
+	enabled, err := strconv.ParseBool(strings.TrimSpace(configValue))
+	if err != nil {
+		return nil, fmt.Errorf("invalid requireNonRootUser config value, given %q: %v",
+			configValue, err)
+	}
+	if !enabled {
+		return DisabledRule(ciKeyRequireNonRootUser), nil
+	}
+
+	return &RequireNonRootUserRule{
+		BaseRule: BaseRule{
+			key:         ciKeyRequireNonRootUser,
+			configValue: configValue,
+		},
+	}, nil
+}
+
+func (r *RequireNonRootUserRule) Evaluate(analysis *image.Analysis) (RuleStatus, string) {
+	if analysis.Metadata.RunsAsRoot() {
+		user := analysis.Metadata.User
+		if user == "" {
+			user = "(unset)"
+		}
+		return RuleFailed, fmt.Sprintf("image runs as root (user=%s)", user)
+	}
+	return RulePassed, ""
+}
+
+// NewRequiredLabelsRule creates a new rule to check that the image has all the given (comma-separated) labels
+func NewRequiredLabelsRule(configValue string) (Rule, error) {
+	if isRuleDisabled(configValue) {
+		return DisabledRule(ciKeyRequiredLabels), nil
+	}
+
+	var labels []string
+	for _, label := range strings.Split(configValue, ",") {
+		label = strings.TrimSpace(label)
+		if label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("invalid requiredLabels config value, given %q: no labels specified", configValue)
+	}
+
+	return &RequiredLabelsRule{
+		BaseRule: BaseRule{
+			key:         ciKeyRequiredLabels,
+			configValue: configValue,
+		},
+		labels: labels,
+	}, nil
+}
+
+func (r *RequiredLabelsRule) Evaluate(analysis *image.Analysis) (RuleStatus, string) {
+	var missing []string
+	for _, label := range r.labels {
+		if _, ok := analysis.Metadata.Labels[label]; !ok {
+			missing = append(missing, label)
+		}
+	}
+	if len(missing) > 0 {
+		return RuleFailed, fmt.Sprintf("image is missing required labels (missing=%s)", strings.Join(missing, ","))
+	}
+	return RulePassed, ""
+}
+
+// NewDisallowedFilesRule creates a new rule to check that no path in the final image matches the given filter query
+// (the same query language used by the file tree filter, see filetree.ParseQuery)
+func NewDisallowedFilesRule(configValue string) (Rule, error) {
+	if isRuleDisabled(configValue) {
+		return DisabledRule(ciKeyDisallowedFiles), nil
+	}
+
+	// the layers are not known yet, only validate the query syntax
+	query, err := filetree.ParseQuery(configValue, []*filetree.FileTree{})
+	if err != nil {
+		return nil, fmt.Errorf("invalid disallowedFiles config value, given %q: %v", configValue, err)
+	}
+	if query.Uses("diff") {
+		return nil, fmt.Errorf("invalid disallowedFiles config value, given %q: diff terms are only supported in the UI (use layer terms instead)", configValue)
+	}
+
+	return &DisallowedFilesRule{
+		BaseRule: BaseRule{
+			key:         ciKeyDisallowedFiles,
+			configValue: configValue,
+		},
+		query: configValue,
+	}, nil
+}
+
+func (r *DisallowedFilesRule) Evaluate(analysis *image.Analysis) (RuleStatus, string) {
+	if len(analysis.RefTrees) == 0 {
+		return RulePassed, ""
+	}
+
+	query, err := filetree.ParseQuery(r.query, analysis.RefTrees)
+	if err != nil {
+		return RuleMisconfigured, err.Error()
+	}
+
+	tree, _, err := filetree.StackTreeRange(analysis.RefTrees, 0, len(analysis.RefTrees)-1)
+	if err != nil {
+		return RuleMisconfigured, fmt.Sprintf("unable to build the final image tree: %v", err)
+	}
+
+	var matches []string
+	err = tree.VisitDepthParentFirst(func(node *filetree.FileNode) error {
+		if query.Match(node) {
+			matches = append(matches, node.Path())
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return RuleMisconfigured, fmt.Sprintf("unable to search the final image tree: %v", err)
+	}
+
+	if len(matches) > 0 {
+		const maxShown = 5
+		shown := matches
+		if len(shown) > maxShown {
+			shown = append(shown[:maxShown:maxShown], fmt.Sprintf("... %d more", len(matches)-maxShown))
+		}
+		return RuleFailed, fmt.Sprintf("image contains %d disallowed paths (%s)", len(matches), strings.Join(shown, ", "))
+	}
+	return RulePassed, ""
+}
+
 func isRuleDisabled(value string) bool {
 	value = strings.TrimSpace(strings.ToLower(value))
 	return value == "" || value == "disabled" || value == "off" || value == "false"
@@ -1,69 +1,159 @@
 package export
 
 import (
+	"archive/tar"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
 	"github.com/wagoodman/dive/dive/filetree"
 	diveImage "github.com/wagoodman/dive/dive/image"
 	"github.com/wagoodman/dive/internal/log"
 )
 
+// SchemaVersion is the version of the export format, described by the JSON Schema in schema/json. The major version
+// changes whenever an existing field is removed, renamed or changes meaning; new fields only bump the minor version.
+const SchemaVersion = "2.1.0"
+
+const (
+	// FormatV2 is the versioned export format (see SchemaVersion)
+	FormatV2 = "v2"
+	// FormatLegacy is the original, unversioned export format (see LegacyExport)
+	FormatLegacy = "legacy"
+)
+
+// Formats are the supported export formats (the first is the default).
+var Formats = []string{FormatV2, FormatLegacy}
+
 type Export struct {
-	Layer []Layer `json:"layer"`
-	Image Image   `json:"image"`
+	SchemaVersion string  `json:"schemaVersion"`
+	Image         Image   `json:"image"`
+	Layer         []Layer `json:"layers"`
 }
 
 type Layer struct {
-	Index     int                 `json:"index"`
-	ID        string              `json:"id"`
-	DigestID  string              `json:"digestId"`
-	SizeBytes uint64              `json:"sizeBytes"`
-	Command   string              `json:"command"`
-	FileList  []filetree.FileInfo `json:"fileList"`
+	Index               int             `json:"index"`
+	ID                  string          `json:"id"`
+	DigestID            string          `json:"digestId"`
+	SizeBytes           uint64          `json:"sizeBytes"`
+	CompressedSizeBytes uint64          `json:"compressedSizeBytes"`
+	Compression         string          `json:"compression"`
+	Command             string          `json:"command"`
+	Files               []File          `json:"files"`
+	LargestFiles        []SizeReference `json:"largestFiles"`
+	LargestDirectories  []SizeReference `json:"largestDirectories"`
+	// WastedBytes is the size of the files written by the layer that a later layer whites out or overwrites
+	WastedBytes uint64 `json:"wastedBytes"`
+}
+
+// File is a single entry written by a layer (including whiteout markers, which remove paths from lower layers).
+type File struct {
+	Path string `json:"path"`
+	// Type is one of file, dir, symlink, hardlink, char, block, fifo or other
+	Type string `json:"type"`
+	// LinkTarget is the path a symlink or hardlink refers to
+	LinkTarget string `json:"linkTarget,omitempty"`
+	SizeBytes  int64  `json:"sizeBytes"`
+	// Mode is the file type and permission bits in the style of "ls -l" (e.g. "-rwxr-xr-x")
+	Mode string `json:"mode"`
+	Uid  int    `json:"uid"`
+	Gid  int    `json:"gid"`
+	// Links is the number of paths in the layer sharing this content (hardlinks), 0 if unknown
+	Links int `json:"links"`
+	// Hash is the xxHash64 digest of the contents as hex (empty for directories)
+	Hash string `json:"hash,omitempty"`
+	// DiffType is how the path differs from the stack of all lower layers (added, modified, removed or unmodified)
+	DiffType string `json:"diffType"`
+	// Whiteout is set when the entry removes lower paths: "file" removes the path itself, "opaque" hides everything
+	// beneath the directory
+	Whiteout string `json:"whiteout,omitempty"`
 }
 
 type Image struct {
-	SizeBytes        uint64          `json:"sizeBytes"`
-	InefficientBytes uint64          `json:"inefficientBytes"`
-	EfficiencyScore  float64         `json:"efficiencyScore"`
-	InefficientFiles []FileReference `json:"fileReference"`
+	Name                string                  `json:"name"`
+	SizeBytes           uint64                  `json:"sizeBytes"`
+	CompressedSizeBytes uint64                  `json:"compressedSizeBytes"`
+	InefficientBytes    uint64                  `json:"inefficientBytes"`
+	EfficiencyScore     float64                 `json:"efficiencyScore"`
+	InefficientFiles    []FileReference         `json:"inefficientFiles"`
+	DuplicateBytes      uint64                  `json:"duplicateBytes"`
+	DuplicateFiles      []DuplicateReference    `json:"duplicateFiles"`
+	Config              diveImage.Metadata      `json:"config"`
+	Attestations        []diveImage.Attestation `json:"attestations"`
+	LargestFiles        []SizeReference         `json:"largestFiles"`
+	LargestDirectories  []SizeReference         `json:"largestDirectories"`
 }
 
+// FileReference is a path found in more than one layer (the bytes of all but the final copy are wasted)
 type FileReference struct {
 	References int    `json:"count"`
 	SizeBytes  uint64 `json:"sizeBytes"`
-	Path       string `json:"file"`
+	Path       string `json:"path"`
+}
+
+// DuplicateReference is a set of files with identical content found at different paths
+type DuplicateReference struct {
+	References  int      `json:"count"`
+	SizeBytes   uint64   `json:"sizeBytes"`
+	WastedBytes uint64   `json:"wastedBytes"`
+	Paths       []string `json:"files"`
+}
+
+// SizeReference is a single file (or directory) ranked by its size (largest first)
+type SizeReference struct {
+	SizeBytes uint64 `json:"sizeBytes"`
+	Path      string `json:"path"`
 }
 
 // NewExport exports the analysis to a JSON
 func NewExport(analysis *diveImage.Analysis) *Export {
 	data := Export{
-		Layer: make([]Layer, len(analysis.Layers)),
+		SchemaVersion: SchemaVersion,
+		Layer:         make([]Layer, len(analysis.Layers)),
 		Image: Image{
-			InefficientFiles: make([]FileReference, len(analysis.Inefficiencies)),
-			SizeBytes:        analysis.SizeBytes,
-			EfficiencyScore:  analysis.Efficiency,
-			InefficientBytes: analysis.WastedBytes,
+			Name:                analysis.Image,
+			InefficientFiles:    make([]FileReference, len(analysis.Inefficiencies)),
+			SizeBytes:           analysis.SizeBytes,
+			CompressedSizeBytes: analysis.CompressedSizeBytes,
+			EfficiencyScore:     analysis.Efficiency,
+			InefficientBytes:    analysis.WastedBytes,
+			DuplicateBytes:      analysis.DuplicateBytes,
+			DuplicateFiles:      make([]DuplicateReference, len(analysis.Duplicates)),
+			Config:              analysis.Metadata,
+			Attestations:        make([]diveImage.Attestation, 0, len(analysis.Attestations)),
+			LargestFiles:        newSizeReferences(analysis.Largest.Files),
+			LargestDirectories:  newSizeReferences(analysis.Largest.Directories),
 		},
 	}
 
-	// export layers in order
+	// export layers in order, describing each file relative to all of the layers beneath it
+	stacked := filetree.NewFileTree()
 	for idx, curLayer := range analysis.Layers {
-		layerFileList := make([]filetree.FileInfo, 0)
-		visitor := func(node *filetree.FileNode) error {
-			layerFileList = append(layerFileList, node.Data.FileInfo)
-			return nil
+		data.Layer[idx] = Layer{
+			Index:               curLayer.Index,
+			ID:                  curLayer.Id,
+			DigestID:            curLayer.Digest,
+			SizeBytes:           curLayer.Size,
+			CompressedSizeBytes: curLayer.CompressedSize,
+			Compression:         curLayer.Compression,
+			Command:             curLayer.Command,
+			Files:               layerFiles(stacked, curLayer.Tree),
+			LargestFiles:        make([]SizeReference, 0),
+			LargestDirectories:  make([]SizeReference, 0),
 		}
-		err := curLayer.Tree.VisitDepthChildFirst(visitor, nil)
-		if err != nil {
-			log.WithFields("layer", curLayer.Id, "error", err).Debug("unable to propagate layer tree")
+		if idx < len(analysis.LayerLargest) {
+			data.Layer[idx].LargestFiles = newSizeReferences(analysis.LayerLargest[idx].Files)
+			data.Layer[idx].LargestDirectories = newSizeReferences(analysis.LayerLargest[idx].Directories)
 		}
-		data.Layer[idx] = Layer{
-			Index:     curLayer.Index,
-			ID:        curLayer.Id,
-			DigestID:  curLayer.Digest,
-			SizeBytes: curLayer.Size,
-			Command:   curLayer.Command,
-			FileList:  layerFileList,
+		if idx < len(analysis.LayerStats) {
+			data.Layer[idx].WastedBytes = uint64(analysis.LayerStats[idx].OverwrittenBytes)
+		}
+
+		if _, err := stacked.Stack(curLayer.Tree); err != nil {
+			log.WithFields("layer", curLayer.Id, "error", err).Debug("unable to stack layer tree")
 		}
 	}
 
@@ -78,9 +168,202 @@ func NewExport(analysis *diveImage.Analysis) *Export {
 		}
 	}
 
+	// add duplicate content (most wasteful first)
+	for idx := 0; idx < len(analysis.Duplicates); idx++ {
+		dupData := analysis.Duplicates[len(analysis.Duplicates)-1-idx]
+
+		data.Image.DuplicateFiles[idx] = DuplicateReference{
+			References:  len(dupData.Paths),
+			SizeBytes:   uint64(dupData.Size),
+			WastedBytes: uint64(dupData.WastedSize),
+			Paths:       dupData.Paths,
+		}
+	}
+
+	data.Image.Attestations = append(data.Image.Attestations, analysis.Attestations...)
+
 	return &data
 }
 
+// layerFiles lists every entry the layer wrote (directories only implied by the paths beneath them are left out),
+// comparing each with the stack of lower layers.
+func layerFiles(stacked, tree *filetree.FileTree) []File {
+	files := make([]File, 0)
+	if tree == nil {
+		return files
+	}
+
+	visitor := func(node *filetree.FileNode) error {
+		if node.Opaque {
+			// the opaque whiteout marker (which is not kept as a node) stands for the directory holding it
+			marker := filetree.FileInfo{TypeFlag: tar.TypeReg}
+			files = append(files, File{
+				Path:     node.Path(),
+				Type:     marker.TypeName(),
+				Mode:     marker.ModeString(),
+				Whiteout: "opaque",
+				DiffType: diffName(filetree.Removed),
+			})
+		}
+		if node == tree.Root {
+			return nil
+		}
+		info := node.Data.FileInfo
+		if info.Path == "" {
+			return nil
+		}
+
+		file := File{
+			Path:       node.Path(),
+			Type:       info.TypeName(),
+			LinkTarget: info.Linkname,
+			SizeBytes:  info.Size,
+			Mode:       info.ModeString(),
+			Uid:        info.Uid,
+			Gid:        info.Gid,
+			Links:      info.Links,
+		}
+
+		switch {
+		case node.IsWhiteout():
+			file.Whiteout = "file"
+			file.DiffType = diffName(filetree.Removed)
+		default:
+			diff := filetree.Added
+			if lower, _ := stacked.GetNode(node.Path()); lower != nil {
+				diff = lower.Data.FileInfo.Compare(info)
+			}
+			file.DiffType = diffName(diff)
+			if !info.IsDir {
+				file.Hash = strconv.FormatUint(info.Hash(), 16)
+			}
+		}
+
+		files = append(files, file)
+		return nil
+	}
+
+	if err := tree.VisitDepthParentFirst(visitor, nil); err != nil {
+		log.WithFields("error", err).Debug("unable to list layer files")
+	}
+	return files
+}
+
+func diffName(diff filetree.DiffType) string {
+	return strings.ToLower(diff.String())
+}
+
+func newSizeReferences(paths []filetree.LargestPath) []SizeReference {
+	refs := make([]SizeReference, len(paths))
+	for idx, p := range paths {
+		refs[idx] = SizeReference{SizeBytes: uint64(p.Size), Path: p.Path}
+	}
+	return refs
+}
+
+// Unmarshal reads a previously exported analysis (e.g. to compare against). Exports written in the legacy
+// (unversioned) format are upgraded to the current format.
+func Unmarshal(data []byte) (*Export, error) {
+	return Decode(bytes.NewReader(data))
+}
+
+// Decode reads a previously exported analysis from the given reader, like Unmarshal, without holding the whole
+// document in memory. The reader is read twice: once for the schema version, and once more for the export itself.
+func Decode(r io.ReadSeeker) (*Export, error) {
+	version, err := schemaVersion(json.NewDecoder(r))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if version == "" {
+		var legacy LegacyExport
+		if err := json.NewDecoder(r).Decode(&legacy); err != nil {
+			return nil, err
+		}
+		return legacy.Upgrade(), nil
+	}
+
+	major, _, _ := strings.Cut(version, ".")
+	current, _, _ := strings.Cut(SchemaVersion, ".")
+	if major != current {
+		return nil, fmt.Errorf("unsupported export schema version %q (expected %s.x)", version, current)
+	}
+
+	var exp Export
+	if err := json.NewDecoder(r).Decode(&exp); err != nil {
+		return nil, err
+	}
+	return &exp, nil
+}
+
+// schemaVersion finds the top-level schema version of the export (empty for the legacy format), skipping over every
+// other value token by token.
+func schemaVersion(dec *json.Decoder) (string, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if key == "schemaVersion" {
+			var version string
+			if err := dec.Decode(&version); err != nil {
+				return "", err
+			}
+			return version, nil
+		}
+		if err := skipValue(dec); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// skipValue consumes the next value (including any nested objects and arrays) from the decoder.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func expectDelim(dec *json.Decoder, expected json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %q but found %v", expected, tok)
+	}
+	return nil
+}
+
 func (exp *Export) Marshal() ([]byte, error) {
 	return json.MarshalIndent(&exp, "", "  ")
 }
+
+// Encode writes the export as indented JSON, without holding the whole document in memory.
+func (exp *Export) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&exp)
+}
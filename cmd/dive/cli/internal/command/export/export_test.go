@@ -1,8 +1,15 @@
 package export
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/wagoodman/dive/dive/image/docker"
 )
 
@@ -17,3 +24,207 @@ func Test_Export(t *testing.T) {
 
 	snaps.MatchJSON(t, payload)
 }
+
+func Test_LegacyExport(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	export := NewLegacyExport(result)
+	payload, err := export.Marshal()
+	if err != nil {
+		t.Errorf("Test_LegacyExport: unable to export analysis: %v", err)
+	}
+
+	snaps.MatchJSON(t, payload)
+}
+
+func Test_ExportFiles(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	export := NewExport(result)
+	require.Len(t, export.Layer, 14)
+
+	find := func(layer int, path string) File {
+		t.Helper()
+		for _, f := range export.Layer[layer].Files {
+			if f.Path == path {
+				return f
+			}
+		}
+		t.Fatalf("no file %q in layer %d", path, layer)
+		return File{}
+	}
+
+	added := find(3, "/root/example/somefile1.txt")
+	assert.Equal(t, "added", added.DiffType)
+	assert.Equal(t, "file", added.Type)
+	assert.NotEmpty(t, added.Hash)
+
+	chmod := find(4, "/root/example/somefile1.txt")
+	assert.Equal(t, "modified", chmod.DiffType)
+	assert.Equal(t, "-r--r--r--", chmod.Mode)
+
+	removed := find(9, "/root/example")
+	assert.Equal(t, "removed", removed.DiffType)
+	assert.Equal(t, "file", removed.Whiteout)
+	assert.Empty(t, removed.Hash)
+}
+
+func Test_ExportMatchesSchema(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	payload, err := NewExport(result).Marshal()
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(repoPath(t, fmt.Sprintf("schema/json/export-%s.json", SchemaVersion)))
+	require.NoError(t, err, "every schema version must be published")
+
+	var schema, document map[string]any
+	require.NoError(t, json.Unmarshal(contents, &schema))
+	require.NoError(t, json.Unmarshal(payload, &document))
+
+	for _, problem := range conforms(schema, schema, document, "") {
+		t.Error(problem)
+	}
+}
+
+func Test_Unmarshal(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	t.Run("current", func(t *testing.T) {
+		expected := NewExport(result)
+		payload, err := expected.Marshal()
+		require.NoError(t, err)
+
+		actual, err := Unmarshal(payload)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		payload, err := NewLegacyExport(result).Marshal()
+		require.NoError(t, err)
+
+		actual, err := Unmarshal(payload)
+		require.NoError(t, err)
+
+		expected := NewExport(result)
+		assert.Equal(t, SchemaVersion, actual.SchemaVersion)
+		assert.Equal(t, expected.Image.InefficientFiles, actual.Image.InefficientFiles)
+		assert.Equal(t, expected.Image.Config, actual.Image.Config)
+		require.Len(t, actual.Layer, len(expected.Layer))
+		assert.Equal(t, expected.Layer[9].Command, actual.Layer[9].Command)
+		assert.Contains(t, actual.Layer[9].Files, File{
+			Path:     "/root/example",
+			Type:     "file",
+			Mode:     "----------",
+			DiffType: "removed",
+			Whiteout: "file",
+		})
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := Unmarshal([]byte(`{"schemaVersion": "99.0.0"}`))
+		require.ErrorContains(t, err, "unsupported export schema version")
+	})
+
+	t.Run("version after other fields", func(t *testing.T) {
+		_, err := Unmarshal([]byte(`{"layers": [{"files": [{}]}], "image": {}, "schemaVersion": "99.0.0"}`))
+		require.ErrorContains(t, err, "unsupported export schema version")
+	})
+}
+
+// conforms checks the document against the (subset of JSON Schema) keywords used by the published schema, reporting
+// any value of the wrong type, missing required properties and any property that is not described by the schema.
+func conforms(root, schema map[string]any, value any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		var name string
+		if _, err := fmt.Sscanf(ref, "#/$defs/%s", &name); err != nil {
+			return []string{fmt.Sprintf("%s: unsupported reference %q", at, ref)}
+		}
+		schema = root["$defs"].(map[string]any)[name].(map[string]any)
+	}
+
+	if !hasType(schema["type"], value) {
+		return []string{fmt.Sprintf("%s: unexpected value %v for type %v", at, value, schema["type"])}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == value
+		}
+		if !found {
+			return []string{fmt.Sprintf("%s: %v is not one of %v", at, value, enum)}
+		}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %q", at, name))
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, ok := properties[key].(map[string]any)
+			if !ok {
+				child = additional
+			}
+			if child == nil {
+				problems = append(problems, fmt.Sprintf("%s: property %q is not described by the schema", at, key))
+				continue
+			}
+			problems = append(problems, conforms(root, child, v[key], at+"/"+key)...)
+		}
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for idx, item := range v {
+			problems = append(problems, conforms(root, items, item, fmt.Sprintf("%s/%d", at, idx))...)
+		}
+	}
+	return problems
+}
+
+func hasType(expected any, value any) bool {
+	if types, ok := expected.([]any); ok {
+		for _, e := range types {
+			if hasType(e, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch expected {
+	case nil:
+		return true
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "null":
+		return value == nil
+	}
+	return false
+}
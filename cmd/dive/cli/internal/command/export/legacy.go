@@ -0,0 +1,190 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/wagoodman/dive/dive/filetree"
+	diveImage "github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// LegacyExport is the original, unversioned export format. It is kept for tooling that has not moved to the
+// versioned format yet (see SchemaVersion) and should not be extended.
+type LegacyExport struct {
+	Layer []LegacyLayer `json:"layer"`
+	Image LegacyImage   `json:"image"`
+}
+
+type LegacyLayer struct {
+	Index               int                 `json:"index"`
+	ID                  string              `json:"id"`
+	DigestID            string              `json:"digestId"`
+	SizeBytes           uint64              `json:"sizeBytes"`
+	CompressedSizeBytes uint64              `json:"compressedSizeBytes"`
+	Compression         string              `json:"compression"`
+	Command             string              `json:"command"`
+	FileList            []filetree.FileInfo `json:"fileList"`
+	LargestFiles        []SizeReference     `json:"largestFiles"`
+	LargestDirectories  []SizeReference     `json:"largestDirectories"`
+}
+
+type LegacyImage struct {
+	SizeBytes           uint64                  `json:"sizeBytes"`
+	CompressedSizeBytes uint64                  `json:"compressedSizeBytes"`
+	InefficientBytes    uint64                  `json:"inefficientBytes"`
+	EfficiencyScore     float64                 `json:"efficiencyScore"`
+	InefficientFiles    []LegacyFileReference   `json:"fileReference"`
+	DuplicateBytes      uint64                  `json:"duplicateBytes"`
+	DuplicateFiles      []DuplicateReference    `json:"duplicateFiles"`
+	Metadata            diveImage.Metadata      `json:"metadata"`
+	Attestations        []diveImage.Attestation `json:"attestations"`
+	LargestFiles        []SizeReference         `json:"largestFiles"`
+	LargestDirectories  []SizeReference         `json:"largestDirectories"`
+}
+
+type LegacyFileReference struct {
+	References int    `json:"count"`
+	SizeBytes  uint64 `json:"sizeBytes"`
+	Path       string `json:"file"`
+}
+
+// NewLegacyExport exports the analysis in the legacy (unversioned) format.
+func NewLegacyExport(analysis *diveImage.Analysis) *LegacyExport {
+	exp := NewExport(analysis)
+
+	data := LegacyExport{
+		Layer: make([]LegacyLayer, len(analysis.Layers)),
+		Image: LegacyImage{
+			SizeBytes:           exp.Image.SizeBytes,
+			CompressedSizeBytes: exp.Image.CompressedSizeBytes,
+			InefficientBytes:    exp.Image.InefficientBytes,
+			EfficiencyScore:     exp.Image.EfficiencyScore,
+			InefficientFiles:    make([]LegacyFileReference, len(exp.Image.InefficientFiles)),
+			DuplicateBytes:      exp.Image.DuplicateBytes,
+			DuplicateFiles:      exp.Image.DuplicateFiles,
+			Metadata:            exp.Image.Config,
+			Attestations:        exp.Image.Attestations,
+			LargestFiles:        exp.Image.LargestFiles,
+			LargestDirectories:  exp.Image.LargestDirectories,
+		},
+	}
+
+	for idx, ref := range exp.Image.InefficientFiles {
+		data.Image.InefficientFiles[idx] = LegacyFileReference(ref)
+	}
+
+	// the legacy file list holds every node of the layer tree (children first), including implied directories
+	for idx, curLayer := range analysis.Layers {
+		layerFileList := make([]filetree.FileInfo, 0)
+		visitor := func(node *filetree.FileNode) error {
+			layerFileList = append(layerFileList, node.Data.FileInfo)
+			return nil
+		}
+		err := curLayer.Tree.VisitDepthChildFirst(visitor, nil)
+		if err != nil {
+			log.WithFields("layer", curLayer.Id, "error", err).Debug("unable to propagate layer tree")
+		}
+
+		layer := exp.Layer[idx]
+		data.Layer[idx] = LegacyLayer{
+			Index:               layer.Index,
+			ID:                  layer.ID,
+			DigestID:            layer.DigestID,
+			SizeBytes:           layer.SizeBytes,
+			CompressedSizeBytes: layer.CompressedSizeBytes,
+			Compression:         layer.Compression,
+			Command:             layer.Command,
+			FileList:            layerFileList,
+			LargestFiles:        layer.LargestFiles,
+			LargestDirectories:  layer.LargestDirectories,
+		}
+	}
+
+	return &data
+}
+
+// Upgrade converts the legacy export to the current format. The legacy format does not record content hashes or how
+// each file differs from the lower layers, so these are left empty (other than for whiteouts, which are removals).
+func (legacy *LegacyExport) Upgrade() *Export {
+	exp := Export{
+		SchemaVersion: SchemaVersion,
+		Layer:         make([]Layer, len(legacy.Layer)),
+		Image: Image{
+			SizeBytes:           legacy.Image.SizeBytes,
+			CompressedSizeBytes: legacy.Image.CompressedSizeBytes,
+			InefficientBytes:    legacy.Image.InefficientBytes,
+			EfficiencyScore:     legacy.Image.EfficiencyScore,
+			InefficientFiles:    make([]FileReference, len(legacy.Image.InefficientFiles)),
+			DuplicateBytes:      legacy.Image.DuplicateBytes,
+			DuplicateFiles:      legacy.Image.DuplicateFiles,
+			Config:              legacy.Image.Metadata,
+			Attestations:        legacy.Image.Attestations,
+			LargestFiles:        legacy.Image.LargestFiles,
+			LargestDirectories:  legacy.Image.LargestDirectories,
+		},
+	}
+
+	for idx, ref := range legacy.Image.InefficientFiles {
+		exp.Image.InefficientFiles[idx] = FileReference(ref)
+	}
+
+	for idx, layer := range legacy.Layer {
+		files := make([]File, 0, len(layer.FileList))
+		for _, info := range layer.FileList {
+			if info.Path == "" {
+				continue
+			}
+			file := File{
+				Path:       path.Join("/", info.Path),
+				Type:       info.TypeName(),
+				LinkTarget: info.Linkname,
+				SizeBytes:  info.Size,
+				Mode:       info.ModeString(),
+				Uid:        info.Uid,
+				Gid:        info.Gid,
+				Links:      info.Links,
+			}
+			dir, name := path.Split(file.Path)
+			switch {
+			case name == ".wh..wh..opq":
+				file.Path = path.Clean(dir)
+				file.Whiteout = "opaque"
+				file.DiffType = diffName(filetree.Removed)
+			case strings.HasPrefix(name, ".wh."):
+				file.Path = path.Join(dir, strings.TrimPrefix(name, ".wh."))
+				file.Whiteout = "file"
+				file.DiffType = diffName(filetree.Removed)
+			}
+			files = append(files, file)
+		}
+
+		exp.Layer[idx] = Layer{
+			Index:               layer.Index,
+			ID:                  layer.ID,
+			DigestID:            layer.DigestID,
+			SizeBytes:           layer.SizeBytes,
+			CompressedSizeBytes: layer.CompressedSizeBytes,
+			Compression:         layer.Compression,
+			Command:             layer.Command,
+			Files:               files,
+			LargestFiles:        layer.LargestFiles,
+			LargestDirectories:  layer.LargestDirectories,
+		}
+	}
+
+	return &exp
+}
+
+func (legacy *LegacyExport) Marshal() ([]byte, error) {
+	return json.MarshalIndent(&legacy, "", "  ")
+}
+
+// Encode writes the export as indented JSON, without holding the whole document in memory.
+func (legacy *LegacyExport) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&legacy)
+}
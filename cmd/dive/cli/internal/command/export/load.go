@@ -0,0 +1,184 @@
+package export
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/wagoodman/dive/dive/filetree"
+	diveImage "github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+)
+
+var typeFlags = map[string]byte{
+	"file":     tar.TypeReg,
+	"dir":      tar.TypeDir,
+	"symlink":  tar.TypeSymlink,
+	"hardlink": tar.TypeLink,
+	"char":     tar.TypeChar,
+	"block":    tar.TypeBlock,
+	"fifo":     tar.TypeFifo,
+	// the exact kind of any other entry is not recorded
+	"other": tar.TypeReg,
+}
+
+// typeModes are the file mode type bits set for each kind of entry (as found in a tar header)
+var typeModes = map[string]os.FileMode{
+	"dir":     os.ModeDir,
+	"symlink": os.ModeSymlink,
+	"char":    os.ModeDevice | os.ModeCharDevice,
+	"block":   os.ModeDevice,
+	"fifo":    os.ModeNamedPipe,
+}
+
+// ToImage rebuilds the image described by the export (the layer trees, config and attestations), so it can be
+// analyzed and explored without the original image. Exports do not hold file contents (see NoContent).
+func (exp *Export) ToImage() (*diveImage.Image, error) {
+	img := diveImage.Image{
+		Request:      exp.Image.Name,
+		Metadata:     exp.Image.Config,
+		Attestations: exp.Image.Attestations,
+	}
+
+	hashes := false
+	for idx, l := range exp.Layer {
+		tree := filetree.NewFileTree()
+		tree.Name = l.ID
+
+		for _, f := range l.Files {
+			info, err := f.fileInfo()
+			if err != nil {
+				return nil, fmt.Errorf("layer %d: %w", idx, err)
+			}
+			hashes = hashes || f.Hash != ""
+			tree.FileSize += uint64(info.Size)
+
+			if _, _, err := tree.AddPath(info.Path, info); err != nil {
+				return nil, fmt.Errorf("layer %d: unable to add %q: %w", idx, f.Path, err)
+			}
+		}
+
+		img.Trees = append(img.Trees, tree)
+		img.Layers = append(img.Layers, &diveImage.Layer{
+			Id:             l.ID,
+			Index:          l.Index,
+			Command:        l.Command,
+			Size:           l.SizeBytes,
+			CompressedSize: l.CompressedSizeBytes,
+			Compression:    l.Compression,
+			Tree:           tree,
+			Digest:         l.DigestID,
+		})
+	}
+
+	if !hashes {
+		log.Warn("export has no content hashes (legacy format), modified files are only detected by their attributes")
+	}
+
+	return &img, nil
+}
+
+// fileInfo restores the tar metadata of the entry (whiteouts are restored as their marker files).
+func (f File) fileInfo() (filetree.FileInfo, error) {
+	p := strings.TrimPrefix(path.Clean(f.Path), "/")
+	switch f.Whiteout {
+	case "":
+	case "file":
+		dir, name := path.Split(p)
+		p = path.Join(dir, whiteoutPrefix+name)
+	case "opaque":
+		p = path.Join(p, opaqueWhiteout)
+	default:
+		return filetree.FileInfo{}, fmt.Errorf("invalid whiteout %q for %q", f.Whiteout, f.Path)
+	}
+
+	typeFlag, ok := typeFlags[f.Type]
+	if !ok {
+		return filetree.FileInfo{}, fmt.Errorf("unsupported file type %q for %q", f.Type, f.Path)
+	}
+
+	mode, err := parseMode(f.Mode)
+	if err != nil {
+		return filetree.FileInfo{}, fmt.Errorf("invalid mode for %q: %w", f.Path, err)
+	}
+
+	info := filetree.FileInfo{
+		Path:     p,
+		TypeFlag: typeFlag,
+		Linkname: f.LinkTarget,
+		Size:     f.SizeBytes,
+		Mode:     mode | typeModes[f.Type],
+		Uid:      f.Uid,
+		Gid:      f.Gid,
+		IsDir:    f.Type == "dir",
+		Links:    f.Links,
+	}
+
+	if f.Hash != "" {
+		hash, err := strconv.ParseUint(f.Hash, 16, 64)
+		if err != nil {
+			return filetree.FileInfo{}, fmt.Errorf("invalid hash for %q: %w", f.Path, err)
+		}
+		info.SetHash(hash)
+	}
+
+	return info, nil
+}
+
+// parseMode reads the permission bits from the "ls -l" style mode (the inverse of FileInfo.ModeString).
+func parseMode(mode string) (os.FileMode, error) {
+	if len(mode) != 10 {
+		return 0, fmt.Errorf("expected 10 characters, got %q", mode)
+	}
+
+	var result os.FileMode
+	bit := func(idx int, set byte, value os.FileMode) {
+		if mode[idx] == set {
+			result |= value
+		}
+	}
+	// each triplet is read (r), write (w) and execute, where execute may also carry the setuid, setgid or sticky bit
+	// (lower case when executable, upper case when not)
+	special := []struct {
+		lower, upper byte
+		value        os.FileMode
+	}{
+		{'s', 'S', os.ModeSetuid},
+		{'s', 'S', os.ModeSetgid},
+		{'t', 'T', os.ModeSticky},
+	}
+	for idx, s := range special {
+		offset := 1 + idx*3
+		shift := uint(2-idx) * 3
+		bit(offset, 'r', 04<<shift)
+		bit(offset+1, 'w', 02<<shift)
+		switch mode[offset+2] {
+		case 'x':
+			result |= 01 << shift
+		case s.lower:
+			result |= 01<<shift | s.value
+		case s.upper:
+			result |= s.value
+		case '-':
+		default:
+			return 0, fmt.Errorf("unexpected character %q in %q", mode[offset+2], mode)
+		}
+	}
+	return result, nil
+}
+
+// NoContent is the content reader for an image loaded from an export, which does not hold any file contents.
+type NoContent struct{}
+
+func (NoContent) Extract(_ context.Context, _ string, _ string, p string) error {
+	return fmt.Errorf("cannot extract %q: file contents are not included in an export", p)
+}
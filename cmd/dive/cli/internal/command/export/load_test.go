@@ -0,0 +1,71 @@
+package export
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/dive/image/docker"
+)
+
+func Test_ToImage(t *testing.T) {
+	expected := docker.TestAnalysisFromArchive(t, repoPath(t, ".data/test-docker-image.tar"))
+
+	payload, err := NewExport(expected).Marshal()
+	require.NoError(t, err)
+	exp, err := Unmarshal(payload)
+	require.NoError(t, err)
+
+	img, err := exp.ToImage()
+	require.NoError(t, err)
+
+	actual, err := image.Analyze(context.Background(), img)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.Image, actual.Image)
+	assert.Equal(t, expected.Metadata, actual.Metadata)
+	assert.Equal(t, expected.SizeBytes, actual.SizeBytes)
+	assert.Equal(t, expected.Efficiency, actual.Efficiency)
+	assert.Equal(t, expected.WastedBytes, actual.WastedBytes)
+	assert.Equal(t, expected.DuplicateBytes, actual.DuplicateBytes)
+	assert.Len(t, actual.Inefficiencies, len(expected.Inefficiencies))
+	assert.Equal(t, expected.Largest, actual.Largest)
+
+	require.Len(t, actual.Layers, len(expected.Layers))
+	for idx := range expected.Layers {
+		assert.Equal(t, expected.Layers[idx].Digest, actual.Layers[idx].Digest)
+		assert.Equal(t, expected.Layers[idx].Command, actual.Layers[idx].Command)
+		assert.Equal(t, expected.RefTrees[idx].String(true), actual.RefTrees[idx].String(true), "layer %d", idx)
+	}
+
+	// exporting the loaded image gives back the same document
+	assert.Equal(t, exp, NewExport(actual))
+}
+
+func Test_parseMode(t *testing.T) {
+	cases := map[string]os.FileMode{
+		"-rw-r--r--": 0644,
+		"drwxr-xr-x": 0755,
+		"-rwsr-xr-x": 0755 | os.ModeSetuid,
+		"-rwSr--r--": 0644 | os.ModeSetuid,
+		"-rwxr-sr-x": 0755 | os.ModeSetgid,
+		"drwxrwxrwt": 0777 | os.ModeSticky,
+		"drwxrwxrwT": 0776 | os.ModeSticky,
+		"----------": 0,
+	}
+
+	for mode, expected := range cases {
+		actual, err := parseMode(mode)
+		require.NoError(t, err, mode)
+		assert.Equal(t, expected, actual, mode)
+	}
+
+	_, err := parseMode("-rw")
+	assert.Error(t, err)
+	_, err = parseMode("-rwqr--r--")
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/anchore/clio"
+	"github.com/spf13/cobra"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/adapter"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/layers"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/options"
+	"github.com/wagoodman/dive/dive"
+	"github.com/wagoodman/dive/internal/bus"
+)
+
+var _ clio.FlagAdder = (*layersOptions)(nil)
+
+type layersOptions struct {
+	options.Analysis `yaml:",inline" mapstructure:",squash"`
+
+	Output string `yaml:"-" mapstructure:"-"`
+}
+
+func (o *layersOptions) AddFlags(flags clio.FlagSet) {
+	flags.StringVarP(&o.Output, "output", "o", fmt.Sprintf("the format of the layer listing %v", layers.Formats))
+}
+
+func Layers(app clio.Application) *cobra.Command {
+	opts := &layersOptions{
+		Analysis: options.DefaultAnalysis(),
+		Output:   layers.Formats[0],
+	}
+	return app.SetupCommand(&cobra.Command{
+		Use:   "layers IMAGE",
+		Short: "List the layers of an image with the files each adds, modifies and removes, and the bytes it wastes.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains(layers.Formats, opts.Output) {
+				return fmt.Errorf("invalid output format %q (expected one of %v)", opts.Output, layers.Formats)
+			}
+
+			if err := setUI(app, options.DefaultApplication()); err != nil {
+				return fmt.Errorf("failed to set UI: %w", err)
+			}
+
+			ctx := cmd.Context()
+
+			source, imageStr := dive.DeriveImageSource(args[0])
+			if source == dive.SourceUnknown {
+				source, imageStr = opts.Source, args[0]
+			}
+
+			resolver, err := dive.GetImageResolver(source)
+			if err != nil {
+				return fmt.Errorf("cannot determine image provider to fetch %q from: %w", args[0], err)
+			}
+
+			img, err := adapter.ImageResolver(resolver).Fetch(ctx, imageStr)
+			if err != nil {
+				return fmt.Errorf("cannot load image %q: %w", args[0], err)
+			}
+
+			analysis, err := adapter.NewAnalyzer().Analyze(ctx, img)
+			if err != nil {
+				return fmt.Errorf("cannot analyze image: %w", err)
+			}
+
+			out, err := layers.NewReport(analysis).Render(opts.Output)
+			if err != nil {
+				return err
+			}
+
+			bus.Report(out)
+
+			return nil
+		},
+	}, opts)
+}
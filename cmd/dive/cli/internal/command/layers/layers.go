@@ -0,0 +1,150 @@
+package layers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+const (
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+)
+
+// Formats are the supported output formats (the first is the default).
+var Formats = []string{FormatTable, FormatCSV, FormatJSON}
+
+// Report lists every layer of an image with a summary of the changes it makes.
+type Report struct {
+	Layers []Layer `json:"layers"`
+}
+
+// Layer is a single row of the report (see filetree.LayerStat for how each file count is determined).
+type Layer struct {
+	Index     int    `json:"index"`
+	DiffID    string `json:"diffId"`
+	SizeBytes uint64 `json:"sizeBytes"`
+	Added     int    `json:"added"`
+	Modified  int    `json:"modified"`
+	Removed   int    `json:"removed"`
+	// RemovedBytes is the size of the lower files removed (whited-out) by the layer
+	RemovedBytes int64 `json:"removedBytes"`
+	// OverwrittenBytes is the size of the files written by the layer that a later layer replaces or removes
+	OverwrittenBytes int64  `json:"overwrittenBytes"`
+	Command          string `json:"command"`
+}
+
+// NewReport summarizes each layer of the analyzed image.
+func NewReport(analysis *image.Analysis) *Report {
+	report := &Report{Layers: make([]Layer, 0, len(analysis.Layers))}
+	for idx, layer := range analysis.Layers {
+		row := Layer{
+			Index:     layer.Index,
+			DiffID:    layer.Digest,
+			SizeBytes: layer.Size,
+			Command:   layer.Command,
+		}
+		if idx < len(analysis.LayerStats) {
+			stat := analysis.LayerStats[idx]
+			row.Added = stat.Added
+			row.Modified = stat.Modified
+			row.Removed = stat.Removed
+			row.RemovedBytes = stat.RemovedBytes
+			row.OverwrittenBytes = stat.OverwrittenBytes
+		}
+		report.Layers = append(report.Layers, row)
+	}
+	return report
+}
+
+// Render writes the report in the given format (see Formats).
+func (r *Report) Render(format string) (string, error) {
+	switch format {
+	case FormatTable:
+		return r.String(), nil
+	case FormatCSV:
+		return r.CSV()
+	case FormatJSON:
+		contents, err := r.Marshal()
+		return string(contents), err
+	default:
+		return "", fmt.Errorf("unsupported format %q (expected one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+func (r *Report) Marshal() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// CSV renders the report with a header row, with sizes in bytes and the full diff-id and command.
+func (r *Report) CSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{{"index", "diff_id", "size_bytes", "added", "modified", "removed", "removed_bytes", "overwritten_bytes", "command"}}
+	for _, layer := range r.Layers {
+		records = append(records, []string{
+			strconv.Itoa(layer.Index),
+			layer.DiffID,
+			strconv.FormatUint(layer.SizeBytes, 10),
+			strconv.Itoa(layer.Added),
+			strconv.Itoa(layer.Modified),
+			strconv.Itoa(layer.Removed),
+			strconv.FormatInt(layer.RemovedBytes, 10),
+			strconv.FormatInt(layer.OverwrittenBytes, 10),
+			layer.Command,
+		})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+var headerStyle = lipgloss.NewStyle().Bold(true)
+
+const rowFormat = "%5s  %-19s  %9s  %6s  %8s  %7s  %11s  %11s  %s"
+
+// String renders the report as a human-readable table.
+func (r *Report) String() string {
+	rows := []string{headerStyle.Render(fmt.Sprintf(rowFormat, "Index", "Diff ID", "Size", "Added", "Modified", "Removed", "Whited-Out", "Overwritten", "Command"))}
+	for _, layer := range r.Layers {
+		rows = append(rows, fmt.Sprintf(rowFormat,
+			strconv.Itoa(layer.Index),
+			shortDiffID(layer.DiffID),
+			humanize.Bytes(layer.SizeBytes),
+			strconv.Itoa(layer.Added),
+			strconv.Itoa(layer.Modified),
+			strconv.Itoa(layer.Removed),
+			humanize.Bytes(uint64(layer.RemovedBytes)),
+			humanize.Bytes(uint64(layer.OverwrittenBytes)),
+			commandPreview(layer.Command),
+		))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func shortDiffID(diffID string) string {
+	const length = 19 // "sha256:" + 12 hex characters
+	if len(diffID) > length {
+		return diffID[:length]
+	}
+	return diffID
+}
+
+func commandPreview(command string) string {
+	command = strings.ReplaceAll(command, "\n", "↵")
+	if runes := []rune(command); len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return command
+}
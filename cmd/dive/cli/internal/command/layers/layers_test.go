@@ -0,0 +1,87 @@
+package layers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/image/docker"
+)
+
+func testReport(t *testing.T) *Report {
+	t.Helper()
+	analysis := docker.TestAnalysisFromArchive(t, docker.TestRepoPath(t, ".data/test-docker-image.tar"))
+	return NewReport(analysis)
+}
+
+func TestNewReport(t *testing.T) {
+	report := testReport(t)
+	require.Len(t, report.Layers, 14)
+
+	// mv /root/example/somefile3.txt /root/saved.txt (saved.txt is chmodded by the last layer)
+	assert.Equal(t, Layer{
+		Index:            7,
+		DiffID:           "sha256:dd1effc5eb19894c3e9b57411c98dd1cf30fa1de4253c7fae53c9cea67267d83",
+		SizeBytes:        6405,
+		Added:            1,
+		Removed:          1,
+		RemovedBytes:     6405,
+		OverwrittenBytes: 6405,
+		Command:          "mv /root/example/somefile3.txt /root/saved.txt",
+	}, report.Layers[7])
+
+	// rm -rf /root/example/
+	assert.Equal(t, 2, report.Layers[9].Removed)
+	assert.Equal(t, int64(12810), report.Layers[9].RemovedBytes)
+
+	// chmod +x /root/saved.txt
+	assert.Equal(t, 1, report.Layers[13].Modified)
+	assert.Equal(t, 0, report.Layers[13].Added)
+}
+
+func TestReport_Render(t *testing.T) {
+	report := testReport(t)
+
+	t.Run("table", func(t *testing.T) {
+		actual, err := report.Render(FormatTable)
+		require.NoError(t, err)
+		lines := strings.Split(actual, "\n")
+		require.Len(t, lines, 15)
+		assert.Contains(t, lines[0], "Whited-Out")
+		assert.Contains(t, lines[10], "sha256:bc2e36423fa3")
+		assert.Contains(t, lines[10], "13 kB")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		actual, err := report.Render(FormatCSV)
+		require.NoError(t, err)
+		records, err := csv.NewReader(strings.NewReader(actual)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 15)
+		assert.Equal(t, []string{"9", "sha256:bc2e36423fa31a97223fd421f22c35466220fa160769abf697b8eb58c896b468", "0", "0", "0", "2", "12810", "0", "rm -rf /root/example/"}, records[10])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		actual, err := report.Render(FormatJSON)
+		require.NoError(t, err)
+		var decoded Report
+		require.NoError(t, json.Unmarshal([]byte(actual), &decoded))
+		assert.Equal(t, report, &decoded)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := report.Render("yaml")
+		assert.ErrorContains(t, err, `unsupported format "yaml"`)
+	})
+}
+
+func TestCommandPreview(t *testing.T) {
+	assert.Equal(t, "apk add curl↵rm -rf /var/cache", commandPreview("apk add curl\nrm -rf /var/cache"))
+
+	long := commandPreview(strings.Repeat("é", 70))
+	assert.Equal(t, strings.Repeat("é", 57)+"...", long)
+}
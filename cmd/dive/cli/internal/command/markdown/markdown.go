@@ -0,0 +1,216 @@
+package markdown
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/ci"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/dive/image"
+)
+
+const (
+	// maxCommandLength is the number of characters of a layer command shown before it is truncated
+	maxCommandLength = 60
+	// maxInefficientFiles is the number of inefficient files listed (most wasteful first)
+	maxInefficientFiles = 10
+)
+
+// Summary is a concise markdown report of an image analysis, meant to be posted as a pull request comment.
+type Summary struct {
+	Analysis *image.Analysis
+	// Evaluation holds the CI rule results (optional)
+	Evaluation *ci.Evaluation
+	// Baseline is a previous export of the same image to show differences against (optional)
+	Baseline *export.Export
+}
+
+// Render writes the summary as GitHub/GitLab flavoured markdown.
+func (s Summary) Render() ([]byte, error) {
+	if s.Analysis == nil {
+		return nil, fmt.Errorf("no analysis to summarize")
+	}
+	exp := export.NewExport(s.Analysis)
+
+	var sb strings.Builder
+	s.renderTitle(&sb)
+	s.renderMetrics(&sb, exp)
+	s.renderLayers(&sb, exp)
+	s.renderInefficientFiles(&sb, exp)
+	s.renderRules(&sb)
+
+	return []byte(sb.String()), nil
+}
+
+func (s Summary) renderTitle(sb *strings.Builder) {
+	title := fmt.Sprintf("### dive: `%s`", code(s.Analysis.Image))
+	if s.Evaluation != nil {
+		if s.Evaluation.Pass {
+			title += " :white_check_mark:"
+		} else {
+			title += " :x:"
+		}
+	}
+	sb.WriteString(title + "\n\n")
+}
+
+func (s Summary) renderMetrics(sb *strings.Builder, exp *export.Export) {
+	base := s.Baseline
+	if base == nil {
+		// compare against itself, the changes are not shown anyway
+		base = exp
+	}
+
+	metrics := [][]string{
+		{"Image size", humanize.Bytes(exp.Image.SizeBytes), byteDelta(exp.Image.SizeBytes, base.Image.SizeBytes)},
+		{"Compressed size", image.CompressedBytes(exp.Image.CompressedSizeBytes), compressedDelta(exp.Image.CompressedSizeBytes, base.Image.CompressedSizeBytes)},
+		{"Efficiency", fmt.Sprintf("%.2f %%", exp.Image.EfficiencyScore*100), percentDelta(exp.Image.EfficiencyScore, base.Image.EfficiencyScore)},
+		{"Wasted bytes", humanize.Bytes(exp.Image.InefficientBytes), byteDelta(exp.Image.InefficientBytes, base.Image.InefficientBytes)},
+		{"User wasted", fmt.Sprintf("%.2f %%", s.Analysis.WastedUserPercent*100), ""},
+		{"Duplicate content", humanize.Bytes(exp.Image.DuplicateBytes), byteDelta(exp.Image.DuplicateBytes, base.Image.DuplicateBytes)},
+		{"Layers", fmt.Sprintf("%d", len(exp.Layer)), countDelta(len(exp.Layer), len(base.Layer))},
+	}
+
+	if s.Baseline != nil {
+		sb.WriteString("| Metric | Value | Change |\n|---|--:|--:|\n")
+		for _, m := range metrics {
+			fmt.Fprintf(sb, "| %s | %s | %s |\n", m[0], m[1], m[2])
+		}
+	} else {
+		sb.WriteString("| Metric | Value |\n|---|--:|\n")
+		for _, m := range metrics {
+			fmt.Fprintf(sb, "| %s | %s |\n", m[0], m[1])
+		}
+	}
+	sb.WriteString("\n")
+}
+
+func (s Summary) renderLayers(sb *strings.Builder, exp *export.Export) {
+	baseDigests := make(map[string]bool)
+	if s.Baseline != nil {
+		for _, l := range s.Baseline.Layer {
+			baseDigests[l.DigestID] = true
+		}
+	}
+
+	fmt.Fprintf(sb, "<details><summary>Layers (%d)</summary>\n\n", len(exp.Layer))
+	if s.Baseline != nil {
+		sb.WriteString("| # | Size | Command | |\n|--:|--:|---|---|\n")
+	} else {
+		sb.WriteString("| # | Size | Command |\n|--:|--:|---|\n")
+	}
+	for _, l := range exp.Layer {
+		command := truncate(strings.Join(strings.Fields(l.Command), " "), maxCommandLength)
+		if s.Baseline != nil {
+			changed := ""
+			if !baseDigests[l.DigestID] {
+				changed = "new"
+			}
+			fmt.Fprintf(sb, "| %d | %s | `%s` | %s |\n", l.Index, humanize.Bytes(l.SizeBytes), code(command), changed)
+		} else {
+			fmt.Fprintf(sb, "| %d | %s | `%s` |\n", l.Index, humanize.Bytes(l.SizeBytes), code(command))
+		}
+	}
+	sb.WriteString("\n</details>\n\n")
+}
+
+func (s Summary) renderInefficientFiles(sb *strings.Builder, exp *export.Export) {
+	files := exp.Image.InefficientFiles
+	if len(files) == 0 {
+		sb.WriteString("**Inefficient files:** none\n\n")
+		return
+	}
+
+	title := fmt.Sprintf("**Inefficient files** (%d)", len(files))
+	if len(files) > maxInefficientFiles {
+		title = fmt.Sprintf("**Inefficient files** (top %d of %d)", maxInefficientFiles, len(files))
+		files = files[:maxInefficientFiles]
+	}
+	sb.WriteString(title + "\n\n| Count | Wasted space | Path |\n|--:|--:|---|\n")
+	for _, f := range files {
+		fmt.Fprintf(sb, "| %d | %s | `%s` |\n", f.References, humanize.Bytes(f.SizeBytes), code(f.Path))
+	}
+	sb.WriteString("\n")
+}
+
+func (s Summary) renderRules(sb *strings.Builder) {
+	if s.Evaluation == nil {
+		return
+	}
+
+	status := "PASS"
+	if !s.Evaluation.Pass {
+		status = "FAIL"
+	}
+	tally := s.Evaluation.Tally
+	fmt.Fprintf(sb, "**CI rules:** %s (pass: %d, fail: %d, warn: %d, skip: %d)\n\n", status, tally.Pass, tally.Fail, tally.Warn, tally.Skip)
+
+	// sort rules by name for consistent output
+	rules := make([]string, 0, len(s.Evaluation.Results))
+	for name := range s.Evaluation.Results {
+		rules = append(rules, name)
+	}
+	sort.Strings(rules)
+
+	sb.WriteString("| Result | Rule | Details |\n|---|---|---|\n")
+	for _, rule := range rules {
+		result := s.Evaluation.Results[rule]
+		fmt.Fprintf(sb, "| %s | %s | %s |\n", result.Status().Label(), rule, cell(result.Message()))
+	}
+}
+
+// code makes the value safe to place within a markdown code span in a table cell.
+func code(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "`", "'"), "|", "\\|")
+}
+
+// cell makes the value safe to place within a markdown table cell.
+func cell(value string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(value), " "), "|", "\\|")
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length-1]) + "…"
+}
+
+func byteDelta(current, baseline uint64) string {
+	switch {
+	case current > baseline:
+		return "+" + humanize.Bytes(current-baseline)
+	case current < baseline:
+		return "-" + humanize.Bytes(baseline-current)
+	default:
+		return "—"
+	}
+}
+
+// compressedDelta is like byteDelta for compressed sizes, which cannot be compared when either is unknown (zero).
+func compressedDelta(current, baseline uint64) string {
+	if current == 0 || baseline == 0 {
+		return ""
+	}
+	return byteDelta(current, baseline)
+}
+
+func percentDelta(current, baseline float64) string {
+	delta := (current - baseline) * 100
+	if math.Abs(delta) < 0.005 {
+		return "—"
+	}
+	return fmt.Sprintf("%+.2f %%", delta)
+}
+
+func countDelta(current, baseline int) string {
+	if current == baseline {
+		return "—"
+	}
+	return fmt.Sprintf("%+d", current-baseline)
+}
@@ -0,0 +1,61 @@
+package markdown
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/ci"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/dive/image/docker"
+)
+
+func TestSummary_Render(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, docker.TestRepoPath(t, ".data/test-docker-image.tar"))
+
+	rules, err := ci.Rules("0.9", "1kB", "disabled")
+	require.NoError(t, err)
+	eval := ci.NewEvaluator(rules).Evaluate(context.TODO(), result)
+
+	t.Run("without baseline", func(t *testing.T) {
+		contents, err := Summary{Analysis: result, Evaluation: &eval}.Render()
+		require.NoError(t, err)
+		summary := string(contents)
+
+		assert.Contains(t, summary, " :x:\n")
+		assert.Contains(t, summary, "| Metric | Value |\n|---|--:|\n| Image size | 1.2 MB |\n")
+		assert.Contains(t, summary, "| Efficiency | 98.44 % |\n")
+		assert.Contains(t, summary, "| 13 | 6.4 kB | `chmod +x /root/saved.txt` |\n")
+		assert.Contains(t, summary, "| 0 | 1.2 MB | `#(nop) ADD file:ce026b62356eec3ad1214f92be2c9dc063fe205bd5e…` |\n")
+		assert.Contains(t, summary, "| 2 | 13 kB | `/root/saved.txt` |\n")
+		assert.Contains(t, summary, "**CI rules:** FAIL (pass: 1, fail: 1, warn: 0, skip: 1)")
+		assert.Contains(t, summary, "| FAIL | highestWastedBytes | too many bytes wasted")
+		assert.Contains(t, summary, "| SKIP | highestUserWastedPercent | disabled |\n")
+		assert.NotContains(t, summary, "\x1b", "expected no terminal styling")
+	})
+
+	t.Run("with baseline", func(t *testing.T) {
+		baseline := export.NewExport(result)
+		baseline.Layer = baseline.Layer[:len(baseline.Layer)-1]
+		baseline.Image.SizeBytes -= 6405
+		baseline.Image.EfficiencyScore += 0.01
+
+		contents, err := Summary{Analysis: result, Baseline: baseline}.Render()
+		require.NoError(t, err)
+		summary := string(contents)
+
+		assert.Contains(t, summary, "| Image size | 1.2 MB | +6.4 kB |\n")
+		assert.Contains(t, summary, "| Efficiency | 98.44 % | -1.00 % |\n")
+		assert.Contains(t, summary, "| Wasted bytes | 32 kB | — |\n")
+		assert.Contains(t, summary, "| Layers | 14 | +1 |\n")
+		assert.Contains(t, summary, "| 12 | 6.4 kB | `cp /root/saved.txt /root/.data/saved.again2.txt` |  |\n")
+		assert.Contains(t, summary, "| 13 | 6.4 kB | `chmod +x /root/saved.txt` | new |\n")
+		assert.NotContains(t, summary, "CI rules")
+	})
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, `echo 'a' \| tee b`, code("echo `a` | tee b"))
+}
@@ -0,0 +1,257 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// Document is a single-file, offline HTML report of an image analysis, meant for readers that will not run the TUI.
+type Document struct {
+	Analysis *image.Analysis
+}
+
+type page struct {
+	Image          string
+	Details        []detail
+	Summary        []detail
+	Layers         []layer
+	InefficientLen int
+	Inefficient    []inefficiency
+}
+
+type detail struct {
+	Name  string
+	Value string
+}
+
+type layer struct {
+	Index          int
+	Size           string
+	CompressedSize string
+	Digest         string
+	Command        string
+	Tree           []treeEntry
+}
+
+// treeEntry is a single path changed by a layer, along with the changed paths beneath it.
+type treeEntry struct {
+	Name     string
+	Size     string
+	Diff     string
+	IsDir    bool
+	Children []treeEntry
+}
+
+type inefficiency struct {
+	Count int
+	Size  string
+	Path  string
+}
+
+// HTML renders the report as a self-contained HTML page (no external resources are referenced).
+func (d Document) HTML() ([]byte, error) {
+	if d.Analysis == nil {
+		return nil, fmt.Errorf("no analysis to report")
+	}
+	analysis := d.Analysis
+	exp := export.NewExport(analysis)
+
+	p := page{
+		Image:   analysis.Image,
+		Details: details(exp.Image.Config),
+		Summary: []detail{
+			{Name: "Efficiency", Value: fmt.Sprintf("%.2f %%", exp.Image.EfficiencyScore*100)},
+			{Name: "Total image size", Value: humanize.Bytes(exp.Image.SizeBytes)},
+			{Name: "Compressed image size", Value: image.CompressedBytes(exp.Image.CompressedSizeBytes)},
+			{Name: "Potential wasted space", Value: humanize.Bytes(exp.Image.InefficientBytes)},
+			{Name: "User wasted space", Value: fmt.Sprintf("%.2f %%", analysis.WastedUserPercent*100)},
+			{Name: "Duplicate content", Value: humanize.Bytes(exp.Image.DuplicateBytes)},
+		},
+		InefficientLen: len(exp.Image.InefficientFiles),
+	}
+
+	comparer := filetree.NewComparer(analysis.RefTrees)
+	for idx, l := range exp.Layer {
+		command := l.Command
+		if idx == 0 && command == "" {
+			command = "FROM " + l.ID
+		}
+		p.Layers = append(p.Layers, layer{
+			Index:          l.Index,
+			Size:           humanize.Bytes(l.SizeBytes),
+			CompressedSize: image.CompressedBytes(l.CompressedSizeBytes),
+			Digest:         l.DigestID,
+			Command:        command,
+			Tree:           layerTree(&comparer, idx),
+		})
+	}
+
+	for _, ref := range exp.Image.InefficientFiles {
+		p.Inefficient = append(p.Inefficient, inefficiency{
+			Count: ref.References,
+			Size:  humanize.Bytes(ref.SizeBytes),
+			Path:  ref.Path,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("unable to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func details(m image.Metadata) []detail {
+	var result []detail
+	add := func(name, value string) {
+		if value != "" {
+			result = append(result, detail{Name: name, Value: value})
+		}
+	}
+	add("Platform", m.Platform())
+	add("Created", m.Created)
+	add("Author", m.Author)
+	user := m.User
+	if m.RunsAsRoot() {
+		user = "root"
+	}
+	add("User", user)
+	add("Working directory", m.WorkingDir)
+	add("Entrypoint", strings.Join(m.Entrypoint, " "))
+	add("Command", strings.Join(m.Cmd, " "))
+	add("Exposed ports", strings.Join(m.ExposedPorts, ", "))
+	add("Volumes", strings.Join(m.Volumes, ", "))
+	return result
+}
+
+// layerTree lists what the given layer changed relative to the layers beneath it (for the first layer, everything).
+func layerTree(comparer *filetree.Comparer, idx int) []treeEntry {
+	key := filetree.NewTreeIndexKey(0, 0, 0, 0)
+	if idx > 0 {
+		key = filetree.NewTreeIndexKey(0, idx-1, idx, idx)
+	}
+	tree, err := comparer.GetTree(key)
+	if err != nil {
+		log.WithFields("layer", idx, "error", err).Debug("unable to build layer tree for report")
+		return nil
+	}
+	return treeEntries(tree.Root, idx == 0)
+}
+
+func treeEntries(parent *filetree.FileNode, base bool) []treeEntry {
+	var names []string
+	for name := range parent.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []treeEntry
+	for _, name := range names {
+		node := parent.Children[name]
+		diff := node.Data.DiffType
+		if base {
+			// there is nothing beneath the first layer to compare against, everything is new
+			diff = filetree.Added
+		} else if diff == filetree.Unmodified {
+			continue
+		}
+		entries = append(entries, treeEntry{
+			Name:     name,
+			Size:     humanize.Bytes(uint64(node.GetSize())),
+			Diff:     strings.ToLower(diff.String()),
+			IsDir:    node.Data.FileInfo.IsDir,
+			Children: treeEntries(node, base),
+		})
+	}
+	return entries
+}
+
+var pageTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>dive report: {{.Image}}</title>
+<style>
+  body { font-family: sans-serif; margin: 1.5em; color: #222; }
+  h1 { font-size: 1.4em; }
+  h2 { font-size: 1.15em; margin-top: 1.5em; border-bottom: 1px solid #ddd; }
+  table { border-collapse: collapse; }
+  th, td { text-align: left; padding: 0.2em 0.8em 0.2em 0; vertical-align: top; }
+  td.num, th.num { text-align: right; }
+  code, .tree { font-family: monospace; }
+  .layer > summary { cursor: pointer; padding: 0.2em 0; }
+  .tree { margin: 0.3em 0 0.8em 1.2em; }
+  .tree details, .tree div { margin-left: 1.2em; }
+  .tree summary { cursor: pointer; }
+  .size { color: #777; margin-left: 0.5em; }
+  .added { color: #2e8b3a; }
+  .modified { color: #b38600; }
+  .removed { color: #c9302c; text-decoration: line-through; }
+  .legend span { margin-right: 1em; }
+</style>
+</head>
+<body>
+<h1>{{.Image}}</h1>
+
+<h2>Image details</h2>
+<table>
+{{- range .Details}}
+<tr><th>{{.Name}}</th><td><code>{{.Value}}</code></td></tr>
+{{- end}}
+</table>
+
+<h2>Efficiency</h2>
+<table>
+{{- range .Summary}}
+<tr><th>{{.Name}}</th><td class="num">{{.Value}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Layers</h2>
+<table>
+<tr><th class="num">#</th><th class="num">Size</th><th class="num">Compressed</th><th>Digest</th><th>Command</th></tr>
+{{- range .Layers}}
+<tr><td class="num">{{.Index}}</td><td class="num">{{.Size}}</td><td class="num">{{.CompressedSize}}</td><td><code>{{.Digest}}</code></td><td><code>{{.Command}}</code></td></tr>
+{{- end}}
+</table>
+
+<h2>Layer contents</h2>
+<p class="legend"><span class="added">added</span><span class="modified">modified</span><span class="removed">removed</span>
+<button type="button" onclick="toggleAll(true)">Expand all</button> <button type="button" onclick="toggleAll(false)">Collapse all</button></p>
+{{- range .Layers}}
+<details class="layer"><summary>Layer {{.Index}} <span class="size">{{.Size}}</span> <code>{{.Command}}</code></summary>
+<div class="tree">{{if .Tree}}{{template "entries" .Tree}}{{else}}<em>no changes</em>{{end}}</div>
+</details>
+{{- end}}
+
+<h2>Inefficient files ({{.InefficientLen}})</h2>
+{{- if .Inefficient}}
+<table>
+<tr><th class="num">Count</th><th class="num">Wasted space</th><th>File path</th></tr>
+{{- range .Inefficient}}
+<tr><td class="num">{{.Count}}</td><td class="num">{{.Size}}</td><td><code>{{.Path}}</code></td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p><em>none</em></p>
+{{- end}}
+
+<script>
+function toggleAll(open) {
+  document.querySelectorAll("details").forEach(function (el) { el.open = open; });
+}
+</script>
+</body>
+</html>
+{{define "entries"}}{{range .}}{{if .Children}}<details><summary class="{{.Diff}}">{{.Name}}/<span class="size">{{.Size}}</span></summary>{{template "entries" .Children}}</details>{{else}}<div class="{{.Diff}}">{{.Name}}{{if .IsDir}}/{{end}}<span class="size">{{.Size}}</span></div>{{end}}
+{{end}}{{end}}`))
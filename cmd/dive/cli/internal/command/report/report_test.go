@@ -0,0 +1,41 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/image/docker"
+)
+
+func TestDocument_HTML(t *testing.T) {
+	result := docker.TestAnalysisFromArchive(t, docker.TestRepoPath(t, ".data/test-docker-image.tar"))
+
+	contents, err := Document{Analysis: result}.HTML()
+	require.NoError(t, err)
+	page := string(contents)
+
+	// efficiency summary and image details
+	assert.Contains(t, page, "<tr><th>Efficiency</th><td class=\"num\">98.44 %</td></tr>")
+	assert.Contains(t, page, "<tr><th>Platform</th><td><code>linux/amd64</code></td></tr>")
+
+	// layer list with commands (escaped)
+	assert.Contains(t, page, "<td><code>rm -rf /root/example/</code></td>")
+	assert.Contains(t, page, "<code>chmod &#43;x /root/saved.txt</code>")
+
+	// the filetree of a layer only lists what it changed, coloured by the kind of change
+	assert.Contains(t, page, `<details><summary class="removed">example/<span class="size">13 kB</span></summary>`)
+	assert.Contains(t, page, `<div class="removed">somefile1.txt<span class="size">6.4 kB</span></div>`)
+	assert.Contains(t, page, `<summary class="modified">root/<span class="size">21 kB</span></summary><div class="modified">saved.txt<span class="size">6.4 kB</span></div>`)
+	assert.NotContains(t, page, `class="unmodified"`)
+
+	// inefficient files
+	assert.Contains(t, page, "<h2>Inefficient files (3)</h2>")
+	assert.Contains(t, page, "<td><code>/root/saved.txt</code></td>")
+}
+
+func TestDocument_HTML_NoAnalysis(t *testing.T) {
+	_, err := Document{}.HTML()
+	assert.Error(t, err)
+}
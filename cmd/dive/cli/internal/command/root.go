@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/adapter"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/options"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui"
 	"github.com/wagoodman/dive/dive"
@@ -16,10 +17,17 @@ import (
 	"os"
 )
 
+var _ clio.FlagAdder = (*rootOptions)(nil)
+
 type rootOptions struct {
 	options.Application `yaml:",inline" mapstructure:",squash"`
 
-	// reserved for future use of root-only flags
+	// FromExport is a previous JSON export or snapshot to explore instead of an image
+	FromExport string `yaml:"-" mapstructure:"-"`
+}
+
+func (o *rootOptions) AddFlags(flags clio.FlagSet) {
+	flags.StringVarP(&o.FromExport, "from-export", "", "explore a previous JSON export (see --json) or snapshot (see --snapshot) of an analysis instead of an image; no container engine, archive or network access is needed.")
 }
 
 func Root(app clio.Application) *cobra.Command {
@@ -27,11 +35,17 @@ func Root(app clio.Application) *cobra.Command {
 		Application: options.DefaultApplication(),
 	}
 	return app.SetupRootCommand(&cobra.Command{
-		Use:   "dive [IMAGE]",
+		Use:   "dive [IMAGE | --from-export FILE]",
 		Short: "Docker Image Visualizer & Explorer",
 		Long: `This tool provides a way to discover and explore the contents of a docker image. Additionally the tool estimates
 the amount of wasted space and identifies the offending files from the image.`,
 		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.FromExport != "" {
+				if len(args) != 0 {
+					return fmt.Errorf("no image argument is allowed with --from-export")
+				}
+				return nil
+			}
 			if len(args) != 1 {
 				return fmt.Errorf("exactly one argument is required")
 			}
@@ -43,12 +57,24 @@ the amount of wasted space and identifies the offending files from the image.`,
 				return fmt.Errorf("failed to set UI: %w", err)
 			}
 
+			ctx := cmd.Context()
+
+			if opts.FromExport != "" {
+				img, err := adapter.NewExportLoader(afero.NewOsFs()).Load(ctx, opts.FromExport)
+				if err != nil {
+					return fmt.Errorf("cannot load export: %w", err)
+				}
+				return run(ctx, opts.Application, img, export.NoContent{})
+			}
+
 			resolver, err := dive.GetImageResolver(opts.Analysis.Source)
 			if err != nil {
 				return fmt.Errorf("cannot determine image provider to fetch from: %w", err)
 			}
 
-			ctx := cmd.Context()
+			if opts.Export.NeedsPackageFiles() {
+				ctx = image.WithPackageFiles(ctx)
+			}
 
 			img, err := adapter.ImageResolver(resolver).Fetch(ctx, opts.Analysis.Image)
 			if err != nil {
@@ -77,11 +103,21 @@ func run(ctx context.Context, opts options.Application, img *image.Image, conten
 		return fmt.Errorf("cannot analyze image: %w", err)
 	}
 
-	if opts.Export.JsonPath != "" {
-		if err := adapter.NewExporter(afero.NewOsFs()).ExportTo(ctx, analysis, opts.Export.JsonPath); err != nil {
-			return fmt.Errorf("cannot export analysis: %w", err)
+	if opts.Export.Requested() {
+		exporter := adapter.NewExporter(afero.NewOsFs())
+		for _, target := range exportTargets(opts) {
+			if target.path == "" {
+				continue
+			}
+			if err := exporter.ExportTo(ctx, analysis, target.output, target.path); err != nil {
+				return err
+			}
+		}
+
+		// only the markdown summary reports the CI rules, so a failing gate still fails the run once it is written
+		if !opts.CI.Enabled || opts.Export.MarkdownPath == "" {
+			return nil
 		}
-		return nil
 	}
 
 	if opts.CI.Enabled {
@@ -97,3 +133,25 @@ func run(ctx context.Context, opts options.Application, img *image.Image, conten
 
 	return nil
 }
+
+// exportTarget is an export output along with the path it is written to (empty when the output is not requested).
+type exportTarget struct {
+	path   string
+	output adapter.Output
+}
+
+// exportTargets lists every export output in the order they are written.
+func exportTargets(opts options.Application) []exportTarget {
+	targets := []exportTarget{
+		{path: opts.Export.JsonPath, output: adapter.JSONOutput(opts.Export.JsonFormat)},
+	}
+	for _, target := range opts.Export.SBOMTargets() {
+		targets = append(targets, exportTarget{path: target.Path, output: adapter.SBOMOutput(target.Format)})
+	}
+	return append(targets,
+		exportTarget{path: opts.Export.TreemapPath, output: adapter.TreemapOutput()},
+		exportTarget{path: opts.Export.HTMLPath, output: adapter.HTMLOutput()},
+		exportTarget{path: opts.Export.SnapshotPath, output: adapter.SnapshotOutput()},
+		exportTarget{path: opts.Export.MarkdownPath, output: adapter.MarkdownOutput(afero.NewOsFs(), opts.CI.Rules.List, opts.Export.BaselinePath)},
+	)
+}
@@ -0,0 +1,86 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const cycloneDXSpecVersion = "1.5"
+
+type cdxDocument struct {
+	BOMFormat   string         `json:"bomFormat"`
+	SpecVersion string         `json:"specVersion"`
+	Version     int            `json:"version"`
+	Metadata    cdxMetadata    `json:"metadata"`
+	Components  []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (d Document) cycloneDX() ([]byte, error) {
+	doc := cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: d.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{{Type: "application", Name: "dive"}},
+			},
+			Component: cdxComponent{
+				BOMRef: "image",
+				Type:   "container",
+				Name:   d.Image,
+			},
+		},
+		Components: make([]cdxComponent, 0, len(d.Packages)),
+	}
+
+	for _, pkg := range d.Packages {
+		layer := attributionOf(pkg)
+		properties := []cdxProperty{
+			{Name: "dive:package:type", Value: pkg.Type},
+			{Name: "dive:package:path", Value: pkg.Path},
+		}
+		if layer.index != "" {
+			properties = append(properties,
+				cdxProperty{Name: "dive:layer:index", Value: layer.index},
+				cdxProperty{Name: "dive:layer:digest", Value: layer.digest},
+				cdxProperty{Name: "dive:layer:command", Value: layer.command},
+			)
+		}
+
+		doc.Components = append(doc.Components, cdxComponent{
+			BOMRef:     pkg.PURL + "?path=" + pkg.Path,
+			Type:       "library",
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			PURL:       pkg.PURL,
+			Properties: properties,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,55 @@
+package sbom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wagoodman/dive/dive/catalog"
+)
+
+// supported SBOM formats
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+var Formats = []string{FormatCycloneDX, FormatSPDX}
+
+// Document is a software bill of materials for a single image, where every package is attributed to a layer.
+type Document struct {
+	Image    string
+	Packages []catalog.Package
+	Created  time.Time
+}
+
+// Encode renders the document in the given format (see Formats).
+func (d Document) Encode(format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case FormatCycloneDX:
+		return d.cycloneDX()
+	case FormatSPDX:
+		return d.spdx()
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// attribution describes which layer (and build step) introduced the package.
+type attribution struct {
+	index   string
+	digest  string
+	command string
+}
+
+func attributionOf(pkg catalog.Package) attribution {
+	if pkg.Layer == nil {
+		return attribution{}
+	}
+	return attribution{
+		index:   strconv.Itoa(pkg.Layer.Index),
+		digest:  pkg.Layer.Digest,
+		command: pkg.Layer.Command,
+	}
+}
@@ -0,0 +1,84 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/catalog"
+	"github.com/wagoodman/dive/dive/image"
+)
+
+func testDocument() Document {
+	layer := &image.Layer{Index: 2, Digest: "sha256:abc", Command: "RUN apk add curl"}
+	return Document{
+		Image:   "alpine:latest",
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Packages: []catalog.Package{
+			{
+				Name:    "curl",
+				Version: "8.5.0-r0",
+				Type:    catalog.TypeApk,
+				PURL:    "pkg:apk/alpine/curl@8.5.0-r0",
+				Path:    "/lib/apk/db/installed",
+				Layer:   layer,
+			},
+		},
+	}
+}
+
+func TestDocument_CycloneDX(t *testing.T) {
+	contents, err := testDocument().Encode(FormatCycloneDX)
+	require.NoError(t, err)
+
+	var doc cdxDocument
+	require.NoError(t, json.Unmarshal(contents, &doc))
+
+	assert.Equal(t, "CycloneDX", doc.BOMFormat)
+	assert.Equal(t, "1.5", doc.SpecVersion)
+	assert.Equal(t, "alpine:latest", doc.Metadata.Component.Name)
+	assert.Equal(t, "2024-01-02T03:04:05Z", doc.Metadata.Timestamp)
+	require.Len(t, doc.Components, 1)
+
+	component := doc.Components[0]
+	assert.Equal(t, "curl", component.Name)
+	assert.Equal(t, "8.5.0-r0", component.Version)
+	assert.Equal(t, "pkg:apk/alpine/curl@8.5.0-r0", component.PURL)
+	assert.Contains(t, component.Properties, cdxProperty{Name: "dive:layer:index", Value: "2"})
+	assert.Contains(t, component.Properties, cdxProperty{Name: "dive:layer:digest", Value: "sha256:abc"})
+	assert.Contains(t, component.Properties, cdxProperty{Name: "dive:layer:command", Value: "RUN apk add curl"})
+	assert.Contains(t, component.Properties, cdxProperty{Name: "dive:package:path", Value: "/lib/apk/db/installed"})
+}
+
+func TestDocument_SPDX(t *testing.T) {
+	contents, err := testDocument().Encode(FormatSPDX)
+	require.NoError(t, err)
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(contents, &doc))
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "SPDXRef-DOCUMENT", doc.SPDXID)
+	assert.Equal(t, []string{"Tool: dive"}, doc.CreationInfo.Creators)
+	require.Len(t, doc.Packages, 2)
+
+	pkg := doc.Packages[1]
+	assert.Equal(t, "SPDXRef-Package-1", pkg.SPDXID)
+	assert.Equal(t, "curl", pkg.Name)
+	assert.Equal(t, "8.5.0-r0", pkg.VersionInfo)
+	assert.Equal(t, "found in /lib/apk/db/installed, introduced by layer 2 (sha256:abc): RUN apk add curl", pkg.SourceInfo)
+	assert.Equal(t, "pkg:apk/alpine/curl@8.5.0-r0", pkg.ExternalRefs[0].ReferenceLocator)
+
+	assert.Equal(t, []spdxRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Image"},
+		{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-1"},
+	}, doc.Relationships)
+}
+
+func TestDocument_UnsupportedFormat(t *testing.T) {
+	_, err := testDocument().Encode("syft-json")
+	assert.ErrorContains(t, err, "unsupported SBOM format")
+}
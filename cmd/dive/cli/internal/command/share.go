@@ -0,0 +1,83 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/anchore/clio"
+	"github.com/spf13/cobra"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/adapter"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/share"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/options"
+	"github.com/wagoodman/dive/dive"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+var _ clio.FlagAdder = (*shareOptions)(nil)
+
+type shareOptions struct {
+	options.Analysis `yaml:",inline" mapstructure:",squash"`
+
+	JsonPath string `yaml:"-" mapstructure:"-"`
+}
+
+func (o *shareOptions) AddFlags(flags clio.FlagSet) {
+	flags.StringVarP(&o.JsonPath, "json", "j", "additionally write the layer sharing report as JSON to the given file.")
+}
+
+func Share(app clio.Application) *cobra.Command {
+	opts := &shareOptions{
+		Analysis: options.DefaultAnalysis(),
+	}
+	return app.SetupCommand(&cobra.Command{
+		Use:   "share IMAGE IMAGE...",
+		Short: "Report which layers are shared between a set of images, and how much is saved by sharing them.",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setUI(app, options.DefaultApplication()); err != nil {
+				return fmt.Errorf("failed to set UI: %w", err)
+			}
+
+			ctx := cmd.Context()
+
+			var images []*image.Image
+			for _, arg := range args {
+				source, imageStr := dive.DeriveImageSource(arg)
+				if source == dive.SourceUnknown {
+					source, imageStr = opts.Source, arg
+				}
+
+				resolver, err := dive.GetImageResolver(source)
+				if err != nil {
+					return fmt.Errorf("cannot determine image provider to fetch %q from: %w", arg, err)
+				}
+
+				img, err := adapter.ImageResolver(resolver).Fetch(ctx, imageStr)
+				if err != nil {
+					return fmt.Errorf("cannot load image %q: %w", arg, err)
+				}
+				images = append(images, img)
+			}
+
+			report := share.NewReport(images)
+
+			if opts.JsonPath != "" {
+				log.WithFields("path", opts.JsonPath).Infof("exporting layer sharing report")
+				contents, err := report.Marshal()
+				if err != nil {
+					return fmt.Errorf("cannot marshal layer sharing report: %w", err)
+				}
+				if err := os.WriteFile(opts.JsonPath, contents, 0644); err != nil {
+					return fmt.Errorf("cannot write layer sharing report: %w", err)
+				}
+			}
+
+			bus.Report(report.String())
+
+			return nil
+		},
+	}, opts)
+}
@@ -0,0 +1,328 @@
+package share
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+// Report describes which layers (by chain-id) are shared across a set of images, and what that sharing is worth to a
+// node that pulls all of them.
+type Report struct {
+	Images      []Image     `json:"images"`
+	Layers      []Layer     `json:"sharedLayers"`
+	NearMatches []NearMatch `json:"nearMatches"`
+
+	// TotalBytes is the sum of all layer sizes, as if no layers were shared between images
+	TotalBytes uint64 `json:"totalBytes"`
+	// UniqueBytes is the size of all distinct layers, which is what a node pulling every image stores
+	UniqueBytes uint64 `json:"uniqueBytes"`
+	SavedBytes  uint64 `json:"savedBytes"`
+
+	TotalCompressedBytes  uint64 `json:"totalCompressedBytes"`
+	UniqueCompressedBytes uint64 `json:"uniqueCompressedBytes"`
+	SavedCompressedBytes  uint64 `json:"savedCompressedBytes"`
+}
+
+// Image summarizes a single image within the report.
+type Image struct {
+	Name        string `json:"name"`
+	Layers      int    `json:"layers"`
+	SizeBytes   uint64 `json:"sizeBytes"`
+	SharedBytes uint64 `json:"sharedBytes"` // bytes in layers that at least one other image has as well
+}
+
+// Layer is a layer that is found in more than one image. Engines only reuse a layer when all layers below it are
+// identical as well, so layers are identified by their chain-id (the digest of the diff-ids of the layer and all layers
+// below it).
+type Layer struct {
+	ChainID             string   `json:"chainId"`
+	DiffID              string   `json:"diffId"`
+	SizeBytes           uint64   `json:"sizeBytes"`
+	CompressedSizeBytes uint64   `json:"compressedSizeBytes"`
+	Command             string   `json:"command"`
+	Images              []string `json:"images"`
+}
+
+// NearMatch describes two images that have identical layers except for a single differing layer, after which the
+// layers are identical again. This usually indicates that both were built from the same base image definition at
+// different times (e.g. a package index layer that was rebuilt), so nothing below the differing layer is shared.
+type NearMatch struct {
+	First          string `json:"first"`
+	Second         string `json:"second"`
+	LayerIndex     int    `json:"layerIndex"`
+	FirstDiffID    string `json:"firstDiffId"`
+	SecondDiffID   string `json:"secondDiffId"`
+	MatchingLayers int    `json:"matchingLayers"` // the number of identical layers that follow the differing layer
+	SizeBytes      uint64 `json:"sizeBytes"`      // the bytes that would be saved if the differing layer was shared
+}
+
+type layerEntry struct {
+	layer   *image.Layer
+	chainID string
+	images  []string
+}
+
+// NewReport analyzes the layers of all given images for sharing opportunities.
+func NewReport(images []*image.Image) *Report {
+	report := &Report{
+		Images:      make([]Image, 0, len(images)),
+		Layers:      make([]Layer, 0),
+		NearMatches: make([]NearMatch, 0),
+	}
+
+	entries := make(map[string]*layerEntry)
+	var order []string
+	keys := make([][]string, len(images))
+	for imgIdx, img := range images {
+		chain := chainIDs(img)
+		for idx, layer := range img.Layers {
+			report.TotalBytes += layer.Size
+			report.TotalCompressedBytes += layer.CompressedSize
+
+			key := layerKey(imgIdx, layer, chain[idx])
+			keys[imgIdx] = append(keys[imgIdx], key)
+
+			entry, exists := entries[key]
+			if !exists {
+				entry = &layerEntry{layer: layer, chainID: chain[idx]}
+				entries[key] = entry
+				order = append(order, key)
+				report.UniqueBytes += layer.Size
+				report.UniqueCompressedBytes += layer.CompressedSize
+			}
+			entry.images = append(entry.images, img.Request)
+		}
+	}
+
+	report.SavedBytes = report.TotalBytes - report.UniqueBytes
+	report.SavedCompressedBytes = report.TotalCompressedBytes - report.UniqueCompressedBytes
+
+	for imgIdx, img := range images {
+		summary := Image{
+			Name:   img.Request,
+			Layers: len(img.Layers),
+		}
+		for idx, layer := range img.Layers {
+			summary.SizeBytes += layer.Size
+			if len(entries[keys[imgIdx][idx]].images) > 1 {
+				summary.SharedBytes += layer.Size
+			}
+		}
+		report.Images = append(report.Images, summary)
+	}
+
+	for _, key := range order {
+		entry := entries[key]
+		if len(entry.images) < 2 {
+			continue
+		}
+		report.Layers = append(report.Layers, Layer{
+			ChainID:             entry.chainID,
+			DiffID:              entry.layer.Digest,
+			SizeBytes:           entry.layer.Size,
+			CompressedSizeBytes: entry.layer.CompressedSize,
+			Command:             entry.layer.Command,
+			Images:              entry.images,
+		})
+	}
+
+	// the most valuable layers to share are listed first
+	sort.SliceStable(report.Layers, func(i, j int) bool {
+		return report.Layers[i].SizeBytes*uint64(len(report.Layers[i].Images)) > report.Layers[j].SizeBytes*uint64(len(report.Layers[j].Images))
+	})
+
+	for i := 0; i < len(images); i++ {
+		for j := i + 1; j < len(images); j++ {
+			if match, ok := nearMatch(images[i], images[j]); ok {
+				report.NearMatches = append(report.NearMatches, match)
+			}
+		}
+	}
+
+	return report
+}
+
+// layerKey identifies a layer by its chain-id. Layers without a chain-id cannot be shared, so are kept distinct per
+// image.
+func layerKey(imgIdx int, layer *image.Layer, chainID string) string {
+	if chainID == "" {
+		return fmt.Sprintf("image:%d/layer:%d", imgIdx, layer.Index)
+	}
+	return chainID
+}
+
+// chainIDs returns the chain-id of each layer of the given image, which is the diff-id of the first layer and the
+// digest of "<chain-id of the layer below> <diff-id>" for all later layers. A layer without a diff-id (and every
+// layer above it) has no chain-id.
+func chainIDs(img *image.Image) []string {
+	ids := make([]string, len(img.Layers))
+	var chain string
+	for idx, layer := range img.Layers {
+		if layer.Digest == "" {
+			break
+		}
+		if idx == 0 {
+			chain = layer.Digest
+		} else {
+			chain = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(chain+" "+layer.Digest)))
+		}
+		ids[idx] = chain
+	}
+	return ids
+}
+
+// nearMatch finds if the given images are identical up to a single differing layer, which is followed by at least one
+// identical layer.
+func nearMatch(first, second *image.Image) (NearMatch, bool) {
+	length := len(first.Layers)
+	if len(second.Layers) < length {
+		length = len(second.Layers)
+	}
+
+	same := func(idx int) bool {
+		return first.Layers[idx].Digest != "" && first.Layers[idx].Digest == second.Layers[idx].Digest
+	}
+
+	differing := 0
+	for differing < length && same(differing) {
+		differing++
+	}
+
+	if differing+1 >= length || !same(differing+1) {
+		return NearMatch{}, false
+	}
+
+	// without a diff-id there is no way to tell if the layers actually differ
+	if first.Layers[differing].Digest == "" || second.Layers[differing].Digest == "" {
+		return NearMatch{}, false
+	}
+
+	matching := 0
+	for idx := differing + 1; idx < length && same(idx); idx++ {
+		matching++
+	}
+
+	size := first.Layers[differing].Size
+	if second.Layers[differing].Size < size {
+		size = second.Layers[differing].Size
+	}
+
+	return NearMatch{
+		First:          first.Request,
+		Second:         second.Request,
+		LayerIndex:     differing,
+		FirstDiffID:    first.Layers[differing].Digest,
+		SecondDiffID:   second.Layers[differing].Digest,
+		MatchingLayers: matching,
+		SizeBytes:      size,
+	}, true
+}
+
+func (r *Report) Marshal() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true)
+	headerStyle = lipgloss.NewStyle().Bold(true)
+	auxStyle    = lipgloss.NewStyle().Faint(true)
+)
+
+// String renders the report as a set of human-readable tables.
+func (r *Report) String() string {
+	sections := []string{
+		r.renderSummarySection(),
+		r.renderImagesSection(),
+		r.renderLayersSection(),
+		r.renderNearMatchesSection(),
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func (r *Report) renderSummarySection() string {
+	rows := []string{
+		fmt.Sprintf("  %-18s %s", "totalSize:", humanize.Bytes(r.TotalBytes)),
+		fmt.Sprintf("  %-18s %s", "sizeOnNode:", humanize.Bytes(r.UniqueBytes)),
+		fmt.Sprintf("  %-18s %s", "savedBySharing:", humanize.Bytes(r.SavedBytes)),
+		fmt.Sprintf("  %-18s %s", "pullSize:", humanize.Bytes(r.UniqueCompressedBytes)+" "+auxStyle.Render("(saved "+humanize.Bytes(r.SavedCompressedBytes)+")")),
+	}
+	return titleStyle.Render("Layer Sharing:") + "\n" + strings.Join(rows, "\n")
+}
+
+func (r *Report) renderImagesSection() string {
+	rows := []string{headerStyle.Render(fmt.Sprintf("  %-6s  %-10s  %-10s  %s", "Layers", "Size", "Shared", "Image"))}
+	for _, img := range r.Images {
+		rows = append(rows, fmt.Sprintf("  %-6s  %-10s  %-10s  %s",
+			strconv.Itoa(img.Layers),
+			humanize.Bytes(img.SizeBytes),
+			humanize.Bytes(img.SharedBytes),
+			img.Name,
+		))
+	}
+	return titleStyle.Render("Images:") + "\n" + strings.Join(rows, "\n")
+}
+
+func (r *Report) renderLayersSection() string {
+	title := titleStyle.Render("Shared Layers:")
+	if len(r.Layers) == 0 {
+		return title + " (None)"
+	}
+
+	rows := []string{headerStyle.Render(fmt.Sprintf("  %-6s  %-10s  %-19s  %s", "Images", "Size", "Diff ID", "Command"))}
+	for _, layer := range r.Layers {
+		rows = append(rows, fmt.Sprintf("  %-6s  %-10s  %-19s  %s",
+			strconv.Itoa(len(layer.Images)),
+			humanize.Bytes(layer.SizeBytes),
+			shortDiffID(layer.DiffID),
+			commandPreview(layer.Command),
+		))
+	}
+	return title + "\n" + strings.Join(rows, "\n")
+}
+
+func (r *Report) renderNearMatchesSection() string {
+	title := titleStyle.Render("Nearly Identical Bases:")
+	if len(r.NearMatches) == 0 {
+		return title + " (None)"
+	}
+
+	var rows []string
+	for _, match := range r.NearMatches {
+		rows = append(rows,
+			fmt.Sprintf("  %s and %s", match.First, match.Second),
+			auxStyle.Render(fmt.Sprintf("    layer %d differs (%s vs %s), followed by %d identical layers (up to %s could be shared)",
+				match.LayerIndex,
+				shortDiffID(match.FirstDiffID),
+				shortDiffID(match.SecondDiffID),
+				match.MatchingLayers,
+				humanize.Bytes(match.SizeBytes),
+			)),
+		)
+	}
+	return title + "\n" + strings.Join(rows, "\n")
+}
+
+func shortDiffID(diffID string) string {
+	const length = 19 // "sha256:" + 12 hex characters
+	if len(diffID) > length {
+		return diffID[:length]
+	}
+	return diffID
+}
+
+func commandPreview(command string) string {
+	command = strings.ReplaceAll(command, "\n", "↵")
+	if runes := []rune(command); len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return command
+}
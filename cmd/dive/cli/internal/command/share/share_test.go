@@ -0,0 +1,83 @@
+package share
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+func testImage(name string, diffIDs ...string) *image.Image {
+	img := &image.Image{Request: name}
+	for idx, diffID := range diffIDs {
+		img.Layers = append(img.Layers, &image.Layer{
+			Index:          idx,
+			Digest:         diffID,
+			Size:           uint64(100 * (idx + 1)),
+			CompressedSize: uint64(10 * (idx + 1)),
+		})
+	}
+	return img
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport([]*image.Image{
+		testImage("svc-a", "sha256:base", "sha256:apt-1", "sha256:config", "sha256:app-a"),
+		testImage("svc-b", "sha256:base", "sha256:apt-2", "sha256:config", "sha256:app-b"),
+		testImage("svc-c", "sha256:base", "sha256:apt-1", "sha256:other"),
+	})
+
+	// 1000 + 1000 + 600 bytes, where base (100) is stored 3 times and apt-1 (200) twice. config (300) has the same
+	// diff-id in svc-a and svc-b, but is not shared since the layers below it differ.
+	assert.Equal(t, uint64(2600), report.TotalBytes)
+	assert.Equal(t, uint64(2200), report.UniqueBytes)
+	assert.Equal(t, uint64(400), report.SavedBytes)
+	assert.Equal(t, uint64(40), report.SavedCompressedBytes)
+
+	require.Len(t, report.Layers, 2)
+	assert.Equal(t, "sha256:apt-1", report.Layers[0].DiffID)
+	assert.Equal(t, []string{"svc-a", "svc-c"}, report.Layers[0].Images)
+	assert.Equal(t, "sha256:base", report.Layers[1].DiffID)
+	assert.Equal(t, "sha256:base", report.Layers[1].ChainID)
+	assert.Equal(t, []string{"svc-a", "svc-b", "svc-c"}, report.Layers[1].Images)
+
+	require.Len(t, report.Images, 3)
+	assert.Equal(t, uint64(300), report.Images[0].SharedBytes)
+	assert.Equal(t, uint64(100), report.Images[1].SharedBytes)
+	assert.Equal(t, uint64(300), report.Images[2].SharedBytes)
+
+	require.Len(t, report.NearMatches, 1)
+	assert.Equal(t, NearMatch{
+		First:          "svc-a",
+		Second:         "svc-b",
+		LayerIndex:     1,
+		FirstDiffID:    "sha256:apt-1",
+		SecondDiffID:   "sha256:apt-2",
+		MatchingLayers: 1,
+		SizeBytes:      200,
+	}, report.NearMatches[0])
+}
+
+func TestNewReport_LayersWithoutDiffID(t *testing.T) {
+	report := NewReport([]*image.Image{
+		testImage("svc-a", "sha256:base", "", "sha256:app"),
+		testImage("svc-b", "sha256:base", "", "sha256:app"),
+	})
+
+	// layers without a diff-id (and the layers above them) can never be considered shared
+	require.Len(t, report.Layers, 1)
+	assert.Equal(t, "sha256:base", report.Layers[0].DiffID)
+	assert.Equal(t, uint64(100), report.SavedBytes)
+	assert.Empty(t, report.NearMatches)
+}
+
+func TestChainIDs(t *testing.T) {
+	ids := chainIDs(testImage("svc-a", "sha256:base", "sha256:app", "", "sha256:config"))
+	require.Len(t, ids, 4)
+	assert.Equal(t, "sha256:base", ids[0])
+	// sha256("sha256:base sha256:app")
+	assert.Equal(t, "sha256:d9edbeeadcc51068e584d9c296fafc4ec9ade3cccee16d3f88fb9867f773f567", ids[1])
+	assert.Equal(t, []string{"", ""}, ids[2:])
+}
@@ -0,0 +1,280 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+)
+
+// Version is the version of the snapshot encoding, which is bumped whenever a change cannot be read by older releases.
+const Version = 1
+
+// opaqueWhiteout is the name of the tar entry marking its directory as opaque (see filetree.FileNode.Opaque).
+const opaqueWhiteout = ".wh..wh..opq"
+
+// magic starts every snapshot (followed by the version), so snapshots can be told apart from other files.
+var magic = []byte("DIVESNAP")
+
+// snapshot is the full analysis input: everything needed to rebuild the image, so the analysis can be repeated
+// exactly. Derived results (efficiency, inefficient files, ...) are recomputed when read.
+type snapshot struct {
+	Image        string
+	Digest       string
+	Metadata     image.Metadata
+	Attestations []image.Attestation
+	Layers       []layer
+}
+
+type layer struct {
+	ID             string
+	Index          int
+	Command        string
+	Size           uint64
+	CompressedSize uint64
+	Compression    string
+	Digest         string
+	Names          []string
+	Created        time.Time
+	Author         string
+	PackageFiles   map[string][]byte
+	EmptyLayers    []image.EmptyLayer
+	// PrecedingEmptyLayers is absent from snapshots written by older releases (which gob decodes as empty)
+	PrecedingEmptyLayers []image.EmptyLayer
+	TreeName             string
+	// Entries are the nodes of the layer tree, parents before children
+	Entries []entry
+}
+
+// entry is a single node of a layer tree. Nodes refer to their parent by index (instead of holding the full path),
+// which keeps the encoding small for deep trees.
+type entry struct {
+	// Parent is the index of the parent entry plus one (zero is the root of the tree)
+	Parent int
+	Name   string
+	// Implied is set for directories that only exist because of the paths beneath them (there is no tar entry)
+	Implied  bool
+	TypeFlag byte
+	Linkname string
+	Size     int64
+	Mode     os.FileMode
+	Uid      int
+	Gid      int
+	IsDir    bool
+	Links    int
+	Hash     uint64
+}
+
+// Is indicates if the given reader starts like a snapshot (the header is peeked, so nothing is consumed).
+func Is(r *bufio.Reader) bool {
+	header, err := r.Peek(len(magic) + 1)
+	return err == nil && bytes.HasPrefix(header, magic)
+}
+
+// Write encodes the analysed image (layer trees with content hashes, config, attestations and layer details) as a
+// compressed snapshot, which is typically a fraction of the size of a JSON export.
+func Write(w io.Writer, analysis *image.Analysis) error {
+	s := snapshot{
+		Image:        analysis.Image,
+		Digest:       analysis.Digest,
+		Metadata:     analysis.Metadata,
+		Attestations: analysis.Attestations,
+		Layers:       make([]layer, 0, len(analysis.Layers)),
+	}
+
+	for _, l := range analysis.Layers {
+		s.Layers = append(s.Layers, layer{
+			ID:                   l.Id,
+			Index:                l.Index,
+			Command:              l.Command,
+			Size:                 l.Size,
+			CompressedSize:       l.CompressedSize,
+			Compression:          l.Compression,
+			Digest:               l.Digest,
+			Names:                l.Names,
+			Created:              l.Created,
+			Author:               l.Author,
+			PackageFiles:         l.PackageFiles,
+			EmptyLayers:          l.EmptyLayers,
+			PrecedingEmptyLayers: l.PrecedingEmptyLayers,
+			TreeName:             l.Tree.Name,
+			Entries:              treeEntries(l.Tree),
+		})
+	}
+
+	header := append(append([]byte{}, magic...), Version)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(zw).Encode(&s); err != nil {
+		zw.Close()
+		return fmt.Errorf("unable to encode snapshot: %w", err)
+	}
+	return zw.Close()
+}
+
+// Read decodes a snapshot back into the image it was taken from, ready to be analyzed.
+func Read(r io.Reader) (*image.Image, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.HasPrefix(header, magic) {
+		return nil, fmt.Errorf("not a dive snapshot")
+	}
+	if version := header[len(magic)]; version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d (expected %d)", version, Version)
+	}
+
+	zr, err := zstd.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var s snapshot
+	if err := gob.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, fmt.Errorf("unable to decode snapshot: %w", err)
+	}
+
+	// empty lists are not encoded, restore them as empty (not missing) so exports of the image are unchanged
+	m := &s.Metadata
+	for _, list := range []*[]string{&m.Env, &m.Entrypoint, &m.Cmd, &m.ExposedPorts, &m.Volumes} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if m.Labels == nil {
+		m.Labels = map[string]string{}
+	}
+
+	img := image.Image{
+		Request:      s.Image,
+		Digest:       s.Digest,
+		Metadata:     s.Metadata,
+		Attestations: s.Attestations,
+	}
+	for _, l := range s.Layers {
+		tree, err := layerTree(l)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode layer %d: %w", l.Index, err)
+		}
+		img.Trees = append(img.Trees, tree)
+		img.Layers = append(img.Layers, &image.Layer{
+			Id:                   l.ID,
+			Index:                l.Index,
+			Command:              l.Command,
+			Size:                 l.Size,
+			CompressedSize:       l.CompressedSize,
+			Compression:          l.Compression,
+			Tree:                 tree,
+			Names:                l.Names,
+			Digest:               l.Digest,
+			Created:              l.Created,
+			Author:               l.Author,
+			PackageFiles:         l.PackageFiles,
+			EmptyLayers:          l.EmptyLayers,
+			PrecedingEmptyLayers: l.PrecedingEmptyLayers,
+		})
+	}
+
+	return &img, nil
+}
+
+func treeEntries(tree *filetree.FileTree) []entry {
+	entries := make([]entry, 0, tree.Size)
+
+	// walk the children directly (instead of following the parent of each node), since stacking trees during the
+	// analysis re-parents nodes onto the stacked tree
+	var walk func(node *filetree.FileNode, parent int)
+	walk = func(node *filetree.FileNode, parent int) {
+		if node.Opaque {
+			// the marker is restored as it was found in the layer tar, which marks the directory again when read
+			entries = append(entries, entry{
+				Parent:   parent,
+				Name:     opaqueWhiteout,
+				TypeFlag: tar.TypeReg,
+			})
+		}
+
+		names := make([]string, 0, len(node.Children))
+		for name := range node.Children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			child := node.Children[name]
+			info := child.Data.FileInfo
+			entries = append(entries, entry{
+				Parent:   parent,
+				Name:     name,
+				Implied:  info.Path == "",
+				TypeFlag: info.TypeFlag,
+				Linkname: info.Linkname,
+				Size:     info.Size,
+				Mode:     info.Mode,
+				Uid:      info.Uid,
+				Gid:      info.Gid,
+				IsDir:    info.IsDir,
+				Links:    info.Links,
+				Hash:     info.Hash(),
+			})
+			walk(child, len(entries))
+		}
+	}
+	walk(tree.Root, 0)
+
+	return entries
+}
+
+func layerTree(l layer) (*filetree.FileTree, error) {
+	tree := filetree.NewFileTree()
+	tree.Name = l.TreeName
+
+	// the (relative, as found in the layer tar) path of each entry, by entry index plus one
+	paths := make([]string, len(l.Entries)+1)
+	for idx, e := range l.Entries {
+		if e.Parent < 0 || e.Parent > idx {
+			return nil, fmt.Errorf("entry %q refers to an unknown parent", e.Name)
+		}
+		p := path.Join(paths[e.Parent], e.Name)
+		paths[idx+1] = p
+
+		var info filetree.FileInfo
+		if !e.Implied {
+			info = filetree.FileInfo{
+				Path:     p,
+				TypeFlag: e.TypeFlag,
+				Linkname: e.Linkname,
+				Size:     e.Size,
+				Mode:     e.Mode,
+				Uid:      e.Uid,
+				Gid:      e.Gid,
+				IsDir:    e.IsDir,
+				Links:    e.Links,
+			}
+			info.SetHash(e.Hash)
+			tree.FileSize += uint64(e.Size)
+		}
+
+		if _, _, err := tree.AddPath("/"+p, info); err != nil {
+			return nil, fmt.Errorf("unable to add %q: %w", p, err)
+		}
+	}
+	return tree, nil
+}
@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/dive/image/docker"
+)
+
+func TestWriteRead(t *testing.T) {
+	expected := docker.TestAnalysisFromArchive(t, docker.TestRepoPath(t, ".data/test-docker-image.tar"))
+	require.NotEmpty(t, expected.Digest)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, expected))
+	assert.True(t, Is(bufio.NewReader(bytes.NewReader(buf.Bytes()))))
+
+	jsonExport, err := export.NewExport(expected).Marshal()
+	require.NoError(t, err)
+	assert.Less(t, buf.Len()*5, len(jsonExport), "snapshot should be much smaller than the JSON export")
+
+	img, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Digest, img.Digest)
+
+	actual, err := image.Analyze(context.Background(), img)
+	require.NoError(t, err)
+
+	assert.Equal(t, export.NewExport(expected), export.NewExport(actual))
+	require.Len(t, actual.Layers, len(expected.Layers))
+	for idx, l := range expected.Layers {
+		assert.Equal(t, l.Names, actual.Layers[idx].Names)
+		assert.Equal(t, l.EmptyLayers, actual.Layers[idx].EmptyLayers)
+		assert.Equal(t, l.PrecedingEmptyLayers, actual.Layers[idx].PrecedingEmptyLayers)
+		assert.Equal(t, l.Tree.Name, actual.Layers[idx].Tree.Name)
+		assert.Equal(t, l.Tree.FileSize, actual.Layers[idx].Tree.FileSize)
+		assert.Equal(t, l.Tree.String(true), actual.Layers[idx].Tree.String(true), "layer %d", idx)
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"schemaVersion": "2.0.0"}`))
+	require.ErrorContains(t, err, "not a dive snapshot")
+
+	_, err = Read(bytes.NewReader(append([]byte("DIVESNAP"), Version+1)))
+	require.ErrorContains(t, err, "unsupported snapshot version")
+}
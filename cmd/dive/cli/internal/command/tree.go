@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/anchore/clio"
+	"github.com/spf13/cobra"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/adapter"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/tree"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/options"
+	"github.com/wagoodman/dive/dive"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/internal/bus"
+)
+
+var _ clio.FlagAdder = (*treeOptions)(nil)
+
+type treeOptions struct {
+	options.Analysis `yaml:",inline" mapstructure:",squash"`
+
+	Layer        int    `yaml:"-" mapstructure:"-"`
+	From         int    `yaml:"-" mapstructure:"-"`
+	Aggregated   bool   `yaml:"-" mapstructure:"-"`
+	NoAttributes bool   `yaml:"-" mapstructure:"-"`
+	MaxDepth     int    `yaml:"-" mapstructure:"-"`
+	Only         string `yaml:"-" mapstructure:"-"`
+	Filter       string `yaml:"-" mapstructure:"-"`
+}
+
+func (o *treeOptions) AddFlags(flags clio.FlagSet) {
+	flags.IntVarP(&o.Layer, "layer", "l", "the layer to show (0 is the base layer, -1 the last layer)")
+	flags.IntVarP(&o.From, "from", "", "the first layer of the compared range (only used with --aggregated)")
+	flags.BoolVarP(&o.Aggregated, "aggregated", "a", "show all changes from the start of the range to the layer (instead of only the changes made by the layer)")
+	flags.BoolVarP(&o.NoAttributes, "no-attributes", "", "hide the permission, owner and size columns")
+	flags.IntVarP(&o.MaxDepth, "max-depth", "d", "collapse directories below the given depth (0 shows the full tree)")
+	flags.StringVarP(&o.Only, "only", "", "only show the given kinds of change (comma separated: added, removed, modified, unmodified)")
+	flags.StringVarP(&o.Filter, "filter", "f", "only show paths matching the filter expression (same syntax as the TUI filter)")
+}
+
+func Tree(app clio.Application) *cobra.Command {
+	opts := &treeOptions{
+		Analysis: options.DefaultAnalysis(),
+		Layer:    -1,
+	}
+	return app.SetupCommand(&cobra.Command{
+		Use:   "tree IMAGE",
+		Short: "Print the filetree of a layer (or layer range) with the same diff colouring as the TUI.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setUI(app, options.DefaultApplication()); err != nil {
+				return fmt.Errorf("failed to set UI: %w", err)
+			}
+
+			only, err := tree.ParseDiffTypes(opts.Only)
+			if err != nil {
+				return err
+			}
+			if opts.MaxDepth < 0 {
+				return fmt.Errorf("invalid max depth %d", opts.MaxDepth)
+			}
+			if opts.From != 0 && !opts.Aggregated {
+				return fmt.Errorf("--from can only be used with --aggregated")
+			}
+
+			source, imageStr := dive.DeriveImageSource(args[0])
+			if source == dive.SourceUnknown {
+				source, imageStr = opts.Source, args[0]
+			}
+
+			resolver, err := dive.GetImageResolver(source)
+			if err != nil {
+				return fmt.Errorf("cannot determine image provider to fetch %q from: %w", args[0], err)
+			}
+
+			img, err := adapter.ImageResolver(resolver).Fetch(cmd.Context(), imageStr)
+			if err != nil {
+				return fmt.Errorf("cannot load image %q: %w", args[0], err)
+			}
+
+			var filter *filetree.Query
+			if opts.Filter != "" {
+				filter, err = filetree.ParseQuery(opts.Filter, img.Trees)
+				if err != nil {
+					return fmt.Errorf("invalid filter: %w", err)
+				}
+			}
+
+			doc := tree.Document{Layers: img.Layers, Trees: img.Trees}
+			out, err := doc.Render(tree.Options{
+				Layer:      opts.Layer,
+				From:       opts.From,
+				Aggregated: opts.Aggregated,
+				Attributes: !opts.NoAttributes,
+				MaxDepth:   opts.MaxDepth,
+				Only:       only,
+				Filter:     filter,
+			})
+			if err != nil {
+				return err
+			}
+
+			bus.Report(out)
+
+			return nil
+		},
+	}, opts)
+}
@@ -0,0 +1,159 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/viewmodel"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+)
+
+// Options selects which tree is rendered (the same way layers are selected in the TUI) and which paths are shown.
+type Options struct {
+	// Layer is the (top) layer to show, a negative value selects the last layer
+	Layer int
+	// From is the first layer of the range to compare (the layers below it are not shown)
+	From int
+	// Aggregated shows all changes from the start of the range instead of only the changes made by the selected layer
+	Aggregated bool
+	// Attributes shows the permission, owner and size columns
+	Attributes bool
+	// MaxDepth collapses directories deeper than the given depth (zero shows the full tree)
+	MaxDepth int
+	// Only lists the diff types to show (empty shows all)
+	Only []filetree.DiffType
+	// Filter hides the paths that do not match the query (optional)
+	Filter *filetree.Query
+}
+
+// Document is the rendered filetree of an image for a layer (or layer range).
+type Document struct {
+	Layers []*image.Layer
+	Trees  []*filetree.FileTree
+}
+
+// ParseDiffTypes reads a comma separated list of diff types (e.g. "added,modified").
+func ParseDiffTypes(value string) ([]filetree.DiffType, error) {
+	var result []filetree.DiffType
+	for _, name := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+			continue
+		case "added":
+			result = append(result, filetree.Added)
+		case "removed":
+			result = append(result, filetree.Removed)
+		case "modified":
+			result = append(result, filetree.Modified)
+		case "unmodified":
+			result = append(result, filetree.Unmodified)
+		default:
+			return nil, fmt.Errorf("invalid diff type %q (expected added, removed, modified or unmodified)", name)
+		}
+	}
+	return result, nil
+}
+
+// Render draws the tree with the same diff colouring and attribute columns as the filetree pane of the TUI.
+func (d Document) Render(opts Options) (string, error) {
+	if len(d.Trees) == 0 {
+		return "", fmt.Errorf("image has no layers")
+	}
+	last := len(d.Trees) - 1
+
+	layer := opts.Layer
+	if layer < 0 {
+		layer = last
+	}
+	if layer > last {
+		return "", fmt.Errorf("invalid layer %d (the image has layers 0 to %d)", layer, last)
+	}
+	if opts.From < 0 || opts.From > layer {
+		return "", fmt.Errorf("invalid range start %d (expected 0 to %d)", opts.From, layer)
+	}
+
+	mode := viewmodel.CompareSingleLayer
+	if opts.Aggregated {
+		mode = viewmodel.CompareAllLayers
+	}
+	state := viewmodel.NewLayerSetState(d.Layers, mode)
+	state.LayerIndex = layer
+	state.CompareStartIndex = opts.From
+
+	comparer := filetree.NewComparer(d.Trees)
+	tree, err := comparer.GetTree(filetree.NewTreeIndexKey(state.GetCompareIndexes()))
+	if err != nil {
+		return "", fmt.Errorf("unable to build tree: %w", err)
+	}
+
+	view, err := visibleTree(tree, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if opts.Attributes {
+		sb.WriteString(fmt.Sprintf(filetree.AttributeFormat+" %s\n", "P", "ermission", "Links", "UID:GID", "Size", "Filetree"))
+	}
+	sb.WriteString(view.String(opts.Attributes))
+	return sb.String(), nil
+}
+
+// visibleTree copies the tree, leaving out the paths hidden by the diff types and filter (keeping the directories
+// leading to any shown path) and collapsing the directories beyond the max depth.
+func visibleTree(tree *filetree.FileTree, opts Options) (*filetree.FileTree, error) {
+	hidden := make(map[filetree.DiffType]bool)
+	if len(opts.Only) > 0 {
+		for _, diffType := range []filetree.DiffType{filetree.Unmodified, filetree.Modified, filetree.Added, filetree.Removed} {
+			hidden[diffType] = true
+		}
+		for _, diffType := range opts.Only {
+			hidden[diffType] = false
+		}
+	}
+
+	view := tree.Copy()
+
+	// this mirrors how the filetree pane hides paths (see FileTreeViewModel.Update)
+	err := view.VisitDepthChildFirst(func(node *filetree.FileNode) error {
+		node.Data.ViewInfo.Hidden = hidden[node.Data.DiffType]
+		visibleChild := false
+		for _, child := range node.Children {
+			if !child.Data.ViewInfo.Hidden {
+				visibleChild = true
+				node.Data.ViewInfo.Hidden = false
+			}
+		}
+		if opts.Filter != nil && !visibleChild && !node.Data.ViewInfo.Hidden {
+			node.Data.ViewInfo.Hidden = !opts.Filter.Match(node)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to filter tree: %w", err)
+	}
+
+	err = view.VisitDepthParentFirst(func(node *filetree.FileNode) error {
+		if node.Data.ViewInfo.Hidden {
+			return view.RemovePath(node.Path())
+		}
+		if opts.MaxDepth > 0 && depth(node) >= opts.MaxDepth && len(node.Children) > 0 {
+			node.Data.ViewInfo.Collapsed = true
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to filter tree: %w", err)
+	}
+
+	return view, nil
+}
+
+func depth(node *filetree.FileNode) int {
+	var d int
+	for n := node; n.Parent != nil; n = n.Parent {
+		d++
+	}
+	return d
+}
@@ -0,0 +1,144 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/dive/image/docker"
+)
+
+func testDocument(t *testing.T) Document {
+	t.Helper()
+	color.NoColor = true
+
+	archivePath := docker.TestRepoPath(t, ".data/test-docker-image.tar")
+	archive, err := docker.TestLoadArchive(t, archivePath)
+	require.NoError(t, err)
+	img, err := archive.ToImage(archivePath)
+	require.NoError(t, err)
+
+	return Document{Layers: img.Layers, Trees: img.Trees}
+}
+
+func TestDocument_Render(t *testing.T) {
+	doc := testDocument(t)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name: "single layer, only added",
+			opts: Options{Layer: 5, Only: []filetree.DiffType{filetree.Added}},
+			expected: `└── root
+    └── example
+        └── somefile2.txt
+`,
+		},
+		{
+			name: "aggregated range with filter",
+			opts: Options{Layer: 7, From: 2, Aggregated: true, Filter: mustQuery(t, "path:/root/example/*.txt")},
+			expected: `└── root
+    └── example
+        ├── somefile1.txt
+        ├── somefile2.txt
+        └── somefile3.txt
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := doc.Render(test.opts)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestDocument_Render_maxDepth(t *testing.T) {
+	doc := testDocument(t)
+
+	actual, err := doc.Render(Options{Layer: -1, MaxDepth: 1})
+	require.NoError(t, err)
+
+	for _, line := range strings.Split(strings.TrimSpace(actual), "\n") {
+		// top level entries only: no nested connectors
+		assert.True(t, strings.HasPrefix(line, "├") || strings.HasPrefix(line, "└"), "unexpected line %q", line)
+	}
+	assert.Contains(t, actual, "├─⊕ etc")
+}
+
+func TestDocument_Render_attributes(t *testing.T) {
+	doc := testDocument(t)
+
+	actual, err := doc.Render(Options{Layer: 5, Attributes: true, Only: []filetree.DiffType{filetree.Added}})
+	require.NoError(t, err)
+
+	lines := strings.Split(actual, "\n")
+	assert.Equal(t, "Permission Links     UID:GID       Size  Filetree", lines[0])
+	assert.Equal(t, "-rw-r--r--               0:0     6.4 kB          └── somefile2.txt", lines[3])
+}
+
+func TestDocument_Render_opaqueMarker(t *testing.T) {
+	color.NoColor = true
+
+	layers := [][]string{
+		{"/etc/config", "/var/cache/.wh..wh..opq", "/var/cache/index"},
+		{"/srv/.wh..wh..opq", "/srv/app"},
+	}
+	doc := Document{}
+	for idx, paths := range layers {
+		tree := filetree.NewFileTree()
+		for _, p := range paths {
+			_, _, err := tree.AddPath(p, filetree.FileInfo{Path: p, TypeFlag: '0', Size: 1})
+			require.NoError(t, err)
+		}
+		doc.Trees = append(doc.Trees, tree)
+		doc.Layers = append(doc.Layers, &image.Layer{Index: idx, Tree: tree})
+	}
+
+	// the markers are used to stack the layers, but are not shown as files
+	for _, opts := range []Options{{Layer: 0}, {Layer: 1}, {Layer: 1, Aggregated: true}} {
+		actual, err := doc.Render(opts)
+		require.NoError(t, err)
+		assert.NotContains(t, actual, ".wh.")
+		assert.Contains(t, actual, "cache")
+	}
+}
+
+func TestDocument_Render_invalid(t *testing.T) {
+	doc := testDocument(t)
+
+	_, err := doc.Render(Options{Layer: len(doc.Trees)})
+	assert.ErrorContains(t, err, "invalid layer")
+
+	_, err = doc.Render(Options{Layer: 2, From: 3, Aggregated: true})
+	assert.ErrorContains(t, err, "invalid range start")
+}
+
+func TestParseDiffTypes(t *testing.T) {
+	actual, err := ParseDiffTypes("added, Modified")
+	require.NoError(t, err)
+	assert.Equal(t, []filetree.DiffType{filetree.Added, filetree.Modified}, actual)
+
+	actual, err = ParseDiffTypes("")
+	require.NoError(t, err)
+	assert.Empty(t, actual)
+
+	_, err = ParseDiffTypes("added,changed")
+	assert.ErrorContains(t, err, `invalid diff type "changed"`)
+}
+
+func mustQuery(t *testing.T, expr string) *filetree.Query {
+	t.Helper()
+	q, err := filetree.ParseQuery(expr, nil)
+	require.NoError(t, err)
+	return q
+}
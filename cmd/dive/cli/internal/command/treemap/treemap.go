@@ -0,0 +1,145 @@
+package treemap
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/dive/filetree"
+)
+
+const (
+	width  = 1200
+	height = 720
+	// depth is the number of directory levels subdivided into tiles
+	depth = 4
+	// header is the room kept at the top of each directory tile for its label
+	header = 16
+	// charWidth is a rough width of a single label character, used to decide what fits within a tile
+	charWidth = 7
+)
+
+var diffColor = map[filetree.DiffType]string{
+	filetree.Unmodified: "#8796a8",
+	filetree.Modified:   "#e0b30d",
+	filetree.Added:      "#3fa34d",
+	filetree.Removed:    "#d9534f",
+}
+
+// Document is a standalone treemap of an image filetree, where the area of each tile is proportional to the size of
+// the path and the colour reflects how the path differs from the lower layers.
+type Document struct {
+	Image string
+	Tree  *filetree.FileTree
+}
+
+type page struct {
+	Image  string
+	Size   string
+	Width  int
+	Height int
+	Legend []legendEntry
+	Tiles  []tile
+}
+
+type legendEntry struct {
+	Name  string
+	Color string
+}
+
+type tile struct {
+	X, Y, Width, Height float64
+	Color               string
+	Depth               int
+	Title               string
+	Label               string
+	LabelX, LabelY      float64
+}
+
+// HTML renders the treemap as a self-contained HTML page with an inline SVG.
+func (d Document) HTML() ([]byte, error) {
+	if d.Tree == nil {
+		return nil, fmt.Errorf("no filetree to render")
+	}
+
+	p := page{
+		Image:  d.Image,
+		Size:   humanize.Bytes(uint64(d.Tree.Root.GetSize())),
+		Width:  width,
+		Height: height,
+	}
+	for _, diffType := range []filetree.DiffType{filetree.Unmodified, filetree.Modified, filetree.Added, filetree.Removed} {
+		p.Legend = append(p.Legend, legendEntry{Name: diffType.String(), Color: diffColor[diffType]})
+	}
+
+	for _, t := range filetree.Treemap(d.Tree.Root, width, height, depth, header) {
+		node := t.Node
+		entry := tile{
+			X:      t.X,
+			Y:      t.Y,
+			Width:  t.Width,
+			Height: t.Height,
+			Color:  diffColor[node.Data.DiffType],
+			Depth:  t.Depth,
+			Title:  fmt.Sprintf("%s\n%s (%s)", node.Path(), humanize.Bytes(uint64(node.GetSize())), node.Data.DiffType),
+			LabelX: t.X + 3,
+			LabelY: t.Y + header - 4,
+		}
+		if t.Width >= 3*charWidth && t.Height >= header {
+			entry.Label = fitLabel(node.Name, int(t.Width-6)/charWidth)
+		}
+		p.Tiles = append(p.Tiles, entry)
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, p); err != nil {
+		return nil, fmt.Errorf("unable to render treemap: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fitLabel truncates the label to the given number of characters.
+func fitLabel(label string, chars int) string {
+	runes := []rune(label)
+	if len(runes) <= chars {
+		return label
+	}
+	if chars <= 1 {
+		return ""
+	}
+	return string(runes[:chars-1]) + "…"
+}
+
+var pageTemplate = template.Must(template.New("treemap").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>dive treemap: {{.Image}}</title>
+<style>
+  body { font-family: sans-serif; margin: 1.5em; color: #222; }
+  h1 { font-size: 1.2em; margin: 0 0 0.25em 0; }
+  .legend span { display: inline-block; margin-right: 1.2em; }
+  .legend i { display: inline-block; width: 0.9em; height: 0.9em; margin-right: 0.3em; vertical-align: middle; }
+  svg rect { stroke: #fff; stroke-width: 1; }
+  svg rect:hover { stroke: #222; }
+  svg .d1 { fill-opacity: 0.55; }
+  svg .d2 { fill-opacity: 0.7; }
+  svg .d3 { fill-opacity: 0.85; }
+  svg .d4 { fill-opacity: 1; }
+  svg text { font-size: 11px; fill: #111; pointer-events: none; }
+</style>
+</head>
+<body>
+<h1>{{.Image}} ({{.Size}})</h1>
+<p class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Name}}</span>{{end}}</p>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+{{- range .Tiles}}
+<g><rect class="d{{.Depth}}" x="{{printf "%.2f" .X}}" y="{{printf "%.2f" .Y}}" width="{{printf "%.2f" .Width}}" height="{{printf "%.2f" .Height}}" fill="{{.Color}}"><title>{{.Title}}</title></rect>
+{{- if .Label}}<text x="{{printf "%.2f" .LabelX}}" y="{{printf "%.2f" .LabelY}}">{{.Label}}</text>{{end}}</g>
+{{- end}}
+</svg>
+</body>
+</html>
+`))
@@ -0,0 +1,47 @@
+package treemap
+
+import (
+	"archive/tar"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/filetree"
+)
+
+func TestDocument_HTML(t *testing.T) {
+	tree := filetree.NewFileTree()
+	for _, info := range []filetree.FileInfo{
+		{Path: "/usr/lib/libbig.so", TypeFlag: tar.TypeReg, Size: 4000},
+		{Path: "/etc/config", TypeFlag: tar.TypeReg, Size: 1000},
+	} {
+		_, _, err := tree.AddPath(info.Path, info)
+		require.NoError(t, err)
+	}
+	node, err := tree.GetNode("/etc/config")
+	require.NoError(t, err)
+	require.NoError(t, node.AssignDiffType(filetree.Added))
+
+	contents, err := Document{Image: "example:<latest>", Tree: tree}.HTML()
+	require.NoError(t, err)
+	page := string(contents)
+
+	assert.Contains(t, page, "example:&lt;latest&gt;")
+	assert.Contains(t, page, "<title>/usr/lib/libbig.so\n4.0 kB (Unmodified)</title>")
+	assert.Contains(t, page, "<title>/etc/config\n1.0 kB (Added)</title>")
+	assert.Contains(t, page, `fill="`+diffColor[filetree.Added]+`"`)
+	assert.Equal(t, 5, strings.Count(page, "<rect"), "expected a tile for every path")
+}
+
+func TestDocument_HTML_NoTree(t *testing.T) {
+	_, err := Document{Image: "example"}.HTML()
+	assert.Error(t, err)
+}
+
+func TestFitLabel(t *testing.T) {
+	assert.Equal(t, "site-packages", fitLabel("site-packages", 13))
+	assert.Equal(t, "site-p…", fitLabel("site-packages", 7))
+	assert.Equal(t, "", fitLabel("site-packages", 1))
+}
@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single .dockerignore pattern, which excludes a path (or re-includes it, for "!" patterns) when it
+// matches the path or any of its parent directories.
+type ignoreRule struct {
+	pattern string
+	include bool
+	re      *regexp.Regexp
+}
+
+// dockerignore is the set of rules read from the .dockerignore file of a build context. Later rules take precedence
+// over earlier ones.
+type dockerignore struct {
+	rules []ignoreRule
+}
+
+// readDockerignore reads the .dockerignore file of the given build context directory (if there is one).
+func readDockerignore(dir string) (*dockerignore, error) {
+	f, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if os.IsNotExist(err) {
+		return &dockerignore{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read .dockerignore: %w", err)
+	}
+	return parseDockerignore(patterns)
+}
+
+// parseDockerignore compiles the given .dockerignore lines (skipping blank lines and comments).
+func parseDockerignore(lines []string) (*dockerignore, error) {
+	ignore := &dockerignore{}
+	for _, line := range lines {
+		pattern := strings.TrimSpace(line)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		include := strings.HasPrefix(pattern, "!")
+		if include {
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+		// patterns are always relative to the root of the context
+		pattern = strings.TrimPrefix(path.Clean(filepath.ToSlash(pattern)), "/")
+		if pattern == "" || pattern == "." {
+			continue
+		}
+
+		re, err := regexp.Compile(ignorePatternToRegex(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid .dockerignore pattern %q: %w", line, err)
+		}
+		ignore.rules = append(ignore.rules, ignoreRule{pattern: pattern, include: include, re: re})
+	}
+	return ignore, nil
+}
+
+// excludes indicates if the given slash separated path (relative to the context root) is left out of the context.
+func (d *dockerignore) excludes(rel string) bool {
+	if d == nil {
+		return false
+	}
+
+	var excluded bool
+	for _, rule := range d.rules {
+		if rule.re.MatchString(rel) {
+			excluded = !rule.include
+		}
+	}
+	return excluded
+}
+
+// skipsDir indicates if nothing beneath the given directory (relative to the context root) can be part of the
+// context, so the directory does not need to be watched at all.
+func (d *dockerignore) skipsDir(rel string) bool {
+	if !d.excludes(rel) {
+		return false
+	}
+	for _, rule := range d.rules {
+		if rule.include && rule.mayMatchWithin(rel) {
+			return false
+		}
+	}
+	return true
+}
+
+// mayMatchWithin indicates if the rule could match a path beneath the given directory.
+func (r ignoreRule) mayMatchWithin(dir string) bool {
+	patternParts := strings.Split(r.pattern, "/")
+	for idx, part := range strings.Split(dir, "/") {
+		if idx >= len(patternParts) {
+			return false
+		}
+		if strings.Contains(patternParts[idx], "**") {
+			return true
+		}
+		if matched, err := path.Match(patternParts[idx], part); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ignorePatternToRegex converts a .dockerignore pattern to a regular expression anchored at the context root that
+// also matches everything beneath a matching directory. "**" matches any number of directories, "*" and "?" match
+// within a single path element.
+func ignorePatternToRegex(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	runes := []rune(pattern)
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch r {
+		case '*':
+			if idx+1 < len(runes) && runes[idx+1] == '*' {
+				idx++
+				if idx+1 < len(runes) && runes[idx+1] == '/' {
+					idx++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '\\':
+			if idx+1 < len(runes) {
+				idx++
+				sb.WriteString(regexp.QuoteMeta(string(runes[idx])))
+				continue
+			}
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		case '[':
+			end := idx + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			sb.WriteString("[" + string(runes[idx+1:end]) + "]")
+			idx = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("(/.*)?$")
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerignore(t *testing.T) {
+	ignore, err := parseDockerignore([]string{
+		"# comment",
+		"",
+		"node_modules",
+		"/dist/",
+		"**/*.log",
+		"docs/*.md",
+		"!docs/README.md",
+		"vendor",
+		"!vendor/keep/**",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		excluded bool
+	}{
+		{path: "node_modules", excluded: true},
+		{path: "node_modules/left-pad/index.js", excluded: true},
+		{path: "src/node_modules", excluded: false},
+		{path: "dist/app", excluded: true},
+		{path: "build.log", excluded: true},
+		{path: "src/debug/build.log", excluded: true},
+		{path: "docs/guide.md", excluded: true},
+		{path: "docs/README.md", excluded: false},
+		{path: "docs", excluded: false},
+		{path: "vendor", excluded: true},
+		{path: "vendor/other", excluded: true},
+		{path: "vendor/keep/mod.go", excluded: false},
+		{path: "main.go", excluded: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.excluded, ignore.excludes(test.path))
+		})
+	}
+
+	// excluded directories are only skipped when no exception could re-include anything beneath them
+	assert.True(t, ignore.skipsDir("node_modules"))
+	assert.True(t, ignore.skipsDir("vendor/other"))
+	assert.False(t, ignore.skipsDir("vendor"))
+	assert.False(t, ignore.skipsDir("docs"))
+	assert.False(t, ignore.skipsDir("src"))
+}
+
+func TestReadDockerignore_Missing(t *testing.T) {
+	ignore, err := readDockerignore(t.TempDir())
+	require.NoError(t, err)
+	assert.False(t, ignore.excludes("anything"))
+}
@@ -0,0 +1,262 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// Debounce is how long the watched paths must be left unchanged before a change is reported (so that saving several
+// files, or an editor writing a file in several steps, triggers a single rebuild).
+const Debounce = 500 * time.Millisecond
+
+// buildBoolFlags are the `docker build`, `docker buildx build` and `podman build` flags that take no value (all other
+// flags given without "=" are followed by their value).
+var buildBoolFlags = map[string]bool{
+	"--all-platforms":         true,
+	"--check":                 true,
+	"--compat-volumes":        true,
+	"--compress":              true,
+	"--disable-compression":   true,
+	"-D":                      true,
+	"--disable-content-trust": true,
+	"--force-rm":              true,
+	"--identity-label":        true,
+	"--layers":                true,
+	"--load":                  true,
+	"--no-cache":              true,
+	"--no-hosts":              true,
+	"--omit-history":          true,
+	"--pull":                  true,
+	"--push":                  true,
+	"-q":                      true,
+	"--quiet":                 true,
+	"--rm":                    true,
+	"--squash":                true,
+	"--squash-all":            true,
+	"--stdin":                 true,
+	"--tls-verify":            true,
+}
+
+// Paths returns the paths to watch for the given `docker build` arguments: the build context directory (the
+// positional argument) and the Dockerfile (when given with -f/--file).
+func Paths(buildArgs []string) ([]string, error) {
+	var paths []string
+	var buildContext string
+	for idx := 0; idx < len(buildArgs); idx++ {
+		arg := buildArgs[idx]
+		var file string
+		switch {
+		case arg == "--":
+			if idx+1 < len(buildArgs) {
+				buildContext = buildArgs[idx+1]
+			}
+			idx = len(buildArgs)
+			continue
+		case (arg == "-f" || arg == "--file") && idx+1 < len(buildArgs):
+			file = buildArgs[idx+1]
+			idx++
+		case strings.HasPrefix(arg, "--file="):
+			file = strings.TrimPrefix(arg, "--file=")
+		case strings.HasPrefix(arg, "-f="):
+			file = strings.TrimPrefix(arg, "-f=")
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			if !strings.Contains(arg, "=") && !buildBoolFlags[arg] {
+				// skip the value of the flag (e.g. the tag given with -t, which may name a directory too)
+				idx++
+			}
+			continue
+		default:
+			buildContext = arg
+			continue
+		}
+		// a Dockerfile read from stdin cannot be watched
+		if file != "-" {
+			paths = append(paths, file)
+		}
+	}
+
+	if info, err := os.Stat(buildContext); buildContext == "" || buildContext == "-" || err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("cannot find a local build context to watch")
+	}
+	return append(paths, buildContext), nil
+}
+
+// Watcher reports changes to the files within the watched paths (directories are watched recursively).
+type Watcher struct {
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+	// dirs are the directories watched recursively, along with the .dockerignore rules of each (paths left out of the
+	// build context are not watched)
+	dirs map[string]*dockerignore
+	// files are the individually watched files (their parent directory is watched, but only changes to the file
+	// itself are reported)
+	files map[string]bool
+}
+
+// New creates a watcher for the given files and directories, reporting a change once the paths have been left
+// unchanged for the given debounce duration.
+func New(debounce time.Duration, paths ...string) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		watcher:  watcher,
+		debounce: debounce,
+		dirs:     make(map[string]*dockerignore),
+		files:    make(map[string]bool),
+	}
+
+	for _, p := range paths {
+		if err := w.add(p); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+func (w *Watcher) add(p string) error {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("unable to watch %q: %w", p, err)
+	}
+
+	if !info.IsDir() {
+		// watch the parent directory since editors commonly replace a file when saving it (which would drop a watch
+		// on the file itself)
+		w.files[p] = true
+		return w.watcher.Add(filepath.Dir(p))
+	}
+
+	ignore, err := readDockerignore(p)
+	if err != nil {
+		return fmt.Errorf("unable to watch %q: %w", p, err)
+	}
+	w.dirs[p] = ignore
+	return w.addDir(p, p)
+}
+
+// addDir watches the given directory and all directories beneath it, skipping VCS metadata and directories left out
+// of the build context by the .dockerignore file of the watched root.
+func (w *Watcher) addDir(root, dir string) error {
+	ignore := w.dirs[root]
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if rel, _ := filepath.Rel(root, p); rel != "." && ignore.skipsDir(filepath.ToSlash(rel)) {
+			return filepath.SkipDir
+		}
+		if err := w.watcher.Add(p); err != nil {
+			return fmt.Errorf("unable to watch %q: %w", p, err)
+		}
+		return nil
+	})
+}
+
+// isWatched indicates if a change to the given path should be reported.
+func (w *Watcher) isWatched(p string) bool {
+	if w.files[p] {
+		return true
+	}
+	root, ok := w.rootOf(p)
+	if !ok {
+		return false
+	}
+	rel, _ := filepath.Rel(root, p)
+	rel = filepath.ToSlash(rel)
+	if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+		return false
+	}
+	// the builder reads these even when they are left out of the context
+	switch rel {
+	case ".dockerignore", "Dockerfile", "Containerfile":
+		return true
+	}
+	return !w.dirs[root].excludes(rel)
+}
+
+// rootOf returns the recursively watched directory that contains the given path.
+func (w *Watcher) rootOf(p string) (string, bool) {
+	for dir := range w.dirs {
+		if p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// Run calls onChange with the first changed path of each burst of changes, until the context is cancelled or the
+// watcher is closed. The watcher does not report further changes until onChange has returned.
+func (w *Watcher) Run(ctx context.Context, onChange func(path string)) error {
+	var changed string
+	var settled <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !w.isWatched(event.Name) || event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				// new directories within the build context need to be watched too
+				if root, ok := w.rootOf(event.Name); ok && isDir(event.Name) {
+					if err := w.addDir(root, event.Name); err != nil {
+						log.WithFields("path", event.Name, "error", err).Debug("unable to watch new directory")
+					}
+				}
+			}
+			if changed == "" {
+				changed = event.Name
+			}
+			settled = time.After(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.WithFields("error", err).Debug("file watcher error")
+		case <-settled:
+			onChange(changed)
+			changed = ""
+			settled = nil
+		}
+	}
+}
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+// Close stops watching all paths.
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
@@ -0,0 +1,162 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaths(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		wantErr  require.ErrorAssertionFunc
+	}{
+		{
+			name:     "context only",
+			args:     []string{"-t", "some:tag", dir},
+			expected: []string{dir},
+		},
+		{
+			name:     "separate dockerfile",
+			args:     []string{"-f", "other/Dockerfile", "--build-arg", "A=B", dir},
+			expected: []string{"other/Dockerfile", dir},
+		},
+		{
+			name:     "dockerfile given with equals",
+			args:     []string{"--file=other/Dockerfile", dir},
+			expected: []string{"other/Dockerfile", dir},
+		},
+		{
+			name:     "dockerfile from stdin",
+			args:     []string{"-f", "-", dir},
+			expected: []string{dir},
+		},
+		{
+			name:     "flag values naming directories",
+			args:     []string{"-t", other, "--build-arg", other, "--no-cache", dir},
+			expected: []string{dir},
+		},
+		{
+			name:     "flags after the context",
+			args:     []string{dir, "--pull", "--tag=" + other},
+			expected: []string{dir},
+		},
+		{
+			name:    "context from stdin",
+			args:    []string{"-t", other, "-"},
+			wantErr: require.Error,
+		},
+		{
+			name:    "remote context",
+			args:    []string{"https://github.com/wagoodman/dive.git"},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.wantErr == nil {
+				test.wantErr = require.NoError
+			}
+			actual, err := Paths(test.args)
+			test.wantErr(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules", "left-pad"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("node_modules\n*.log\n"), 0o644))
+	dockerfile := filepath.Join(other, "Dockerfile")
+	require.NoError(t, os.WriteFile(dockerfile, []byte("FROM scratch"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(other, "unrelated"), []byte("a"), 0o644))
+
+	w, err := New(50*time.Millisecond, dir, dockerfile)
+	require.NoError(t, err)
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan string, 10)
+	go func() {
+		_ = w.Run(ctx, func(p string) {
+			changes <- p
+		})
+	}()
+
+	expectChange := func(t *testing.T, expected string) {
+		t.Helper()
+		select {
+		case actual := <-changes:
+			assert.Equal(t, expected, actual)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change reported (expected %q)", expected)
+		}
+	}
+
+	expectNoChange := func(t *testing.T) {
+		t.Helper()
+		select {
+		case actual := <-changes:
+			t.Fatalf("unexpected change reported: %q", actual)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+
+	t.Run("burst of changes is reported once", func(t *testing.T) {
+		first := filepath.Join(dir, "first")
+		require.NoError(t, os.WriteFile(first, []byte("1"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "second"), []byte("2"), 0o644))
+		expectChange(t, first)
+		expectNoChange(t)
+	})
+
+	t.Run("new directories are watched", func(t *testing.T) {
+		sub := filepath.Join(dir, "sub")
+		require.NoError(t, os.Mkdir(sub, 0o755))
+		expectChange(t, sub)
+
+		nested := filepath.Join(sub, "nested")
+		require.NoError(t, os.WriteFile(nested, []byte("n"), 0o644))
+		expectChange(t, nested)
+	})
+
+	t.Run("dockerfile outside of the context", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(other, "unrelated"), []byte("b"), 0o644))
+		expectNoChange(t)
+
+		require.NoError(t, os.WriteFile(dockerfile, []byte("FROM alpine"), 0o644))
+		expectChange(t, dockerfile)
+	})
+
+	t.Run("paths left out of the context are ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "node_modules", "left-pad", "index.js"), []byte("x"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "build.log"), []byte("x"), 0o644))
+		expectNoChange(t)
+		assert.NotContains(t, w.watcher.WatchList(), filepath.Join(dir, "node_modules"))
+
+		ignore := filepath.Join(dir, ".dockerignore")
+		require.NoError(t, os.WriteFile(ignore, []byte("node_modules\n"), 0o644))
+		expectChange(t, ignore)
+	})
+
+	t.Run("vcs metadata is ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0o644))
+		expectNoChange(t)
+	})
+}
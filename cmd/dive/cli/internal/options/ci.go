@@ -59,6 +59,12 @@ func (c *CI) PostLoad() error {
 				LowestEfficiencyThresholdString: def.LowestEfficiencyThresholdString,
 				HighestWastedBytesString:        def.HighestWastedBytesString,
 				HighestUserWastedPercentString:  def.HighestUserWastedPercentString,
+				HighestCompressedSizeString:     def.HighestCompressedSizeString,
+				HighestDuplicateBytesString:     def.HighestDuplicateBytesString,
+				HighestLayerWastedBytesString:   def.HighestLayerWastedBytesString,
+				RequireNonRootUserString:        def.RequireNonRootUserString,
+				RequiredLabelsString:            def.RequiredLabelsString,
+				DisallowedFilesString:           def.DisallowedFilesString,
 			}
 			wrapper := struct {
 				Rules *legacyRuleFile `yaml:"rules"`
@@ -73,6 +79,12 @@ func (c *CI) PostLoad() error {
 				LowestEfficiencyThresholdString: r.LowestEfficiencyThresholdString,
 				HighestWastedBytesString:        r.HighestWastedBytesString,
 				HighestUserWastedPercentString:  r.HighestUserWastedPercentString,
+				HighestCompressedSizeString:     r.HighestCompressedSizeString,
+				HighestDuplicateBytesString:     r.HighestDuplicateBytesString,
+				HighestLayerWastedBytesString:   r.HighestLayerWastedBytesString,
+				RequireNonRootUserString:        r.RequireNonRootUserString,
+				RequiredLabelsString:            r.RequiredLabelsString,
+				DisallowedFilesString:           r.DisallowedFilesString,
 			}
 		}
 	}
@@ -84,6 +96,12 @@ type legacyRuleFile struct {
 	LowestEfficiencyThresholdString string `yaml:"lowestEfficiency"`
 	HighestWastedBytesString        string `yaml:"highestWastedBytes"`
 	HighestUserWastedPercentString  string `yaml:"highestUserWastedPercent"`
+	HighestCompressedSizeString     string `yaml:"highestCompressedSize"`
+	HighestDuplicateBytesString     string `yaml:"highestDuplicateBytes"`
+	HighestLayerWastedBytesString   string `yaml:"highestLayerWastedBytes"`
+	RequireNonRootUserString        string `yaml:"requireNonRootUser"`
+	RequiredLabelsString            string `yaml:"requiredLabels"`
+	DisallowedFilesString           string `yaml:"disallowedFiles"`
 }
 
 func fileExists(path string) bool {
@@ -16,6 +16,18 @@ type CIRules struct {
 	HighestUserWastedPercentString       string `yaml:"highest-user-wasted-percent" mapstructure:"highest-user-wasted-percent"`
 	LegacyHighestUserWastedPercentString string `yaml:"-" mapstructure:"highestUserWastedPercent"`
 
+	HighestCompressedSizeString string `yaml:"highest-compressed-size" mapstructure:"highest-compressed-size"`
+
+	HighestDuplicateBytesString string `yaml:"highest-duplicate-bytes" mapstructure:"highest-duplicate-bytes"`
+
+	HighestLayerWastedBytesString string `yaml:"highest-layer-wasted-bytes" mapstructure:"highest-layer-wasted-bytes"`
+
+	RequireNonRootUserString string `yaml:"require-non-root-user" mapstructure:"require-non-root-user"`
+
+	RequiredLabelsString string `yaml:"required-labels" mapstructure:"required-labels"`
+
+	DisallowedFilesString string `yaml:"disallowed-files" mapstructure:"disallowed-files"`
+
 	List []ci.Rule `yaml:"-" mapstructure:"-"`
 }
 
@@ -24,6 +36,12 @@ func DefaultCIRules() CIRules {
 		LowestEfficiencyThresholdString: "0.9",
 		HighestWastedBytesString:        "disabled",
 		HighestUserWastedPercentString:  "0.1",
+		HighestCompressedSizeString:     "disabled",
+		HighestDuplicateBytesString:     "disabled",
+		HighestLayerWastedBytesString:   "disabled",
+		RequireNonRootUserString:        "disabled",
+		RequiredLabelsString:            "disabled",
+		DisallowedFilesString:           "disabled",
 	}
 }
 
@@ -31,12 +49,24 @@ func (c *CIRules) DescribeFields(descriptions clio.FieldDescriptionSet) {
 	descriptions.Add(&c.LowestEfficiencyThresholdString, "lowest allowable image efficiency (as a ratio between 0-1), otherwise CI validation will fail.")
 	descriptions.Add(&c.HighestWastedBytesString, "highest allowable bytes wasted, otherwise CI validation will fail.")
 	descriptions.Add(&c.HighestUserWastedPercentString, "highest allowable percentage of bytes wasted (as a ratio between 0-1), otherwise CI validation will fail.")
+	descriptions.Add(&c.HighestCompressedSizeString, "highest allowable total compressed (wire) size of all layers, otherwise CI validation will fail.")
+	descriptions.Add(&c.HighestDuplicateBytesString, "highest allowable bytes spent on identical content at different paths, otherwise CI validation will fail.")
+	descriptions.Add(&c.HighestLayerWastedBytesString, "highest allowable bytes of any single layer that are whited out or overwritten by later layers, otherwise CI validation will fail.")
+	descriptions.Add(&c.RequireNonRootUserString, "when true, CI validation will fail if the image config does not set a non-root user.")
+	descriptions.Add(&c.RequiredLabelsString, "comma-separated list of labels the image config must have, otherwise CI validation will fail.")
+	descriptions.Add(&c.DisallowedFilesString, "filter query (as used by the file tree filter) that no path in the final image may match, otherwise CI validation will fail.")
 }
 
 func (c *CIRules) AddFlags(flags clio.FlagSet) {
 	flags.StringVarP(&c.LowestEfficiencyThresholdString, "lowestEfficiency", "", "(only valid with --ci given) lowest allowable image efficiency (as a ratio between 0-1), otherwise CI validation will fail.")
 	flags.StringVarP(&c.HighestWastedBytesString, "highestWastedBytes", "", "(only valid with --ci given) highest allowable bytes wasted, otherwise CI validation will fail.")
 	flags.StringVarP(&c.HighestUserWastedPercentString, "highestUserWastedPercent", "", "(only valid with --ci given) highest allowable percentage of bytes wasted (as a ratio between 0-1), otherwise CI validation will fail.")
+	flags.StringVarP(&c.HighestCompressedSizeString, "highestCompressedSize", "", "(only valid with --ci given) highest allowable total compressed (wire) size of all layers, otherwise CI validation will fail.")
+	flags.StringVarP(&c.HighestDuplicateBytesString, "highestDuplicateBytes", "", "(only valid with --ci given) highest allowable bytes spent on identical content at different paths, otherwise CI validation will fail.")
+	flags.StringVarP(&c.HighestLayerWastedBytesString, "highestLayerWastedBytes", "", "(only valid with --ci given) highest allowable bytes of any single layer that are whited out or overwritten by later layers, otherwise CI validation will fail.")
+	flags.StringVarP(&c.RequireNonRootUserString, "requireNonRootUser", "", "(only valid with --ci given) when true, CI validation will fail if the image config does not set a non-root user.")
+	flags.StringVarP(&c.RequiredLabelsString, "requiredLabels", "", "(only valid with --ci given) comma-separated list of labels the image config must have, otherwise CI validation will fail.")
+	flags.StringVarP(&c.DisallowedFilesString, "disallowedFiles", "", "(only valid with --ci given) filter query (as used by the file tree filter) that no path in the final image may match, otherwise CI validation will fail.")
 }
 
 func (c CIRules) hasLegacyOptionsInUse() bool {
@@ -69,5 +99,41 @@ func (c *CIRules) PostLoad() error {
 	}
 	c.List = append(c.List, rules...)
 
+	compressedSizeRule, err := ci.NewHighestCompressedSizeRule(c.HighestCompressedSizeString)
+	if err != nil {
+		return err
+	}
+	c.List = append(c.List, compressedSizeRule)
+
+	duplicateBytesRule, err := ci.NewHighestDuplicateBytesRule(c.HighestDuplicateBytesString)
+	if err != nil {
+		return err
+	}
+	c.List = append(c.List, duplicateBytesRule)
+
+	layerWastedBytesRule, err := ci.NewHighestLayerWastedBytesRule(c.HighestLayerWastedBytesString)
+	if err != nil {
+		return err
+	}
+	c.List = append(c.List, layerWastedBytesRule)
+
+	nonRootUserRule, err := ci.NewRequireNonRootUserRule(c.RequireNonRootUserString)
+	if err != nil {
+		return err
+	}
+	c.List = append(c.List, nonRootUserRule)
+
+	requiredLabelsRule, err := ci.NewRequiredLabelsRule(c.RequiredLabelsString)
+	if err != nil {
+		return err
+	}
+	c.List = append(c.List, requiredLabelsRule)
+
+	disallowedFilesRule, err := ci.NewDisallowedFilesRule(c.DisallowedFilesString)
+	if err != nil {
+		return err
+	}
+	c.List = append(c.List, disallowedFilesRule)
+
 	return nil
 }
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/anchore/clio"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/export"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/command/sbom"
 )
 
 var _ interface {
@@ -17,14 +21,52 @@ var _ interface {
 type Export struct {
 	// Path to export analysis results as JSON (empty string = disabled)
 	JsonPath string `yaml:"json-path" json:"json-path" mapstructure:"json-path"`
+
+	// Format of the JSON export: the versioned format ("v2") or the original unversioned format ("legacy")
+	JsonFormat string `yaml:"json-format" json:"json-format" mapstructure:"json-format"`
+
+	// SBOM documents to write, each as "format=path" (e.g. "cyclonedx=sbom.json")
+	SBOM []string `yaml:"sbom" json:"sbom" mapstructure:"sbom"`
+
+	// Path to write a standalone HTML treemap of the image filetree (empty string = disabled)
+	TreemapPath string `yaml:"treemap-path" json:"treemap-path" mapstructure:"treemap-path"`
+
+	// Path to write a self-contained HTML report of the analysis (empty string = disabled)
+	HTMLPath string `yaml:"html-path" json:"html-path" mapstructure:"html-path"`
+
+	// Path to write a markdown summary of the analysis, suitable for a pull request comment (empty string = disabled)
+	MarkdownPath string `yaml:"markdown-path" json:"markdown-path" mapstructure:"markdown-path"`
+
+	// Path to a previous JSON export to show changes against in the markdown summary (empty string = disabled)
+	BaselinePath string `yaml:"baseline-path" json:"baseline-path" mapstructure:"baseline-path"`
+
+	// Path to write a compressed binary snapshot of the full analysis, which can be explored later (empty string = disabled)
+	SnapshotPath string `yaml:"snapshot-path" json:"snapshot-path" mapstructure:"snapshot-path"`
+
+	sbomTargets []SBOMTarget
+}
+
+// SBOMTarget is a single SBOM document to write.
+type SBOMTarget struct {
+	Format string
+	Path   string
 }
 
 func DefaultExport() Export {
-	return Export{}
+	return Export{
+		JsonFormat: export.FormatV2,
+	}
 }
 
 func (o *Export) AddFlags(flags clio.FlagSet) {
 	flags.StringVarP(&o.JsonPath, "json", "j", "Skip the interactive TUI and write the layer analysis statistics to a given file.")
+	flags.StringVarP(&o.JsonFormat, "json-format", "", fmt.Sprintf("The format of the JSON export (see --json): %s.", strings.Join(export.Formats, ", ")))
+	flags.StringArrayVarP(&o.SBOM, "sbom", "", fmt.Sprintf("Skip the interactive TUI and write a software bill of materials as FORMAT=PATH (formats: %s). May be given multiple times.", strings.Join(sbom.Formats, ", ")))
+	flags.StringVarP(&o.TreemapPath, "treemap", "", "Skip the interactive TUI and write a treemap of the image filetree (as HTML with an inline SVG) to a given file.")
+	flags.StringVarP(&o.HTMLPath, "html", "", "Skip the interactive TUI and write a self-contained HTML report of the analysis to a given file.")
+	flags.StringVarP(&o.MarkdownPath, "markdown", "", "Skip the interactive TUI and write a markdown summary of the analysis and CI rule results (e.g. for a pull request comment) to a given file.")
+	flags.StringVarP(&o.SnapshotPath, "snapshot", "", "Skip the interactive TUI and write a compressed snapshot of the full analysis to a given file (explore it later with --from-export).")
+	flags.StringVarP(&o.BaselinePath, "baseline", "", "A previous JSON export (see --json) of the image to show changes against in the markdown summary.")
 }
 
 func (o *Export) PostLoad() error {
@@ -36,5 +78,93 @@ func (o *Export) PostLoad() error {
 		}
 	}
 
+	o.JsonFormat = strings.ToLower(strings.TrimSpace(o.JsonFormat))
+	if o.JsonFormat == "" {
+		o.JsonFormat = export.FormatV2
+	}
+	if !contains(export.Formats, o.JsonFormat) {
+		return fmt.Errorf("invalid JSON export format %q (supported: %s)", o.JsonFormat, strings.Join(export.Formats, ", "))
+	}
+
+	if o.TreemapPath != "" {
+		dir := path.Dir(o.TreemapPath)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return fmt.Errorf("directory for treemap export does not exist: %s", dir)
+		}
+	}
+
+	if o.HTMLPath != "" {
+		dir := path.Dir(o.HTMLPath)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return fmt.Errorf("directory for HTML report does not exist: %s", dir)
+		}
+	}
+
+	if o.MarkdownPath != "" {
+		dir := path.Dir(o.MarkdownPath)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return fmt.Errorf("directory for markdown summary does not exist: %s", dir)
+		}
+	}
+
+	if o.SnapshotPath != "" {
+		dir := path.Dir(o.SnapshotPath)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return fmt.Errorf("directory for snapshot does not exist: %s", dir)
+		}
+	}
+
+	if o.BaselinePath != "" {
+		if o.MarkdownPath == "" {
+			return fmt.Errorf("a baseline is only used by the markdown summary (see --markdown)")
+		}
+		if _, err := os.Stat(o.BaselinePath); os.IsNotExist(err) {
+			return fmt.Errorf("baseline export does not exist: %s", o.BaselinePath)
+		}
+	}
+
+	o.sbomTargets = nil
+	for _, value := range o.SBOM {
+		format, filePath, ok := strings.Cut(value, "=")
+		format = strings.ToLower(strings.TrimSpace(format))
+		filePath = strings.TrimSpace(filePath)
+		if !ok || filePath == "" {
+			return fmt.Errorf("invalid SBOM target %q: expected FORMAT=PATH", value)
+		}
+		if !contains(sbom.Formats, format) {
+			return fmt.Errorf("invalid SBOM format %q (supported: %s)", format, strings.Join(sbom.Formats, ", "))
+		}
+		dir := path.Dir(filePath)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return fmt.Errorf("directory for SBOM export does not exist: %s", dir)
+		}
+		o.sbomTargets = append(o.sbomTargets, SBOMTarget{Format: format, Path: filePath})
+	}
+
 	return nil
 }
+
+// Requested indicates if any export was asked for, in which case the interactive TUI is skipped.
+func (o Export) Requested() bool {
+	return o.JsonPath != "" || len(o.sbomTargets) > 0 || o.TreemapPath != "" || o.HTMLPath != "" || o.MarkdownPath != "" || o.SnapshotPath != ""
+}
+
+// NeedsPackageFiles indicates if an export catalogs the installed software (an SBOM, or a snapshot which keeps the
+// package files for a later SBOM), so the image must be read along with the contents of its package files.
+func (o Export) NeedsPackageFiles() bool {
+	return len(o.sbomTargets) > 0 || o.SnapshotPath != ""
+}
+
+// SBOMTargets returns the SBOM documents requested, as validated during PostLoad.
+func (o Export) SBOMTargets() []SBOMTarget {
+	return o.sbomTargets
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -12,10 +12,12 @@ var _ interface {
 
 // UIKeybindings provides configuration for all keyboard shortcuts
 type UIKeybindings struct {
-	Global     GlobalBindings     `yaml:",inline" mapstructure:",squash"`
-	Navigation NavigationBindings `yaml:",inline" mapstructure:",squash"`
-	Layer      LayerBindings      `yaml:",inline" mapstructure:",squash"`
-	Filetree   FiletreeBindings   `yaml:",inline" mapstructure:",squash"`
+	Global       GlobalBindings       `yaml:",inline" mapstructure:",squash"`
+	Navigation   NavigationBindings   `yaml:",inline" mapstructure:",squash"`
+	Layer        LayerBindings        `yaml:",inline" mapstructure:",squash"`
+	Filetree     FiletreeBindings     `yaml:",inline" mapstructure:",squash"`
+	ImageDetails ImageDetailsBindings `yaml:",inline" mapstructure:",squash"`
+	Largest      LargestBindings      `yaml:",inline" mapstructure:",squash"`
 
 	Config key.Bindings `yaml:"-" mapstructure:"-"`
 }
@@ -25,6 +27,8 @@ type GlobalBindings struct {
 	ToggleView       string `yaml:"toggle-view" mapstructure:"toggle-view"`
 	FilterFiles      string `yaml:"filter-files" mapstructure:"filter-files"`
 	CloseFilterFiles string `yaml:"close-filter-files" mapstructure:"close-filter-files"`
+	SearchLayers     string `yaml:"search-layers" mapstructure:"search-layers"`
+	ToggleLargest    string `yaml:"toggle-largest" mapstructure:"toggle-largest"`
 }
 
 type NavigationBindings struct {
@@ -39,6 +43,7 @@ type NavigationBindings struct {
 type LayerBindings struct {
 	CompareAll   string `yaml:"compare-all" mapstructure:"compare-all"`
 	CompareLayer string `yaml:"compare-layer" mapstructure:"compare-layer"`
+	ToggleSort   string `yaml:"toggle-layer-sort-order" mapstructure:"toggle-layer-sort-order"`
 }
 
 type FiletreeBindings struct {
@@ -52,6 +57,18 @@ type FiletreeBindings struct {
 	ToggleSortOrder       string `yaml:"toggle-sort-order" mapstructure:"toggle-sort-order"`
 	ToggleWrapTree        string `yaml:"toggle-wrap-tree" mapstructure:"toggle-wrap-tree"`
 	ExtractFile           string `yaml:"extract-file" mapstructure:"extract-file"`
+	TogglePathHistory     string `yaml:"toggle-path-history" mapstructure:"toggle-path-history"`
+	CyclePathLayers       string `yaml:"cycle-path-layers" mapstructure:"cycle-path-layers"`
+	ToggleTreemap         string `yaml:"toggle-treemap" mapstructure:"toggle-treemap"`
+}
+
+type ImageDetailsBindings struct {
+	ToggleImageConfig string `yaml:"toggle-image-config" mapstructure:"toggle-image-config"`
+	GotoFile          string `yaml:"goto-file" mapstructure:"goto-file"`
+}
+
+type LargestBindings struct {
+	ToggleScope string `yaml:"toggle-largest-scope" mapstructure:"toggle-largest-scope"`
 }
 
 func DefaultUIKeybinding() UIKeybindings {
@@ -151,7 +168,9 @@ func (c *UIKeybindings) DescribeFields(descriptions clio.FieldDescriptionSet) {
 	descriptions.Add(&c.Global.Quit, "quit the application (global)")
 	descriptions.Add(&c.Global.ToggleView, "toggle between different views (global)")
 	descriptions.Add(&c.Global.FilterFiles, "filter files by name (global)")
-	descriptions.Add(&c.Global.CloseFilterFiles, "close file filtering (global)")
+	descriptions.Add(&c.Global.CloseFilterFiles, "close file filtering or the layer search (global)")
+	descriptions.Add(&c.Global.SearchLayers, "search every layer for matching paths (global)")
+	descriptions.Add(&c.Global.ToggleLargest, "show the largest files and directories (global)")
 
 	// navigation keybindings
 	descriptions.Add(&c.Navigation.Up, "move cursor up (global)")
@@ -164,6 +183,7 @@ func (c *UIKeybindings) DescribeFields(descriptions clio.FieldDescriptionSet) {
 	// layer view keybindings
 	descriptions.Add(&c.Layer.CompareAll, "compare all layers (layer view)")
 	descriptions.Add(&c.Layer.CompareLayer, "compare specific layer (layer view)")
+	descriptions.Add(&c.Layer.ToggleSort, "toggle sorting layers by wasted bytes (layer view)")
 
 	// file view keybindings
 	descriptions.Add(&c.Filetree.ToggleCollapseDir, "toggle directory collapse (file view)")
@@ -175,4 +195,14 @@ func (c *UIKeybindings) DescribeFields(descriptions clio.FieldDescriptionSet) {
 	descriptions.Add(&c.Filetree.ToggleTreeAttributes, "toggle display of file attributes (file view)")
 	descriptions.Add(&c.Filetree.ToggleSortOrder, "toggle sort order (file view)")
 	descriptions.Add(&c.Filetree.ExtractFile, "extract file contents (file view)")
+	descriptions.Add(&c.Filetree.TogglePathHistory, "show the history of the selected path across all layers (file view)")
+	descriptions.Add(&c.Filetree.CyclePathLayers, "select the next layer that touches the selected path (file view)")
+	descriptions.Add(&c.Filetree.ToggleTreemap, "show the directory holding the selected path as a treemap (file view)")
+
+	// image details view keybindings
+	descriptions.Add(&c.ImageDetails.ToggleImageConfig, "expand or collapse the image config (image details view)")
+	descriptions.Add(&c.ImageDetails.GotoFile, "select the highlighted inefficient, duplicate or largest file in the file tree (image details and largest paths views)")
+
+	// largest paths view keybindings
+	descriptions.Add(&c.Largest.ToggleScope, "switch between the whole image and the selected layer (largest paths view)")
 }
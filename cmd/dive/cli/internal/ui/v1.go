@@ -137,9 +137,10 @@ func (n *V1UI) Handle(e partybus.Event) error {
 		n.writeToStderr("")
 		n.writeToStdout(text)
 	case event.ExploreAnalysis:
-		analysis, content, err := parser.ParseExploreAnalysis(e)
+		explore, err := parser.ParseExploreAnalysis(e)
 		if err != nil {
 			log.WithFields("error", err, "event", fmt.Sprintf("%#v", e)).Warn("failed to parse event")
+			return nil
 		}
 
 		// ensure the logger will not interfere with the UI
@@ -149,9 +150,10 @@ func (n *V1UI) Handle(e partybus.Event) error {
 			// TODO: this is not plumbed through from the command object...
 			context.Background(),
 			v1.Config{
-				Content:     content,
-				Analysis:    analysis,
+				Content:     explore.Content,
+				Analysis:    explore.Analysis,
 				Preferences: n.cfg,
+				Rebuilds:    explore.Rebuilds,
 			},
 		)
 	}
@@ -2,12 +2,17 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"github.com/awesome-gocui/gocui"
+	"github.com/dustin/go-humanize"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/key"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/layout"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/layout/compound"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
 	"golang.org/x/net/context"
+	"path/filepath"
 	"time"
 )
 
@@ -36,14 +41,17 @@ func Run(ctx context.Context, c v1.Config) error {
 	}
 	defer g.Close()
 
-	_, err = newApp(ctx, g, c)
-	if err != nil {
+	a := &app{gui: g}
+	// the manager is set once (setting it deletes all views and keybindings), while the layout is replaced when the
+	// image is rebuilt
+	g.SetManagerFunc(a.layoutViews)
+
+	if err := a.load(ctx, c); err != nil {
 		return err
 	}
 
-	k, mod := gocui.MustParse("Ctrl+Z")
-	if err := g.SetKeybinding("", k, mod, handle_ctrl_z); err != nil {
-		return err
+	if c.Rebuilds != nil {
+		go a.watchRebuilds(c.Rebuilds)
 	}
 
 	if err := g.MainLoop(); err != nil && !errors.Is(err, gocui.ErrQuit) {
@@ -52,20 +60,22 @@ func Run(ctx context.Context, c v1.Config) error {
 	return nil
 }
 
-func newApp(ctx context.Context, gui *gocui.Gui, cfg v1.Config) (*app, error) {
-	var err error
-	var c *controller
-	var globalHelpKeys []*key.Binding
+// load creates the controller and the layout for the given analysis, along with the global keybindings.
+func (a *app) load(ctx context.Context, cfg v1.Config) error {
+	gui := a.gui
 
-	c, err = newController(ctx, gui, cfg)
+	c, err := newController(ctx, gui, cfg)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// note: order matters when adding elements to the layout
 	lm := layout.NewManager()
 	lm.Add(c.views.Status, layout.LocationFooter)
 	lm.Add(c.views.Filter, layout.LocationFooter)
+	lm.Add(c.views.PathHistory, layout.LocationFooter)
+	lm.Add(c.views.Search, layout.LocationFooter)
+	lm.Add(c.views.Largest, layout.LocationFooter)
 	lm.Add(compound.NewLayerDetailsCompoundLayout(c.views.Layer, c.views.LayerDetails, c.views.ImageDetails), layout.LocationColumn)
 	lm.Add(c.views.Tree, layout.LocationColumn)
 
@@ -75,13 +85,6 @@ func newApp(ctx context.Context, gui *gocui.Gui, cfg v1.Config) (*app, error) {
 	}
 	gui.Cursor = false
 	// g.Mouse = true
-	gui.SetManagerFunc(lm.Layout)
-
-	a := &app{
-		gui:        gui,
-		controller: c,
-		layout:     lm,
-	}
 
 	var infos = []key.BindingInfo{
 		{
@@ -110,27 +113,130 @@ func newApp(ctx context.Context, gui *gocui.Gui, cfg v1.Config) (*app, error) {
 		},
 		{
 			Config:   cfg.Preferences.KeyBindings.Global.CloseFilterFiles,
-			OnAction: c.CloseFilterView,
+			OnAction: c.ClosePane,
+		},
+		{
+			Config:     cfg.Preferences.KeyBindings.Global.SearchLayers,
+			OnAction:   c.ToggleSearchView,
+			IsSelected: c.views.Search.IsVisible,
+			Display:    "Search",
+		},
+		{
+			Config:     cfg.Preferences.KeyBindings.Global.ToggleLargest,
+			OnAction:   c.ToggleLargestView,
+			IsSelected: c.views.Largest.IsVisible,
+			Display:    "Largest",
 		},
 	}
 
-	globalHelpKeys, err = key.GenerateBindings(gui, "", infos)
+	globalHelpKeys, err := key.GenerateBindings(gui, "", infos)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	k, mod := gocui.MustParse("Ctrl+Z")
+	if err := gui.SetKeybinding("", k, mod, handle_ctrl_z); err != nil {
+		return err
 	}
 
 	c.views.Status.AddHelpKeys(globalHelpKeys...)
 
+	a.controller, a.layout = c, lm
+
 	// perform the first update and render now that all resources have been loaded
-	err = c.UpdateAndRender()
-	if err != nil {
-		return nil, err
-	}
+	return c.UpdateAndRender()
+}
 
-	return a, err
+// layoutViews is the gocui manager, which lays out the views of the current controller.
+func (a *app) layoutViews(g *gocui.Gui) error {
+	return a.layout.Layout(g)
 }
 
 // quit is the gocui callback invoked when the user hits Ctrl+C
 func (a *app) quit() error {
 	return gocui.ErrQuit
 }
+
+// watchRebuilds reports the progress of each rebuild of the image in the status pane, refreshing the UI with the
+// analysis of each completed rebuild (until the channel is closed).
+func (a *app) watchRebuilds(rebuilds <-chan payload.Rebuild) {
+	for rebuild := range rebuilds {
+		a.gui.Update(func(*gocui.Gui) error {
+			return a.onRebuild(rebuild)
+		})
+	}
+}
+
+func (a *app) onRebuild(rebuild payload.Rebuild) error {
+	switch {
+	case rebuild.Err != nil:
+		a.controller.views.Status.SetMessage(fmt.Sprintf("rebuild failed: %v", rebuild.Err), true)
+	case rebuild.Analysis == nil:
+		a.controller.views.Status.SetMessage(fmt.Sprintf("rebuilding (%s changed)...", filepath.Base(rebuild.Trigger)), false)
+	default:
+		previous := a.controller.config.Analysis
+		if err := a.refresh(*rebuild.Analysis); err != nil {
+			a.controller.views.Status.SetMessage(fmt.Sprintf("rebuild failed: %v", err), true)
+			break
+		}
+		a.controller.views.Status.SetMessage(rebuildSummary(previous, *rebuild.Analysis, a.controller.views.Layer.RebuiltCount()), false)
+	}
+	return a.controller.views.Status.Render()
+}
+
+// refresh replaces the explored analysis with the given one (from a rebuild of the image), keeping the selected
+// layer, the file tree options, the collapsed directories and the focused pane where possible.
+func (a *app) refresh(analysis image.Analysis) error {
+	previous := a.controller
+
+	var focused string
+	if v := a.gui.CurrentView(); v != nil {
+		focused = v.Name()
+	}
+
+	a.reset()
+
+	err := a.load(previous.ctx, v1.Config{
+		Analysis:    analysis,
+		Content:     previous.config.Content,
+		Preferences: previous.config.Preferences,
+		Rebuilds:    previous.config.Rebuilds,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the views are usually created on the next layout, but they must exist to restore their state
+	if err := a.layout.Layout(a.gui); err != nil {
+		return err
+	}
+
+	return a.controller.restore(previous, focused)
+}
+
+// reset removes all views and keybindings, so that the views of the next controller are set up from scratch and
+// only its keybindings handle input.
+func (a *app) reset() {
+	var names []string
+	for _, v := range a.gui.Views() {
+		names = append(names, v.Name())
+	}
+	for _, name := range names {
+		a.gui.DeleteKeybindings(name)
+		_ = a.gui.DeleteView(name)
+	}
+	// global keybindings (including the ones of views that were not created yet)
+	a.gui.DeleteKeybindings("")
+}
+
+// rebuildSummary describes how a rebuilt image differs from its previous build.
+func rebuildSummary(previous, current image.Analysis, rebuiltLayers int) string {
+	size := "same size"
+	switch {
+	case current.SizeBytes > previous.SizeBytes:
+		size = "+" + humanize.Bytes(current.SizeBytes-previous.SizeBytes)
+	case current.SizeBytes < previous.SizeBytes:
+		size = "-" + humanize.Bytes(previous.SizeBytes-current.SizeBytes)
+	}
+	return fmt.Sprintf("rebuilt: %d of %d layers changed (%s)", rebuiltLayers, len(current.Layers), size)
+}
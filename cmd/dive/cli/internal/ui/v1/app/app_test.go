@@ -0,0 +1,71 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/options"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/dive/image/docker"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
+)
+
+func testAnalysis(t *testing.T) *image.Analysis {
+	t.Helper()
+	return docker.TestAnalysisFromArchive(t, docker.TestRepoPath(t, ".data/test-docker-image.tar"))
+}
+
+func TestOnRebuild(t *testing.T) {
+	g, err := gocui.NewGui(gocui.OutputSimulator, true)
+	require.NoError(t, err)
+	defer g.Close()
+
+	opts := options.DefaultApplication()
+	require.NoError(t, opts.UI.Keybinding.PostLoad())
+
+	a := &app{gui: g}
+	g.SetManagerFunc(a.layoutViews)
+	require.NoError(t, a.load(context.Background(), v1.Config{
+		Analysis:    *testAnalysis(t),
+		Preferences: opts.V1Preferences(),
+	}))
+
+	screen := g.GetTestingScreen()
+	stop := screen.StartGui()
+	defer stop()
+
+	rebuild := func() {
+		t.Helper()
+		done := make(chan error, 1)
+		g.Update(func(*gocui.Gui) error {
+			err := a.onRebuild(payload.Rebuild{Trigger: "Dockerfile", Analysis: testAnalysis(t)})
+			done <- err
+			return err
+		})
+		require.NoError(t, <-done)
+		screen.WaitSync()
+	}
+
+	screen.SendKeySync(gocui.KeyArrowDown)
+	assert.Equal(t, 1, a.controller.views.Layer.CurrentLayer().Index)
+
+	for expected := 2; expected <= 3; expected++ {
+		previous := a.controller
+		rebuild()
+		require.NotSame(t, previous, a.controller)
+
+		// the views of the new controller are set up (and the selected layer is kept)
+		assert.True(t, a.controller.views.LayerDetails.IsVisible())
+		assert.Equal(t, expected-1, a.controller.views.Layer.CurrentLayer().Index)
+
+		// only the keybindings of the new controller handle input
+		screen.SendKeySync(gocui.KeyArrowDown)
+		assert.Equal(t, expected, a.controller.views.Layer.CurrentLayer().Index)
+		assert.Equal(t, expected-1, previous.views.Layer.CurrentLayer().Index)
+	}
+}
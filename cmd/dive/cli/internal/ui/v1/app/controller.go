@@ -1,12 +1,14 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/view"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/viewmodel"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/internal/log"
 	"golang.org/x/net/context"
-	"regexp"
 
 	"github.com/awesome-gocui/gocui"
 )
@@ -40,9 +42,26 @@ func newController(ctx context.Context, g *gocui.Gui, cfg v1.Config) (*controlle
 	// update the status pane when a filetree option is changed by the user
 	c.views.Tree.AddViewExtractListener(c.onFileTreeViewExtract)
 
+	// show (and follow) the history of the selected path
+	c.views.Tree.AddViewPathHistoryListener(c.views.PathHistory.IsVisible, c.onFileTreePathHistory)
+	c.views.Tree.AddViewSelectionListener(c.onFileTreeSelectionChange)
+
+	// jump to a path (and step through the layers that touch it)
+	c.views.ImageDetails.AddViewGotoListener(c.onImageDetailsGoto)
+	c.views.Tree.AddViewCycleLayersListener(c.onFileTreeCycleLayers)
+
 	// update the tree view while the user types into the filter view
 	c.views.Filter.AddFilterEditListener(c.onFilterEdit)
 
+	// jump to the layer and path of a search result
+	c.views.Search.AddSearchSelectListener(c.onSearchSelect)
+
+	// update the status pane as the search results (or the selected result) change
+	c.views.Search.AddSearchChangeListener(c.onFileTreeViewOptionChange)
+
+	// jump to one of the largest files or directories
+	c.views.Largest.AddLargestGotoListener(c.onLargestGoto)
+
 	// propagate initial conditions to necessary views
 	err = c.onLayerChange(viewmodel.LayerSelection{
 		Layer:           c.views.Layer.CurrentLayer(),
@@ -59,10 +78,127 @@ func newController(ctx context.Context, g *gocui.Gui, cfg v1.Config) (*controlle
 	return c, nil
 }
 
+// restore carries the selection, the view options and the focused pane of the controller for a previous build of the
+// image over to this one.
+func (c *controller) restore(previous *controller, focused string) error {
+	err := c.views.Layer.Restore(previous.views.Layer)
+	if err != nil {
+		return err
+	}
+
+	err = c.views.Tree.Restore(previous.views.Tree)
+	if err != nil {
+		return err
+	}
+
+	panes := []interface {
+		view.Helper
+		Name() string
+	}{c.views.Layer, c.views.LayerDetails, c.views.ImageDetails, c.views.Tree}
+	for _, pane := range panes {
+		if pane.Name() != focused {
+			continue
+		}
+		_, err = c.gui.SetCurrentView(pane.Name())
+		if err != nil {
+			return fmt.Errorf("controller unable to restore the focused pane: %w", err)
+		}
+		c.views.Status.SetCurrentView(pane)
+	}
+
+	return c.UpdateAndRender()
+}
+
 func (c *controller) onFileTreeViewExtract(p string) error {
 	return c.config.Content.Extract(c.ctx, c.config.Analysis.Image, c.views.LayerDetails.CurrentLayer.Id, p)
 }
 
+func (c *controller) onFileTreePathHistory(p string) error {
+	c.views.PathHistory.ToggleVisible()
+	c.views.PathHistory.SetPath(p)
+	return c.UpdateAndRender()
+}
+
+func (c *controller) onFileTreeSelectionChange(p string) error {
+	if !c.views.PathHistory.IsVisible() {
+		return nil
+	}
+	c.views.PathHistory.SetPath(p)
+	err := c.views.PathHistory.Update()
+	if err != nil {
+		return err
+	}
+	return c.views.PathHistory.Render()
+}
+
+// onImageDetailsGoto selects the first layer that touches the given path and highlights the path in the file tree.
+func (c *controller) onImageDetailsGoto(p string) error {
+	history := filetree.History(c.config.Analysis.RefTrees, p)
+	if len(history) == 0 {
+		return nil
+	}
+	return c.selectPath(p, history[0].Layer)
+}
+
+// onFileTreeCycleLayers selects the next layer (wrapping around) that touches the given path.
+func (c *controller) onFileTreeCycleLayers(p string) error {
+	history := filetree.History(c.config.Analysis.RefTrees, p)
+	if len(history) == 0 {
+		return nil
+	}
+	next := history[0].Layer
+	current := c.views.Layer.CurrentLayer().Index
+	for _, entry := range history {
+		if entry.Layer > current {
+			next = entry.Layer
+			break
+		}
+	}
+	return c.selectPath(p, next)
+}
+
+// onSearchSelect selects the layer of the chosen search result and highlights its path in the file tree.
+func (c *controller) onSearchSelect(result filetree.SearchResult) error {
+	return c.selectPath(result.Path, result.Layer)
+}
+
+// onLargestGoto selects the given layer (or, when any layer will do, the last layer that touches the path) and
+// highlights the path in the file tree.
+func (c *controller) onLargestGoto(p string, layer int) error {
+	if layer < 0 {
+		history := filetree.History(c.config.Analysis.RefTrees, p)
+		if len(history) == 0 {
+			return nil
+		}
+		layer = history[len(history)-1].Layer
+	}
+	return c.selectPath(p, layer)
+}
+
+// selectPath moves the layer cursor to the given layer, then expands and highlights the path in the file tree.
+func (c *controller) selectPath(p string, layer int) error {
+	err := c.views.Layer.SetCursor(layer)
+	if err != nil {
+		return err
+	}
+
+	found, err := c.views.Tree.SelectPath(p)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.WithFields("path", p, "layer", layer).Debug("path not shown in the file tree")
+	}
+
+	_, err = c.gui.SetCurrentView(c.views.Tree.Name())
+	if err != nil {
+		return fmt.Errorf("controller unable to select the file tree: %w", err)
+	}
+	c.views.Status.SetCurrentView(c.views.Tree)
+
+	return c.UpdateAndRender()
+}
+
 func (c *controller) onFileTreeViewOptionChange() error {
 	err := c.views.Status.Update()
 	if err != nil {
@@ -71,18 +207,22 @@ func (c *controller) onFileTreeViewOptionChange() error {
 	return c.views.Status.Render()
 }
 
-func (c *controller) onFilterEdit(filter string) error {
-	var filterRegex *regexp.Regexp
+func (c *controller) onFilterEdit(expr string) error {
+	var filter *filetree.Query
 	var err error
 
-	if len(filter) > 0 {
-		filterRegex, err = regexp.Compile(filter)
-		if err != nil {
-			return err
-		}
+	if len(expr) > 0 {
+		filter, err = filetree.ParseQuery(expr, c.config.Analysis.RefTrees)
 	}
 
-	c.views.Tree.SetFilterRegex(filterRegex)
+	// show why the query is invalid in the status bar, but keep the last valid filter while the user is typing
+	c.views.Filter.SetError(err)
+	statusErr := c.views.Status.Render()
+	if err != nil || statusErr != nil {
+		return errors.Join(err, statusErr)
+	}
+
+	c.views.Tree.SetFilter(filter)
 
 	err = c.views.Tree.Update()
 	if err != nil {
@@ -95,9 +235,13 @@ func (c *controller) onFilterEdit(filter string) error {
 func (c *controller) onLayerChange(selection viewmodel.LayerSelection) error {
 	// update the details
 	c.views.LayerDetails.CurrentLayer = selection.Layer
+	err := c.views.Largest.SetLayer(selection.Layer.Index)
+	if err != nil {
+		return err
+	}
 
 	// update the filetree
-	err := c.views.Tree.SetTree(selection.BottomTreeStart, selection.BottomTreeStop, selection.TopTreeStart, selection.TopTreeStop)
+	err = c.views.Tree.SetTree(selection.BottomTreeStart, selection.BottomTreeStop, selection.TopTreeStart, selection.TopTreeStop)
 	if err != nil {
 		return err
 	}
@@ -216,6 +360,17 @@ func (c *controller) ToggleView() (err error) {
 	return c.UpdateAndRender()
 }
 
+// ClosePane closes the layer search (or the largest paths pane) when it has focus, otherwise the file tree filter.
+func (c *controller) ClosePane() error {
+	if c.isCurrentView(c.views.Search) {
+		return c.ToggleSearchView()
+	}
+	if c.isCurrentView(c.views.Largest) {
+		return c.ToggleLargestView()
+	}
+	return c.CloseFilterView()
+}
+
 func (c *controller) CloseFilterView() error {
 	// filter view needs to be visible
 	if c.views.Filter.IsVisible() {
@@ -232,10 +387,16 @@ func (c *controller) ToggleFilterView() error {
 		return fmt.Errorf("unable to toggle filter visibility: %w", err)
 	}
 
+	// we have just shown the filter view, so show its help (and any query errors) in the status bar
+	if c.views.Filter.IsVisible() {
+		c.views.Status.SetCurrentView(c.views.Filter)
+	}
+
 	// we have just hidden the filter view...
 	if !c.views.Filter.IsVisible() {
 		// ...remove any filter from the tree
-		c.views.Tree.SetFilterRegex(nil)
+		c.views.Tree.SetFilter(nil)
+		c.views.Filter.SetError(nil)
 
 		// ...adjust focus to a valid (visible) view
 		err = c.ToggleView()
@@ -246,3 +407,56 @@ func (c *controller) ToggleFilterView() error {
 
 	return c.UpdateAndRender()
 }
+
+// focusPane is a footer pane that takes the focus while it is shown.
+type focusPane interface {
+	view.View
+	view.Helper
+	ToggleVisible() error
+	Focus() error
+}
+
+// ToggleSearchView shows and focuses the layer search pane, or hides it when it already has focus.
+func (c *controller) ToggleSearchView() error {
+	return c.toggleFocusPane(c.views.Search)
+}
+
+// ToggleLargestView shows and focuses the largest paths pane, or hides it when it already has focus.
+func (c *controller) ToggleLargestView() error {
+	return c.toggleFocusPane(c.views.Largest)
+}
+
+// toggleFocusPane shows and focuses the given pane, or hides it when it already has focus. When the pane is shown but
+// another pane has focus (e.g. after going to a result) the pane is focused again instead.
+func (c *controller) toggleFocusPane(pane focusPane) error {
+	if pane.IsVisible() && !c.isCurrentView(pane) {
+		err := pane.Focus()
+		if err != nil {
+			return err
+		}
+		c.views.Status.SetCurrentView(pane)
+		return c.UpdateAndRender()
+	}
+
+	err := pane.ToggleVisible()
+	if err != nil {
+		return fmt.Errorf("unable to toggle %s visibility: %w", pane.Name(), err)
+	}
+
+	if pane.IsVisible() {
+		c.views.Status.SetCurrentView(pane)
+	} else {
+		// we have just hidden the pane, adjust focus to a valid (visible) view
+		err = c.ToggleView()
+		if err != nil {
+			return fmt.Errorf("unable to toggle %s view (back): %w", pane.Name(), err)
+		}
+	}
+
+	return c.UpdateAndRender()
+}
+
+func (c *controller) isCurrentView(v view.View) bool {
+	current := c.gui.CurrentView()
+	return current != nil && current.Name() == v.Name()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/key"
 	"github.com/wagoodman/dive/dive/filetree"
 	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/bus/event/payload"
 	"golang.org/x/net/context"
 	"sync"
 )
@@ -16,6 +17,9 @@ type Config struct {
 	Content     ContentReader
 	Preferences Preferences
 
+	// optional input
+	Rebuilds <-chan payload.Rebuild
+
 	stack     filetree.Comparer
 	stackErrs error
 	do        *sync.Once
@@ -40,12 +40,18 @@ const (
 var (
 	Header                func(...interface{}) string
 	Selected              func(...interface{}) string
+	Faint                 func(...interface{}) string
 	StatusSelected        func(...interface{}) string
 	StatusNormal          func(...interface{}) string
 	StatusControlSelected func(...interface{}) string
 	StatusControlNormal   func(...interface{}) string
 	CompareTop            func(...interface{}) string
 	CompareBottom         func(...interface{}) string
+	Wasted                func(...interface{}) string
+	MostWasted            func(...interface{}) string
+	Rebuilt               func(...interface{}) string
+	CacheInvalidated      func(...interface{}) string
+	StatusError           func(...interface{}) string
 	reset                 = color.New(color.Reset).Sprint("")
 )
 
@@ -59,12 +65,18 @@ func init() {
 
 	Selected = wrapper(color.New(color.ReverseVideo, color.Bold).SprintFunc())
 	Header = wrapper(color.New(color.Bold).SprintFunc())
+	Faint = wrapper(color.New(color.Faint).SprintFunc())
 	StatusSelected = wrapper(color.New(color.BgMagenta, color.FgWhite).SprintFunc())
 	StatusNormal = wrapper(color.New(color.ReverseVideo).SprintFunc())
 	StatusControlSelected = wrapper(color.New(color.BgMagenta, color.FgWhite, color.Bold).SprintFunc())
 	StatusControlNormal = wrapper(color.New(color.ReverseVideo, color.Bold).SprintFunc())
 	CompareTop = wrapper(color.New(color.BgMagenta).SprintFunc())
 	CompareBottom = wrapper(color.New(color.BgGreen).SprintFunc())
+	Wasted = wrapper(color.New(color.FgYellow).SprintFunc())
+	MostWasted = wrapper(color.New(color.FgRed, color.Bold).SprintFunc())
+	Rebuilt = wrapper(color.New(color.FgCyan, color.Bold).SprintFunc())
+	CacheInvalidated = wrapper(color.New(color.FgRed, color.Bold).SprintFunc())
+	StatusError = wrapper(color.New(color.BgRed, color.FgWhite).SprintFunc())
 }
 
 func RenderNoHeader(width int, selected bool) string {
@@ -24,10 +24,12 @@ func (c *Config) Setup() error {
 }
 
 type Bindings struct {
-	Global     GlobalBindings     `yaml:",inline" mapstructure:",squash"`
-	Navigation NavigationBindings `yaml:",inline" mapstructure:",squash"`
-	Layer      LayerBindings      `yaml:",inline" mapstructure:",squash"`
-	Filetree   FiletreeBindings   `yaml:",inline" mapstructure:",squash"`
+	Global       GlobalBindings       `yaml:",inline" mapstructure:",squash"`
+	Navigation   NavigationBindings   `yaml:",inline" mapstructure:",squash"`
+	Layer        LayerBindings        `yaml:",inline" mapstructure:",squash"`
+	Filetree     FiletreeBindings     `yaml:",inline" mapstructure:",squash"`
+	ImageDetails ImageDetailsBindings `yaml:",inline" mapstructure:",squash"`
+	Largest      LargestBindings      `yaml:",inline" mapstructure:",squash"`
 }
 
 type GlobalBindings struct {
@@ -35,6 +37,8 @@ type GlobalBindings struct {
 	ToggleView       Config `yaml:"toggle-view" mapstructure:"toggle-view"`
 	FilterFiles      Config `yaml:"filter-files" mapstructure:"filter-files"`
 	CloseFilterFiles Config `yaml:"close-filter-files" mapstructure:"close-filter-files"`
+	SearchLayers     Config `yaml:"search-layers" mapstructure:"search-layers"`
+	ToggleLargest    Config `yaml:"toggle-largest" mapstructure:"toggle-largest"`
 }
 
 type NavigationBindings struct {
@@ -49,6 +53,7 @@ type NavigationBindings struct {
 type LayerBindings struct {
 	CompareAll   Config `yaml:"compare-all" mapstructure:"compare-all"`
 	CompareLayer Config `yaml:"compare-layer" mapstructure:"compare-layer"`
+	ToggleSort   Config `yaml:"toggle-layer-sort-order" mapstructure:"toggle-layer-sort-order"`
 }
 
 type FiletreeBindings struct {
@@ -62,6 +67,18 @@ type FiletreeBindings struct {
 	ToggleSortOrder       Config `yaml:"toggle-sort-order" mapstructure:"toggle-sort-order"`
 	ToggleWrapTree        Config `yaml:"toggle-wrap-tree" mapstructure:"toggle-wrap-tree"`
 	ExtractFile           Config `yaml:"extract-file" mapstructure:"extract-file"`
+	TogglePathHistory     Config `yaml:"toggle-path-history" mapstructure:"toggle-path-history"`
+	CyclePathLayers       Config `yaml:"cycle-path-layers" mapstructure:"cycle-path-layers"`
+	ToggleTreemap         Config `yaml:"toggle-treemap" mapstructure:"toggle-treemap"`
+}
+
+type ImageDetailsBindings struct {
+	ToggleImageConfig Config `yaml:"toggle-image-config" mapstructure:"toggle-image-config"`
+	GotoFile          Config `yaml:"goto-file" mapstructure:"goto-file"`
+}
+
+type LargestBindings struct {
+	ToggleScope Config `yaml:"toggle-largest-scope" mapstructure:"toggle-largest-scope"`
 }
 
 func DefaultBindings() Bindings {
@@ -71,6 +88,8 @@ func DefaultBindings() Bindings {
 			ToggleView:       Config{Input: "tab"},
 			FilterFiles:      Config{Input: "ctrl+f, ctrl+slash"},
 			CloseFilterFiles: Config{Input: "esc"},
+			SearchLayers:     Config{Input: "ctrl+g"},
+			ToggleLargest:    Config{Input: "ctrl+k"},
 		},
 		Navigation: NavigationBindings{
 			Up:       Config{Input: "up,k"},
@@ -83,6 +102,7 @@ func DefaultBindings() Bindings {
 		Layer: LayerBindings{
 			CompareAll:   Config{Input: "ctrl+a"},
 			CompareLayer: Config{Input: "ctrl+l"},
+			ToggleSort:   Config{Input: "ctrl+o"},
 		},
 		Filetree: FiletreeBindings{
 			ToggleCollapseDir:     Config{Input: "space"},
@@ -95,6 +115,16 @@ func DefaultBindings() Bindings {
 			ToggleWrapTree:        Config{Input: "ctrl+p"},
 			ToggleSortOrder:       Config{Input: "ctrl+o"},
 			ExtractFile:           Config{Input: "ctrl+e"},
+			TogglePathHistory:     Config{Input: "ctrl+t"},
+			CyclePathLayers:       Config{Input: "ctrl+n"},
+			ToggleTreemap:         Config{Input: "ctrl+x"},
+		},
+		ImageDetails: ImageDetailsBindings{
+			ToggleImageConfig: Config{Input: "space"},
+			GotoFile:          Config{Input: "enter"},
+		},
+		Largest: LargestBindings{
+			ToggleScope: Config{Input: "space"},
 		},
 	}
 }
@@ -152,10 +152,11 @@ func (lm *Manager) layoutFooters(g *gocui.Gui, area Area, footerHeights []int) e
 			element := elements[idx]
 			height := footerHeights[idx]
 			var topY, bottomY, bottomPadding int
-			for oIdx := 0; oIdx <= idx; oIdx++ {
+			for oIdx := 0; oIdx < idx; oIdx++ {
 				bottomPadding += footerHeights[oIdx]
 			}
-			topY = area.maxY - bottomPadding - height
+			// note: -1 since the footer must start on the (invisible) border of the element above it
+			topY = area.maxY - bottomPadding - height - 1
 			// +1 for border
 			bottomY = topY + height + 1
 
@@ -2,6 +2,7 @@ package view
 
 import (
 	"errors"
+	"unicode/utf8"
 
 	"github.com/awesome-gocui/gocui"
 )
@@ -36,3 +37,28 @@ func CursorStep(g *gocui.Gui, v *gocui.View, step int) error {
 	}
 	return nil
 }
+
+// CursorBufferLine returns the index of the buffer line under the cursor, accounting for the origin and for any lines
+// wrapped over several rows of the pane. Returns -1 if the cursor is not on a buffer line.
+func CursorBufferLine(v *gocui.View) int {
+	_, cy := v.Cursor()
+	_, oy := v.Origin()
+	row := oy + cy
+	if !v.Wrap {
+		return row
+	}
+
+	width, _ := v.Size()
+	var current int
+	for idx, line := range v.BufferLines() {
+		rows := 1
+		if length := utf8.RuneCountInString(line); width > 0 && length > width {
+			rows = (length + width - 1) / width
+		}
+		if row < current+rows {
+			return idx
+		}
+		current += rows
+	}
+	return -1
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/viewmodel"
 	"github.com/wagoodman/dive/internal/log"
 	"github.com/wagoodman/dive/internal/utils"
-	"regexp"
 
 	"github.com/awesome-gocui/gocui"
 	"github.com/wagoodman/dive/dive/filetree"
@@ -19,6 +18,8 @@ type ViewOptionChangeListener func() error
 
 type ViewExtractListener func(string) error
 
+type ViewPathListener func(string) error
+
 // FileTree holds the UI objects and data models for populating the right pane. Specifically, the pane that
 // shows selected layer or aggregate file ASCII tree.
 type FileTree struct {
@@ -31,9 +32,13 @@ type FileTree struct {
 	kb     key.Bindings
 	logger logger.Logger
 
-	filterRegex         *regexp.Regexp
+	filter              *filetree.Query
 	listeners           []ViewOptionChangeListener
 	extractListeners    []ViewExtractListener
+	historyListeners    []ViewPathListener
+	selectionListeners  []ViewPathListener
+	cycleListeners      []ViewPathListener
+	isHistoryVisible    func() bool
 	helpKeys            []*key.Binding
 	requestedWidthRatio float64
 }
@@ -72,12 +77,28 @@ func (v *FileTree) AddViewExtractListener(listener ...ViewExtractListener) {
 	v.extractListeners = append(v.extractListeners, listener...)
 }
 
+// AddViewPathHistoryListener registers listeners for when the user toggles the history of the selected path.
+func (v *FileTree) AddViewPathHistoryListener(isVisible func() bool, listener ...ViewPathListener) {
+	v.isHistoryVisible = isVisible
+	v.historyListeners = append(v.historyListeners, listener...)
+}
+
+// AddViewSelectionListener registers listeners for when the cursor moves to another path.
+func (v *FileTree) AddViewSelectionListener(listener ...ViewPathListener) {
+	v.selectionListeners = append(v.selectionListeners, listener...)
+}
+
+// AddViewCycleLayersListener registers listeners for when the user asks for the next layer that touches the selected path.
+func (v *FileTree) AddViewCycleLayersListener(listener ...ViewPathListener) {
+	v.cycleListeners = append(v.cycleListeners, listener...)
+}
+
 func (v *FileTree) SetTitle(title string) {
 	v.title = title
 }
 
-func (v *FileTree) SetFilterRegex(filterRegex *regexp.Regexp) {
-	v.filterRegex = filterRegex
+func (v *FileTree) SetFilter(filter *filetree.Query) {
+	v.filter = filter
 }
 
 func (v *FileTree) Name() string {
@@ -120,6 +141,23 @@ func (v *FileTree) Setup(view, header *gocui.View) error {
 			OnAction: v.extractFile,
 			Display:  "Extract File",
 		},
+		{
+			Config:     v.kb.Filetree.TogglePathHistory,
+			OnAction:   v.togglePathHistory,
+			IsSelected: func() bool { return v.isHistoryVisible != nil && v.isHistoryVisible() },
+			Display:    "Path history",
+		},
+		{
+			Config:   v.kb.Filetree.CyclePathLayers,
+			OnAction: v.cyclePathLayers,
+			Display:  "Next layer",
+		},
+		{
+			Config:     v.kb.Filetree.ToggleTreemap,
+			OnAction:   v.toggleTreemap,
+			IsSelected: func() bool { return v.vm.ShowTreemap },
+			Display:    "Treemap",
+		},
 		{
 			Config:     v.kb.Filetree.ToggleAddedFiles,
 			OnAction:   func() error { return v.toggleShowDiffType(filetree.Added) },
@@ -228,7 +266,8 @@ func (v *FileTree) SetTree(bottomTreeStart, bottomTreeStop, topTreeStart, topTre
 // this range into the view buffer. This is much faster when tree sizes are large.
 func (v *FileTree) CursorDown() error {
 	if v.vm.CursorDown() {
-		return v.Render()
+		_ = v.Render()
+		return v.notifySelectionListeners()
 	}
 	return nil
 }
@@ -239,29 +278,32 @@ func (v *FileTree) CursorDown() error {
 // this range into the view buffer. This is much faster when tree sizes are large.
 func (v *FileTree) CursorUp() error {
 	if v.vm.CursorUp() {
-		return v.Render()
+		_ = v.Render()
+		return v.notifySelectionListeners()
 	}
 	return nil
 }
 
 // CursorLeft moves the cursor up until we reach the Parent Node or top of the tree
 func (v *FileTree) CursorLeft() error {
-	err := v.vm.CursorLeft(v.filterRegex)
+	err := v.vm.CursorLeft()
 	if err != nil {
 		return err
 	}
 	_ = v.Update()
-	return v.Render()
+	_ = v.Render()
+	return v.notifySelectionListeners()
 }
 
 // CursorRight descends into directory expanding it if needed
 func (v *FileTree) CursorRight() error {
-	err := v.vm.CursorRight(v.filterRegex)
+	err := v.vm.CursorRight()
 	if err != nil {
 		return err
 	}
 	_ = v.Update()
-	return v.Render()
+	_ = v.Render()
+	return v.notifySelectionListeners()
 }
 
 // PageDown moves to next page putting the cursor on top
@@ -270,7 +312,8 @@ func (v *FileTree) PageDown() error {
 	if err != nil {
 		return err
 	}
-	return v.Render()
+	_ = v.Render()
+	return v.notifySelectionListeners()
 }
 
 // PageUp moves to previous page putting the cursor on top
@@ -279,7 +322,8 @@ func (v *FileTree) PageUp() error {
 	if err != nil {
 		return err
 	}
-	return v.Render()
+	_ = v.Render()
+	return v.notifySelectionListeners()
 }
 
 // getAbsPositionNode determines the selected screen cursor's location in the file tree, returning the selected FileNode.
@@ -289,7 +333,7 @@ func (v *FileTree) PageUp() error {
 
 // ToggleCollapse will collapse/expand the selected FileNode.
 func (v *FileTree) toggleCollapse() error {
-	err := v.vm.ToggleCollapse(v.filterRegex)
+	err := v.vm.ToggleCollapse()
 	if err != nil {
 		return err
 	}
@@ -321,7 +365,7 @@ func (v *FileTree) toggleSortOrder() error {
 }
 
 func (v *FileTree) extractFile() error {
-	node := v.vm.CurrentNode(v.filterRegex)
+	node := v.vm.CurrentNode()
 	for _, listener := range v.extractListeners {
 		err := listener(node.Path())
 		if err != nil {
@@ -332,6 +376,88 @@ func (v *FileTree) extractFile() error {
 	return nil
 }
 
+// togglePathHistory shows/hides the history of the selected path across all layers.
+func (v *FileTree) togglePathHistory() error {
+	node := v.vm.CurrentNode()
+	if node == nil {
+		return nil
+	}
+	for _, listener := range v.historyListeners {
+		err := listener(node.Path())
+		if err != nil {
+			return err
+		}
+	}
+	return v.notifyOnViewOptionChangeListeners()
+}
+
+// cyclePathLayers asks for the next layer that touches the selected path to be shown.
+func (v *FileTree) cyclePathLayers() error {
+	node := v.vm.CurrentNode()
+	if node == nil {
+		return nil
+	}
+	for _, listener := range v.cycleListeners {
+		err := listener(node.Path())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelectPath expands the tree down to the given path and moves the cursor onto it. Returns false if the path is not
+// present in the current tree.
+func (v *FileTree) SelectPath(p string) (bool, error) {
+	found, err := v.vm.SelectPath(p)
+	if err != nil || !found {
+		return found, err
+	}
+	if err := v.Update(); err != nil {
+		return true, err
+	}
+	if err := v.Render(); err != nil {
+		return true, err
+	}
+	return true, v.notifySelectionListeners()
+}
+
+// Restore carries the display options, filter and collapsed directories of another file tree pane (e.g. one for a
+// previous build of the image) over to this one, selecting the same path when it is still present.
+func (v *FileTree) Restore(other *FileTree) error {
+	v.filter = other.filter
+	if err := v.vm.Restore(other.vm); err != nil {
+		return err
+	}
+	if err := v.Update(); err != nil {
+		return err
+	}
+
+	if node := other.vm.CurrentNode(); node != nil {
+		if _, err := v.SelectPath(node.Path()); err != nil {
+			return err
+		}
+	}
+	return v.Render()
+}
+
+func (v *FileTree) notifySelectionListeners() error {
+	if len(v.selectionListeners) == 0 {
+		return nil
+	}
+	node := v.vm.CurrentNode()
+	if node == nil {
+		return nil
+	}
+	for _, listener := range v.selectionListeners {
+		err := listener(node.Path())
+		if err != nil {
+			return fmt.Errorf("notifySelectionListeners error: %w", err)
+		}
+	}
+	return nil
+}
+
 func (v *FileTree) toggleWrapTree() error {
 	v.view.Wrap = !v.view.Wrap
 
@@ -358,6 +484,19 @@ func (v *FileTree) notifyOnViewOptionChangeListeners() error {
 	return nil
 }
 
+// toggleTreemap switches between the tree and a treemap of the directory holding the selected path.
+func (v *FileTree) toggleTreemap() error {
+	v.vm.ToggleTreemap()
+
+	err := v.Render()
+	if err != nil {
+		return err
+	}
+
+	// we need to render the changes to the status pane as well (not just this controller/view)
+	return v.notifyOnViewOptionChangeListeners()
+}
+
 // ToggleAttributes will show/hide file attributes
 func (v *FileTree) toggleAttributes() error {
 	err := v.vm.ToggleAttributes()
@@ -415,7 +554,7 @@ func (v *FileTree) Update() error {
 		width, height = v.gui.Size()
 	}
 	// height should account for the header
-	return v.vm.Update(v.filterRegex, width, height-1)
+	return v.vm.Update(v.filter, width, height-1)
 }
 
 // Render flushes the state objects (file tree) to the pane.
@@ -430,8 +569,8 @@ func (v *FileTree) Render() error {
 		v.header.Clear()
 		width, _ := g.Size()
 		headerStr := format.RenderHeader(title, width, isSelected)
-		if v.vm.ShowAttributes {
-			headerStr += fmt.Sprintf(filetree.AttributeFormat+" %s", "P", "ermission", "UID:GID", "Size", "Filetree")
+		if v.vm.ShowAttributes && !v.vm.ShowTreemap {
+			headerStr += fmt.Sprintf(filetree.AttributeFormat+" %s", "P", "ermission", "Links", "UID:GID", "Size", "Filetree")
 		}
 		_, _ = fmt.Fprintln(v.header, headerStr)
 
@@ -14,7 +14,7 @@ import (
 type FilterEditListener func(string) error
 
 // Filter holds the UI objects and data models for populating the bottom row. Specifically the pane that
-// allows the user to filter the file tree by path.
+// allows the user to filter the file tree (see filetree.ParseQuery for the query syntax).
 type Filter struct {
 	gui    *gocui.Gui
 	view   *gocui.View
@@ -25,6 +25,7 @@ type Filter struct {
 	maxLength       int
 	hidden          bool
 	requestedHeight int
+	err             error
 
 	filterEditListeners []FilterEditListener
 }
@@ -38,7 +39,7 @@ func newFilterView(gui *gocui.Gui) *Filter {
 
 	// populate main fields
 	c.gui = gui
-	c.labelStr = "Path Filter: "
+	c.labelStr = "Filter: "
 	c.hidden = true
 
 	c.requestedHeight = 1
@@ -138,6 +139,11 @@ func (v *Filter) notifyFilterEditListeners() {
 	}
 }
 
+// SetError shows why the current filter could not be applied (or clears the reason when nil).
+func (v *Filter) SetError(err error) {
+	v.err = err
+}
+
 // Update refreshes the state objects for future rendering (currently does nothing).
 func (v *Filter) Update() error {
 	return nil
@@ -156,6 +162,9 @@ func (v *Filter) Render() error {
 
 // KeyHelp indicates all the possible actions a user can take while the current pane is selected.
 func (v *Filter) KeyHelp() string {
+	if v.err != nil {
+		return format.StatusControlNormal("▏Invalid filter: " + v.err.Error() + " ")
+	}
 	return format.StatusControlNormal("▏Type to filter the file tree ")
 }
 
@@ -6,14 +6,18 @@ import (
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/key"
 	"github.com/wagoodman/dive/internal/log"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/awesome-gocui/gocui"
 	"github.com/dustin/go-humanize"
 	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
 )
 
+type ViewGotoListener func(string) error
+
 type ImageDetails struct {
 	gui    *gocui.Gui
 	body   *gocui.View
@@ -22,9 +26,25 @@ type ImageDetails struct {
 
 	imageName      string
 	imageSize      uint64
+	compressedSize uint64
+	layers         []*image.Layer
 	efficiency     float64
 	inefficiencies filetree.EfficiencySlice
+	duplicates     filetree.DuplicateSlice
+	metadata       image.Metadata
+	attestations   []image.Attestation
 	kb             key.Bindings
+
+	showConfig    bool
+	helpKeys      []*key.Binding
+	paths         map[int]string
+	gotoListeners []ViewGotoListener
+}
+
+// AddViewGotoListener registers listeners for when the user selects a path (from the inefficient or duplicate files
+// list) to investigate in the file tree.
+func (v *ImageDetails) AddViewGotoListener(listener ...ViewGotoListener) {
+	v.gotoListeners = append(v.gotoListeners, listener...)
 }
 
 func (v *ImageDetails) Name() string {
@@ -48,6 +68,17 @@ func (v *ImageDetails) Setup(body, header *gocui.View) error {
 	v.header.Frame = false
 
 	var infos = []key.BindingInfo{
+		{
+			Config:     v.kb.ImageDetails.ToggleImageConfig,
+			OnAction:   v.toggleConfig,
+			IsSelected: func() bool { return v.showConfig },
+			Display:    "Image config",
+		},
+		{
+			Config:   v.kb.ImageDetails.GotoFile,
+			OnAction: v.gotoFile,
+			Display:  "Go to file",
+		},
 		{
 			Config:   v.kb.Navigation.Down,
 			Modifier: gocui.ModNone,
@@ -68,33 +99,113 @@ func (v *ImageDetails) Setup(body, header *gocui.View) error {
 		},
 	}
 
-	_, err := key.GenerateBindings(v.gui, v.Name(), infos)
+	helpKeys, err := key.GenerateBindings(v.gui, v.Name(), infos)
 	if err != nil {
 		return err
 	}
+	v.helpKeys = helpKeys
 	return nil
 }
 
+// gotoFile notifies listeners of the path in the inefficient (or duplicate) files list under the cursor.
+func (v *ImageDetails) gotoFile() error {
+	p, ok := v.paths[CursorBufferLine(v.body)]
+	if !ok {
+		return nil
+	}
+	for _, listener := range v.gotoListeners {
+		if err := listener(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *ImageDetails) toggleConfig() error {
+	v.showConfig = !v.showConfig
+	return v.Render()
+}
+
 // Render flushes the state objects to the screen. The details pane reports:
-// 1. the image efficiency score
-// 2. the estimated wasted image space
-// 3. a list of inefficient file allocations
+// 1. the image size (uncompressed and compressed)
+// 2. the image efficiency score
+// 3. the estimated wasted image space
+// 4. the image config (summarized unless expanded)
+// 5. the attestations shipped with the image (provenance materials and SBOM package counts)
+// 6. a list of inefficient file allocations
+// 7. a list of identical file content found at different paths
 func (v *ImageDetails) Render() error {
 	analysisTemplate := "%5s  %12s  %-s\n"
 	inefficiencyReport := fmt.Sprintf(format.Header(analysisTemplate), "Count", "Total Space", "Path")
 
 	var wastedSpace int64
+	inefficientPaths := make([]string, 0, len(v.inefficiencies))
 	for idx := 0; idx < len(v.inefficiencies); idx++ {
 		data := v.inefficiencies[len(v.inefficiencies)-1-idx]
 		wastedSpace += data.CumulativeSize
+		inefficientPaths = append(inefficientPaths, data.Path)
 
 		inefficiencyReport += fmt.Sprintf(analysisTemplate, strconv.Itoa(len(data.Nodes)), humanize.Bytes(uint64(data.CumulativeSize)), data.Path)
 	}
 
+	duplicateTemplate := "%5s  %12s  %-s\n"
+	duplicateReport := fmt.Sprintf(format.Header(duplicateTemplate), "Count", "Total Space", "Duplicate Paths")
+
+	var duplicateSpace int64
+	duplicatePaths := make([]string, 0, len(v.duplicates))
+	for idx := 0; idx < len(v.duplicates); idx++ {
+		data := v.duplicates[len(v.duplicates)-1-idx]
+		duplicateSpace += data.WastedSize
+		duplicatePaths = append(duplicatePaths, data.Paths...)
+
+		for pathIdx, path := range data.Paths {
+			if pathIdx == 0 {
+				duplicateReport += fmt.Sprintf(duplicateTemplate, strconv.Itoa(len(data.Paths)), humanize.Bytes(uint64(data.WastedSize)), path)
+				continue
+			}
+			duplicateReport += fmt.Sprintf(duplicateTemplate, "", "", path)
+		}
+	}
+
 	imageNameStr := fmt.Sprintf("%s %s", format.Header("Image name:"), v.imageName)
 	imageSizeStr := fmt.Sprintf("%s %s", format.Header("Total Image size:"), humanize.Bytes(v.imageSize))
+	compressedSizeStr := fmt.Sprintf("%s %s", format.Header("Total compressed size:"), image.CompressedBytes(v.compressedSize))
+	if compressions := v.compressions(); len(compressions) > 0 {
+		compressedSizeStr += fmt.Sprintf(" (%s)", strings.Join(compressions, ", "))
+	}
 	efficiencyStr := fmt.Sprintf("%s %d %%", format.Header("Image efficiency score:"), int(100.0*v.efficiency))
 	wastedSpaceStr := fmt.Sprintf("%s %s", format.Header("Potential wasted space:"), humanize.Bytes(uint64(wastedSpace)))
+	duplicateSpaceStr := fmt.Sprintf("%s %s", format.Header("Duplicate content:"), humanize.Bytes(uint64(duplicateSpace)))
+	configReport := v.renderConfig()
+	attestationReport := v.renderAttestations()
+
+	var lines = []string{
+		imageNameStr,
+		imageSizeStr,
+		compressedSizeStr,
+		wastedSpaceStr,
+		duplicateSpaceStr,
+		efficiencyStr,
+		" ", // to avoid an empty line so CursorDown can work as expected
+		configReport,
+		" ",
+		attestationReport,
+		" ",
+	}
+
+	// keep track of which buffer lines list a path (the first row of each report is the column header)
+	v.paths = make(map[int]string)
+	addSection := func(report string, paths []string) {
+		start := strings.Count(strings.Join(lines, "\n"), "\n") + 1
+		for idx, p := range paths {
+			v.paths[start+1+idx] = p
+		}
+		lines = append(lines, report)
+	}
+	addSection(inefficiencyReport, inefficientPaths)
+	if len(v.duplicates) > 0 {
+		addSection(duplicateReport, duplicatePaths)
+	}
 
 	v.gui.Update(func(g *gocui.Gui) error {
 		width, _ := v.body.Size()
@@ -107,26 +218,137 @@ func (v *ImageDetails) Render() error {
 			log.WithFields("error", err).Debug("unable to write to buffer")
 		}
 
-		var lines = []string{
-			imageNameStr,
-			imageSizeStr,
-			wastedSpaceStr,
-			efficiencyStr,
-			" ", // to avoid an empty line so CursorDown can work as expected
-			inefficiencyReport,
-		}
+		// clearing the buffer resets the cursor, which should stay put when re-rendering
+		cx, cy := v.body.Cursor()
+		ox, oy := v.body.Origin()
 
 		v.body.Clear()
 		_, err = fmt.Fprintln(v.body, strings.Join(lines, "\n"))
 		if err != nil {
 			log.WithFields("error", err).Debug("unable to write to buffer")
 		}
+
+		_ = v.body.SetOrigin(ox, oy)
+		_ = v.body.SetCursor(cx, cy)
 		return err
 	})
 
 	return nil
 }
 
+// renderConfig summarizes the runtime configuration of the image, or shows all of it when the section is expanded.
+func (v *ImageDetails) renderConfig() string {
+	md := v.metadata
+
+	user := md.User
+	if user == "" {
+		user = "(root)"
+	}
+
+	if !v.showConfig {
+		summary := fmt.Sprintf("%s %s, user %s", format.Header("Image config:"), valueOrNone(md.Platform()), user)
+		if command := strings.Join(append(append([]string{}, md.Entrypoint...), md.Cmd...), " "); command != "" {
+			summary += fmt.Sprintf(", runs %q", command)
+		}
+		return summary
+	}
+
+	configTemplate := "  %-13s %s"
+	rows := []string{format.Header("Image config:")}
+	addRow := func(name string, values ...string) {
+		if len(values) == 0 {
+			rows = append(rows, fmt.Sprintf(configTemplate, name, "(none)"))
+			return
+		}
+		for idx, value := range values {
+			if idx > 0 {
+				name = ""
+			}
+			rows = append(rows, fmt.Sprintf(configTemplate, name, value))
+		}
+	}
+
+	addRow("Platform:", valueOrNone(md.Platform()))
+	addRow("Created:", valueOrNone(md.Created))
+	if md.Author != "" {
+		addRow("Author:", md.Author)
+	}
+	addRow("User:", user)
+	addRow("WorkingDir:", valueOrNone(md.WorkingDir))
+	addRow("Entrypoint:", md.Entrypoint...)
+	addRow("Cmd:", md.Cmd...)
+	addRow("Env:", md.Env...)
+	addRow("Ports:", md.ExposedPorts...)
+	addRow("Volumes:", md.Volumes...)
+
+	labelKeys := make([]string, 0, len(md.Labels))
+	for name := range md.Labels {
+		labelKeys = append(labelKeys, name)
+	}
+	sort.Strings(labelKeys)
+	labels := make([]string, 0, len(labelKeys))
+	for _, name := range labelKeys {
+		labels = append(labels, fmt.Sprintf("%s=%s", name, md.Labels[name]))
+	}
+	addRow("Labels:", labels...)
+
+	if md.StopSignal != "" {
+		addRow("StopSignal:", md.StopSignal)
+	}
+	if hc := md.Healthcheck; hc != nil {
+		addRow("Healthcheck:", strings.Join(hc.Test, " "), fmt.Sprintf("interval %s, timeout %s, retries %d", hc.Interval, hc.Timeout, hc.Retries))
+	} else {
+		addRow("Healthcheck:")
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// renderAttestations lists the attestations found alongside the image, including the build materials of provenance.
+func (v *ImageDetails) renderAttestations() string {
+	title := format.Header("Attestations:")
+	if len(v.attestations) == 0 {
+		return title + " (none)"
+	}
+
+	rows := []string{title}
+	for _, attestation := range v.attestations {
+		switch attestation.Kind {
+		case image.AttestationProvenance:
+			rows = append(rows, fmt.Sprintf("  provenance (%d materials)", len(attestation.Materials)))
+			for _, material := range attestation.Materials {
+				rows = append(rows, "    "+material.URI)
+			}
+		case image.AttestationSBOM:
+			rows = append(rows, fmt.Sprintf("  sbom (%s, %d packages)", attestation.SBOMFormat, attestation.PackageCount))
+		default:
+			rows = append(rows, fmt.Sprintf("  %s", valueOrNone(attestation.PredicateType)))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// compressions lists the distinct compression algorithms used across all layer blobs (in layer order).
+func (v *ImageDetails) compressions() []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, layer := range v.layers {
+		if layer.Compression == "" || seen[layer.Compression] {
+			continue
+		}
+		seen[layer.Compression] = true
+		result = append(result, layer.Compression)
+	}
+	return result
+}
+
 func (v *ImageDetails) OnLayoutChange() error {
 	if err := v.Update(); err != nil {
 		return err
@@ -169,9 +391,13 @@ func (v *ImageDetails) CursorDown() error {
 	return nil
 }
 
-// KeyHelp indicates all the possible actions a user can take while the current pane is selected (currently does nothing).
+// KeyHelp indicates all the possible actions a user can take while the current pane is selected.
 func (v *ImageDetails) KeyHelp() string {
-	return ""
+	var help string
+	for _, binding := range v.helpKeys {
+		help += binding.RenderKeyHelp()
+	}
+	return help
 }
 
 // Update refreshes the state objects for future rendering.
@@ -0,0 +1,331 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/anchore/go-logger"
+	"github.com/awesome-gocui/gocui"
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/key"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/internal/log"
+	"github.com/wagoodman/dive/internal/utils"
+)
+
+const largestFormat = "%10s  %s"
+
+// LargestGotoListener is notified with the path to investigate in the file tree, along with the layer to select (or
+// -1 when any layer that touches the path will do).
+type LargestGotoListener func(path string, layer int) error
+
+// Largest holds the UI objects and data models for populating the pane (above the status bar) that lists the largest
+// files and directories, either in the whole image or in the selected layer.
+type Largest struct {
+	gui    *gocui.Gui
+	body   *gocui.View
+	header *gocui.View
+	logger logger.Logger
+	kb     key.Bindings
+
+	image      filetree.LargestPaths
+	layers     []filetree.LargestPaths
+	layer      int
+	layerScope bool
+	hidden     bool
+
+	helpKeys      []*key.Binding
+	paths         map[int]string
+	gotoListeners []LargestGotoListener
+}
+
+// newLargestView creates a new view object attached the global [gocui] screen object.
+func newLargestView(gui *gocui.Gui, image filetree.LargestPaths, layers []filetree.LargestPaths, kb key.Bindings) *Largest {
+	return &Largest{
+		gui:    gui,
+		logger: log.Nested("ui", "largest"),
+		kb:     kb,
+		image:  image,
+		layers: layers,
+		hidden: true,
+	}
+}
+
+// AddLargestGotoListener registers listeners for when the user selects a path to investigate in the file tree.
+func (v *Largest) AddLargestGotoListener(listener ...LargestGotoListener) {
+	v.gotoListeners = append(v.gotoListeners, listener...)
+}
+
+func (v *Largest) Name() string {
+	return "largest"
+}
+
+// Setup initializes the UI concerns within the context of a global [gocui] view object.
+func (v *Largest) Setup(body, header *gocui.View) error {
+	v.logger.Trace("setup()")
+
+	v.body = body
+	v.body.Editable = false
+	v.body.Wrap = false
+	v.body.Highlight = true
+	v.body.Frame = false
+
+	v.header = header
+	v.header.Editable = false
+	v.header.Wrap = false
+	v.header.Frame = false
+
+	var infos = []key.BindingInfo{
+		{
+			Config:     v.kb.Largest.ToggleScope,
+			OnAction:   v.toggleScope,
+			IsSelected: func() bool { return v.layerScope },
+			Display:    "Selected layer only",
+		},
+		{
+			Config:   v.kb.ImageDetails.GotoFile,
+			OnAction: v.gotoFile,
+			Display:  "Go to file",
+		},
+		{
+			Config:   v.kb.Navigation.Down,
+			Modifier: gocui.ModNone,
+			OnAction: v.CursorDown,
+		},
+		{
+			Config:   v.kb.Navigation.Up,
+			Modifier: gocui.ModNone,
+			OnAction: v.CursorUp,
+		},
+		{
+			Config:   v.kb.Navigation.PageUp,
+			OnAction: v.PageUp,
+		},
+		{
+			Config:   v.kb.Navigation.PageDown,
+			OnAction: v.PageDown,
+		},
+	}
+
+	helpKeys, err := key.GenerateBindings(v.gui, v.Name(), infos)
+	if err != nil {
+		return err
+	}
+	v.helpKeys = helpKeys
+
+	return v.Render()
+}
+
+// ToggleVisible shows (and focuses) or hides the largest paths pane.
+func (v *Largest) ToggleVisible() error {
+	v.hidden = !v.hidden
+	if v.hidden {
+		return nil
+	}
+	return v.Focus()
+}
+
+// Focus selects the largest paths pane, so that the user can pick a path to investigate.
+func (v *Largest) Focus() error {
+	_, err := v.gui.SetCurrentView(v.Name())
+	if err != nil {
+		return fmt.Errorf("unable to focus largest paths view: %w", err)
+	}
+	return nil
+}
+
+// IsVisible indicates if the largest paths pane is currently shown.
+func (v *Largest) IsVisible() bool {
+	if v == nil {
+		return false
+	}
+	return !v.hidden
+}
+
+// SetLayer selects the layer to rank paths for (when only showing the selected layer).
+func (v *Largest) SetLayer(layer int) error {
+	if v.layer == layer {
+		return nil
+	}
+	v.layer = layer
+	if !v.layerScope {
+		return nil
+	}
+	return v.resetCursor()
+}
+
+func (v *Largest) toggleScope() error {
+	v.layerScope = !v.layerScope
+	if err := v.resetCursor(); err != nil {
+		return err
+	}
+	return v.Render()
+}
+
+func (v *Largest) resetCursor() error {
+	if v.body == nil {
+		return nil
+	}
+	if err := v.body.SetOrigin(0, 0); err != nil {
+		return err
+	}
+	return v.body.SetCursor(0, 0)
+}
+
+// gotoFile notifies listeners of the path under the cursor.
+func (v *Largest) gotoFile() error {
+	p, ok := v.paths[CursorBufferLine(v.body)]
+	if !ok {
+		return nil
+	}
+	layer := -1
+	if v.layerScope {
+		layer = v.layer
+	}
+	for _, listener := range v.gotoListeners {
+		if err := listener(p, layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Largest) current() filetree.LargestPaths {
+	if !v.layerScope {
+		return v.image
+	}
+	if v.layer < len(v.layers) {
+		return v.layers[v.layer]
+	}
+	return filetree.LargestPaths{}
+}
+
+// Update refreshes the state objects for future rendering (currently does nothing, paths are ranked during analysis).
+func (v *Largest) Update() error {
+	return nil
+}
+
+// Render flushes the state objects to the screen. The pane lists the largest files followed by the largest directories.
+func (v *Largest) Render() error {
+	v.logger.Trace("render()")
+
+	largest := v.current()
+	title := "Largest Paths: whole image"
+	if v.layerScope {
+		title = fmt.Sprintf("Largest Paths: layer %d", v.layer)
+	}
+
+	var lines []string
+	v.paths = make(map[int]string)
+	for _, section := range []struct {
+		name    string
+		entries []filetree.LargestPath
+	}{
+		{name: "Files", entries: largest.Files},
+		{name: "Directories", entries: largest.Directories},
+	} {
+		lines = append(lines, format.Header(fmt.Sprintf(largestFormat, "Size", section.name)))
+		if len(section.entries) == 0 {
+			lines = append(lines, format.Faint(fmt.Sprintf(largestFormat, "", "(none)")))
+			continue
+		}
+		for _, entry := range section.entries {
+			v.paths[len(lines)] = entry.Path
+			lines = append(lines, fmt.Sprintf(largestFormat, humanize.Bytes(uint64(entry.Size)), entry.Path))
+		}
+	}
+
+	v.gui.Update(func(g *gocui.Gui) error {
+		if v.header == nil || v.body == nil {
+			return nil
+		}
+		width, _ := g.Size()
+
+		v.header.Clear()
+		_, _ = fmt.Fprintln(v.header, format.RenderHeader(title, width, v.gui.CurrentView() == v.body))
+
+		v.body.Clear()
+		for _, line := range lines {
+			if _, err := fmt.Fprintln(v.body, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+func (v *Largest) PageUp() error {
+	_, height := v.body.Size()
+	if err := CursorStep(v.gui, v.body, -height); err != nil {
+		v.logger.WithFields("error", err).Debugf("couldn't move the cursor up by %d steps", height)
+	}
+	return nil
+}
+
+func (v *Largest) PageDown() error {
+	_, height := v.body.Size()
+	if err := CursorStep(v.gui, v.body, height); err != nil {
+		v.logger.WithFields("error", err).Debugf("couldn't move the cursor down by %d steps", height)
+	}
+	return nil
+}
+
+func (v *Largest) CursorUp() error {
+	if err := CursorUp(v.gui, v.body); err != nil {
+		v.logger.WithFields("error", err).Debug("couldn't move the cursor up")
+	}
+	return nil
+}
+
+func (v *Largest) CursorDown() error {
+	if err := CursorDown(v.gui, v.body); err != nil {
+		v.logger.WithFields("error", err).Debug("couldn't move the cursor down")
+	}
+	return nil
+}
+
+// KeyHelp indicates all the possible actions a user can take while the current pane is selected.
+func (v *Largest) KeyHelp() string {
+	var help string
+	for _, binding := range v.helpKeys {
+		help += binding.RenderKeyHelp()
+	}
+	return help
+}
+
+// OnLayoutChange is called whenever the screen dimensions are changed
+func (v *Largest) OnLayoutChange() error {
+	err := v.Update()
+	if err != nil {
+		return err
+	}
+	return v.Render()
+}
+
+func (v *Largest) Layout(g *gocui.Gui, minX, minY, maxX, maxY int) error {
+	v.logger.Tracef("layout(minX: %d, minY: %d, maxX: %d, maxY: %d)", minX, minY, maxX, maxY)
+
+	// title + ranked paths
+	header, headerErr := g.SetView(v.Name()+"header", minX, minY, maxX, min(minY+2, maxY), 0)
+	body, bodyErr := g.SetView(v.Name(), minX, minY+1, maxX, max(maxY, minY+1), 0)
+	if utils.IsNewView(bodyErr, headerErr) {
+		err := v.Setup(body, header)
+		if err != nil {
+			return fmt.Errorf("unable to setup largest paths controller: %w", err)
+		}
+	}
+	return nil
+}
+
+// RequestedSize fits all ranked paths (along with the title), but never more than a third of the screen.
+func (v *Largest) RequestedSize(available int) *int {
+	largest := v.current()
+	rows := 2 + max(len(largest.Files), 1) + max(len(largest.Directories), 1)
+	height := rows + 1
+	if limit := available / 3; height > limit {
+		height = max(limit, 3)
+	}
+	return &height
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/anchore/go-logger"
 	"github.com/awesome-gocui/gocui"
+	"github.com/dustin/go-humanize"
+	"github.com/lunixbochs/vtclean"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/key"
@@ -12,6 +14,9 @@ import (
 	"github.com/wagoodman/dive/internal/log"
 )
 
+// wasteFormat is the column of the layer pane holding the wasted bytes of each layer
+const wasteFormat = "%7s  "
+
 // Layer holds the UI objects and data models for populating the lower-left pane.
 // Specifically the pane that shows the image layers and layer selector.
 type Layer struct {
@@ -53,6 +58,9 @@ func newLayerView(gui *gocui.Gui, cfg v1.Config) (c *Layer, err error) {
 	}
 
 	c.vm = viewmodel.NewLayerSetState(cfg.Analysis.Layers, compareMode)
+	for _, stat := range cfg.Analysis.LayerStats {
+		c.vm.Wasted = append(c.vm.Wasted, stat.OverwrittenBytes)
+	}
 
 	return c, err
 }
@@ -117,6 +125,12 @@ func (v *Layer) Setup(body *gocui.View, header *gocui.View) error {
 			IsSelected: func() bool { return v.vm.CompareMode == viewmodel.CompareAllLayers },
 			Display:    "Show aggregated changes",
 		},
+		{
+			Config:     v.kb.Layer.ToggleSort,
+			OnAction:   v.toggleSortByWaste,
+			IsSelected: func() bool { return v.vm.SortByWaste },
+			Display:    "Sort by waste",
+		},
 		{
 			Config:   v.kb.Navigation.Down,
 			Modifier: gocui.ModNone,
@@ -163,66 +177,41 @@ func (v *Layer) IsVisible() bool {
 
 // PageDown moves to next page putting the cursor on top
 func (v *Layer) PageDown() error {
-	step := int(v.height()) + 1
-	targetLayerIndex := v.vm.LayerIndex + step
-
-	if targetLayerIndex > len(v.vm.Layers) {
-		step -= targetLayerIndex - (len(v.vm.Layers) - 1)
-	}
-
-	if step > 0 {
-		// err := CursorStep(v.gui, v.body, step)
-		err := error(nil)
-		if err == nil {
-			return v.SetCursor(v.vm.LayerIndex + step)
-		}
-	}
-	return nil
+	return v.moveCursor(int(v.height()) + 1)
 }
 
 // PageUp moves to previous page putting the cursor on top
 func (v *Layer) PageUp() error {
-	step := int(v.height()) + 1
-	targetLayerIndex := v.vm.LayerIndex - step
-
-	if targetLayerIndex < 0 {
-		step += targetLayerIndex
-	}
-
-	if step > 0 {
-		// err := CursorStep(v.gui, v.body, -step)
-		err := error(nil)
-		if err == nil {
-			return v.SetCursor(v.vm.LayerIndex - step)
-		}
-	}
-	return nil
+	return v.moveCursor(-(int(v.height()) + 1))
 }
 
-// CursorDown moves the cursor down in the layer pane (selecting a higher layer).
+// CursorDown moves the cursor down in the layer pane (selecting a higher layer, or a less wasteful layer when sorted
+// by waste).
 func (v *Layer) CursorDown() error {
-	if v.vm.LayerIndex < len(v.vm.Layers)-1 {
-		// err := CursorDown(v.gui, v.body)
-		err := error(nil)
-		if err == nil {
-			return v.SetCursor(v.vm.LayerIndex + 1)
-		}
-	}
-	return nil
+	return v.moveCursor(1)
 }
 
-// CursorUp moves the cursor up in the layer pane (selecting a lower layer).
+// CursorUp moves the cursor up in the layer pane (selecting a lower layer, or a more wasteful layer when sorted by
+// waste).
 func (v *Layer) CursorUp() error {
-	if v.vm.LayerIndex > 0 {
-		// err := CursorUp(v.gui, v.body)
-		err := error(nil)
-		if err == nil {
-			return v.SetCursor(v.vm.LayerIndex - 1)
-		}
+	return v.moveCursor(-1)
+}
+
+// moveCursor selects the layer listed the given number of rows away from the selected layer.
+func (v *Layer) moveCursor(rows int) error {
+	if target := v.vm.Step(rows); target != v.vm.LayerIndex {
+		return v.SetCursor(target)
 	}
 	return nil
 }
 
+// toggleSortByWaste switches between listing the layers in build order and listing the most wasteful layers first
+// (the selected layer is kept).
+func (v *Layer) toggleSortByWaste() error {
+	v.vm.SortByWaste = !v.vm.SortByWaste
+	return v.Render()
+}
+
 // SetOrigin updates the origin of the layer view pane.
 func (v *Layer) SetOrigin(x, y int) error {
 	if err := v.body.SetOrigin(x, y); err != nil {
@@ -242,6 +231,26 @@ func (v *Layer) SetCursor(layer int) error {
 	return v.Render()
 }
 
+// Restore carries the selection and display options of another layer pane (e.g. one for a previous build of the image)
+// over to this one, marking the layers that differ from the layers of the other pane as rebuilt.
+func (v *Layer) Restore(other *Layer) error {
+	v.vm.CompareMode = other.vm.CompareMode
+	v.vm.SortByWaste = other.vm.SortByWaste
+	v.vm.Rebuilt = viewmodel.RebuiltLayers(other.vm.Layers, v.vm.Layers)
+	return v.SetCursor(min(other.vm.LayerIndex, len(v.vm.Layers)-1))
+}
+
+// RebuiltCount returns the number of layers that differ from the previous build of the image.
+func (v *Layer) RebuiltCount() int {
+	var count int
+	for idx := range v.vm.Layers {
+		if v.vm.IsRebuilt(idx) {
+			count++
+		}
+	}
+	return count
+}
+
 // CurrentLayer returns the Layer object currently selected.
 func (v *Layer) CurrentLayer() *image.Layer {
 	return v.vm.Layers[v.vm.LayerIndex]
@@ -268,6 +277,39 @@ func (v *Layer) renderCompareBar(layerIdx int) string {
 	return result
 }
 
+// renderRebuiltMarker returns the (single column) marker shown between the compare bar and the given layer, which
+// indicates if the layer differs from the previous build of the image, or if it was reused from the build cache (when
+// known), highlighting the layer that invalidated the build cache.
+func (v *Layer) renderRebuiltMarker(layerIdx int) string {
+	if layerIdx == image.FirstCacheMiss(v.vm.Layers) {
+		return format.CacheInvalidated("•")
+	}
+	var buildCache image.BuildCache
+	if layerIdx < len(v.vm.Layers) {
+		buildCache = v.vm.Layers[layerIdx].BuildCache
+	}
+	if v.vm.IsRebuilt(layerIdx) || buildCache == image.BuildCacheMiss {
+		return format.Rebuilt("•")
+	}
+	if buildCache == image.BuildCacheHit {
+		return format.Faint("·")
+	}
+	return " "
+}
+
+// renderWaste returns the formatted wasted bytes of the given layer (the bytes that are whited out or overwritten by
+// a later layer), highlighting the most wasteful layer.
+func (v *Layer) renderWaste(layerIdx int) string {
+	if layerIdx >= len(v.vm.Wasted) || v.vm.Wasted[layerIdx] == 0 {
+		return fmt.Sprintf(wasteFormat, "")
+	}
+	result := fmt.Sprintf(wasteFormat, humanize.Bytes(uint64(v.vm.Wasted[layerIdx])))
+	if layerIdx == v.vm.MostWasted() {
+		return format.MostWasted(result)
+	}
+	return format.Wasted(result)
+}
+
 func (v *Layer) ConstrainLayout() {
 	if !v.constrainedRealEstate {
 		v.logger.Debug("constraining layout")
@@ -320,7 +362,7 @@ func (v *Layer) Render() error {
 			}
 		} else {
 			headerStr := format.RenderHeader(title, width, isSelected)
-			headerStr += fmt.Sprintf("Cmp"+image.LayerFormat, "Size", "Command")
+			headerStr += fmt.Sprintf("Cmp"+wasteFormat+image.LayerFormat, "Waste", "Size", "Wire", "Command")
 			_, err := fmt.Fprintln(v.header, headerStr)
 			if err != nil {
 				return err
@@ -329,31 +371,62 @@ func (v *Layer) Render() error {
 
 		// update contents
 		v.body.Clear()
-		for idx, layer := range v.vm.Layers {
+		var line, selectedLine int
+		for _, idx := range v.vm.Order() {
+			layer := v.vm.Layers[idx]
 			var layerStr string
 			if v.constrainedRealEstate {
 				layerStr = fmt.Sprintf("%-4d", layer.Index)
 			} else {
-				layerStr = layer.String()
+				layerStr = v.renderWaste(idx) + layer.String()
 			}
 
 			compareBar := v.renderCompareBar(idx)
+			marker := v.renderRebuiltMarker(idx)
+
+			// build steps that only changed metadata are shown (but cannot be selected) to match the build history
+			renderEmptyLayers := func(emptyLayers []image.EmptyLayer) error {
+				if v.constrainedRealEstate || v.vm.SortByWaste {
+					return nil
+				}
+				for _, emptyLayer := range emptyLayers {
+					if _, err := fmt.Fprintln(v.body, compareBar+" "+format.Faint(fmt.Sprintf(wasteFormat, "")+emptyLayer.String())); err != nil {
+						return err
+					}
+					line++
+				}
+				return nil
+			}
+
+			if err := renderEmptyLayers(layer.PrecedingEmptyLayers); err != nil {
+				return err
+			}
 
 			if idx == v.vm.LayerIndex {
-				_, err = fmt.Fprintln(v.body, compareBar+" "+format.Selected(layerStr))
+				selectedLine = line
+				_, err = fmt.Fprintln(v.body, compareBar+marker+format.Selected(vtclean.Clean(layerStr, false)))
 			} else {
-				_, err = fmt.Fprintln(v.body, compareBar+" "+layerStr)
+				_, err = fmt.Fprintln(v.body, compareBar+marker+layerStr)
 			}
+			line++
 
 			if err != nil {
 				return err
 			}
+
+			if err := renderEmptyLayers(layer.EmptyLayers); err != nil {
+				return err
+			}
 		}
 
 		// Adjust origin, if necessary
 		maxBodyDisplayHeight := int(v.height())
-		if v.vm.LayerIndex > maxBodyDisplayHeight {
-			if err := v.SetOrigin(0, v.vm.LayerIndex-maxBodyDisplayHeight); err != nil {
+		if selectedLine > maxBodyDisplayHeight {
+			if err := v.SetOrigin(0, selectedLine-maxBodyDisplayHeight); err != nil {
+				return err
+			}
+		} else if _, originY := v.body.Origin(); selectedLine < originY {
+			if err := v.SetOrigin(0, selectedLine); err != nil {
 				return err
 			}
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/key"
 	"github.com/wagoodman/dive/internal/log"
 	"strings"
+	"time"
 
 	"github.com/awesome-gocui/gocui"
 	"github.com/dustin/go-humanize"
@@ -18,6 +19,7 @@ type LayerDetails struct {
 	header       *gocui.View
 	body         *gocui.View
 	CurrentLayer *image.Layer
+	layers       []*image.Layer
 	kb           key.Bindings
 	logger       logger.Logger
 }
@@ -66,8 +68,10 @@ func (v *LayerDetails) Setup(body, header *gocui.View) error {
 // The details pane reports the currently selected layer's:
 // 1. tags
 // 2. ID
-// 3. digest
-// 4. command
+// 3. size (uncompressed and compressed)
+// 4. digest
+// 5. creation time, author and time since the previous layer
+// 6. command
 func (v *LayerDetails) Render() error {
 	v.gui.Update(func(g *gocui.Gui) error {
 		v.header.Clear()
@@ -92,7 +96,17 @@ func (v *LayerDetails) Render() error {
 			format.Header("Tags:   ") + tags,
 			format.Header("Id:     ") + v.CurrentLayer.Id,
 			format.Header("Size:   ") + humanize.Bytes(v.CurrentLayer.Size),
+			format.Header("Wire:   ") + compressedSize(v.CurrentLayer),
 			format.Header("Digest: ") + v.CurrentLayer.Digest,
+			format.Header("Created:") + " " + v.created(),
+			format.Header("Author: ") + valueOrNone(v.CurrentLayer.Author),
+		}...)
+
+		if v.CurrentLayer.BuildCache != image.BuildCacheUnknown {
+			lines = append(lines, format.Header("Cache:  ")+v.buildCache())
+		}
+
+		lines = append(lines, []string{
 			format.Header("Command:"),
 			v.CurrentLayer.Command,
 		}...)
@@ -106,6 +120,49 @@ func (v *LayerDetails) Render() error {
 	return nil
 }
 
+// created describes when the current layer was created, relative to the layer before it.
+func (v *LayerDetails) created() string {
+	if v.CurrentLayer.Created.IsZero() {
+		return "(unknown)"
+	}
+
+	result := v.CurrentLayer.Created.UTC().Format(time.RFC3339)
+
+	idx := v.CurrentLayer.Index
+	if idx <= 0 || idx >= len(v.layers) || v.layers[idx-1].Created.IsZero() {
+		return result
+	}
+
+	elapsed := v.CurrentLayer.Created.Sub(v.layers[idx-1].Created).Round(time.Second)
+	if elapsed < 0 {
+		return result
+	}
+	return fmt.Sprintf("%s (%s after the previous layer)", result, elapsed)
+}
+
+// buildCache describes if the current layer was reused from the build cache, noting the layer that invalidated the
+// cache for the rest of the build.
+func (v *LayerDetails) buildCache() string {
+	if v.CurrentLayer.BuildCache == image.BuildCacheHit {
+		return "hit (reused from the build cache)"
+	}
+	if v.CurrentLayer.Index == image.FirstCacheMiss(v.layers) {
+		return "miss (invalidated the build cache for all later steps)"
+	}
+	return "miss (rebuilt)"
+}
+
+// compressedSize describes the size of the layer blob along with how it is compressed.
+func compressedSize(layer *image.Layer) string {
+	switch {
+	case layer.Compression == "":
+		return image.CompressedBytes(layer.CompressedSize)
+	case layer.CompressedSize == 0:
+		return fmt.Sprintf("unknown (%s in the archive)", layer.Compression)
+	}
+	return fmt.Sprintf("%s (%s)", humanize.Bytes(layer.CompressedSize), layer.Compression)
+}
+
 func (v *LayerDetails) OnLayoutChange() error {
 	if err := v.Update(); err != nil {
 		return err
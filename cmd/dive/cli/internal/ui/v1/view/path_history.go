@@ -0,0 +1,187 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anchore/go-logger"
+	"github.com/awesome-gocui/gocui"
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
+	"github.com/wagoodman/dive/internal/utils"
+)
+
+const pathHistoryFormat = "%5s  %-10s %10s  %-10s %9s  %-16s  %s"
+
+// PathHistory holds the UI objects and data models for populating the pane (above the status bar) that shows every
+// layer that has touched the path selected in the file tree.
+type PathHistory struct {
+	gui    *gocui.Gui
+	view   *gocui.View
+	header *gocui.View
+	logger logger.Logger
+
+	layers  []*image.Layer
+	trees   []*filetree.FileTree
+	path    string
+	entries []filetree.HistoryEntry
+	hidden  bool
+}
+
+// newPathHistoryView creates a new view object attached the global [gocui] screen object.
+func newPathHistoryView(gui *gocui.Gui, layers []*image.Layer, trees []*filetree.FileTree) *PathHistory {
+	return &PathHistory{
+		gui:    gui,
+		logger: log.Nested("ui", "pathHistory"),
+		layers: layers,
+		trees:  trees,
+		hidden: true,
+	}
+}
+
+func (v *PathHistory) Name() string {
+	return "pathHistory"
+}
+
+// Setup initializes the UI concerns within the context of a global [gocui] view object.
+func (v *PathHistory) Setup(view, header *gocui.View) error {
+	v.logger.Trace("setup()")
+
+	v.view = view
+	v.view.Editable = false
+	v.view.Wrap = false
+	v.view.Frame = false
+
+	v.header = header
+	v.header.Editable = false
+	v.header.Wrap = false
+	v.header.Frame = false
+
+	return v.Render()
+}
+
+// SetPath selects the path to show the history for.
+func (v *PathHistory) SetPath(p string) {
+	v.path = p
+}
+
+// ToggleVisible shows/hides the path history pane.
+func (v *PathHistory) ToggleVisible() {
+	v.hidden = !v.hidden
+}
+
+// IsVisible indicates if the path history pane is currently shown.
+func (v *PathHistory) IsVisible() bool {
+	if v == nil {
+		return false
+	}
+	return !v.hidden
+}
+
+// Update refreshes the history of the selected path (only while the pane is shown).
+func (v *PathHistory) Update() error {
+	if !v.IsVisible() || v.path == "" {
+		v.entries = nil
+		return nil
+	}
+	v.entries = filetree.History(v.trees, v.path)
+	return nil
+}
+
+// Render flushes the state objects to the screen. Each row is a layer that touched the selected path.
+func (v *PathHistory) Render() error {
+	v.logger.Trace("render()")
+
+	v.gui.Update(func(g *gocui.Gui) error {
+		if v.header == nil || v.view == nil {
+			return nil
+		}
+		width, _ := g.Size()
+
+		v.header.Clear()
+		_, _ = fmt.Fprint(v.header, format.RenderHeader("Path History: "+v.path, width, false))
+		_, _ = fmt.Fprintln(v.header, format.Header(fmt.Sprintf(pathHistoryFormat, "Layer", "Change", "Size", "Permission", "UID:GID", "Hash", "Command")))
+
+		v.view.Clear()
+		if len(v.entries) == 0 {
+			_, err := fmt.Fprintln(v.view, format.Faint("(no layer touches this path)"))
+			return err
+		}
+		for _, entry := range v.entries {
+			if _, err := fmt.Fprintln(v.view, v.row(entry)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+func (v *PathHistory) row(entry filetree.HistoryEntry) string {
+	hash := ""
+	if entry.Hash != 0 {
+		hash = fmt.Sprintf("%016x", entry.Hash)
+	}
+
+	var command string
+	if entry.Layer < len(v.layers) {
+		command = strings.TrimSpace(v.layers[entry.Layer].Command)
+	}
+
+	return fmt.Sprintf(pathHistoryFormat,
+		fmt.Sprintf("%d", entry.Layer),
+		entry.Change,
+		humanize.Bytes(uint64(entry.Size)),
+		entry.Info.ModeString(),
+		fmt.Sprintf("%d:%d", entry.Info.Uid, entry.Info.Gid),
+		hash,
+		command,
+	)
+}
+
+// KeyHelp indicates all the possible actions a user can take while the current pane is selected (currently does nothing).
+func (v *PathHistory) KeyHelp() string {
+	return ""
+}
+
+// OnLayoutChange is called whenever the screen dimensions are changed
+func (v *PathHistory) OnLayoutChange() error {
+	err := v.Update()
+	if err != nil {
+		return err
+	}
+	return v.Render()
+}
+
+func (v *PathHistory) Layout(g *gocui.Gui, minX, minY, maxX, maxY int) error {
+	v.logger.Tracef("layout(minX: %d, minY: %d, maxX: %d, maxY: %d)", minX, minY, maxX, maxY)
+
+	// title + column names
+	headerSize := 2
+	header, headerErr := g.SetView(v.Name()+"header", minX, minY, maxX, minY+headerSize+1, 0)
+	view, viewErr := g.SetView(v.Name(), minX, minY+headerSize, maxX, maxY, 0)
+	if utils.IsNewView(viewErr, headerErr) {
+		err := v.Setup(view, header)
+		if err != nil {
+			return fmt.Errorf("unable to setup path history controller: %w", err)
+		}
+	}
+	return nil
+}
+
+// RequestedSize fits all history rows (along with the header), but never more than a third of the screen.
+func (v *PathHistory) RequestedSize(available int) *int {
+	rows := len(v.entries)
+	if rows == 0 {
+		rows = 1
+	}
+	height := rows + 2
+	if limit := available / 3; height > limit {
+		height = max(limit, 3)
+	}
+	return &height
+}
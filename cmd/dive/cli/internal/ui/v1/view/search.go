@@ -0,0 +1,330 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anchore/go-logger"
+	"github.com/awesome-gocui/gocui"
+	"github.com/dustin/go-humanize"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/internal/log"
+	"github.com/wagoodman/dive/internal/utils"
+)
+
+const searchFormat = "%5s  %-10s %10s  %s"
+
+type SearchSelectListener func(filetree.SearchResult) error
+
+// Search holds the UI objects and data models for populating the pane (above the status bar) that searches every
+// layer for paths matching a query (see filetree.ParseQuery for the query syntax) and lists each change found.
+type Search struct {
+	gui     *gocui.Gui
+	view    *gocui.View
+	label   *gocui.View
+	header  *gocui.View
+	results *gocui.View
+	logger  logger.Logger
+
+	labelStr  string
+	maxLength int
+	hidden    bool
+
+	trees    []*filetree.FileTree
+	query    string
+	found    []filetree.SearchResult
+	err      error
+	selected int
+	offset   int
+
+	selectListeners []SearchSelectListener
+	changeListeners []ViewOptionChangeListener
+}
+
+// newSearchView creates a new view object attached the global [gocui] screen object.
+func newSearchView(gui *gocui.Gui, trees []*filetree.FileTree) *Search {
+	return &Search{
+		gui:       gui,
+		logger:    log.Nested("ui", "search"),
+		labelStr:  "Search: ",
+		maxLength: 200,
+		hidden:    true,
+		trees:     trees,
+	}
+}
+
+func (v *Search) AddSearchSelectListener(listener ...SearchSelectListener) {
+	v.selectListeners = append(v.selectListeners, listener...)
+}
+
+// AddSearchChangeListener registers listeners for whenever the results (or the selected result) change.
+func (v *Search) AddSearchChangeListener(listener ...ViewOptionChangeListener) {
+	v.changeListeners = append(v.changeListeners, listener...)
+}
+
+func (v *Search) Name() string {
+	return "search"
+}
+
+// Setup initializes the UI concerns within the context of a global [gocui] view object.
+func (v *Search) Setup(view, header *gocui.View) error {
+	v.logger.Trace("setup()")
+
+	v.view = view
+	v.view.Frame = false
+	v.view.BgColor = gocui.AttrReverse
+	v.view.Editable = true
+	v.view.Editor = v
+
+	v.header = header
+	v.header.Editable = false
+	v.header.Wrap = false
+	v.header.Frame = false
+
+	return v.Render()
+}
+
+// ToggleVisible shows (and focuses) or hides the search pane. The last query and its results are kept while hidden.
+func (v *Search) ToggleVisible() error {
+	v.hidden = !v.hidden
+	if v.hidden {
+		return nil
+	}
+	return v.Focus()
+}
+
+// Focus selects the search input, so that the user can refine the query or pick another result.
+func (v *Search) Focus() error {
+	_, err := v.gui.SetCurrentView(v.Name())
+	if err != nil {
+		return fmt.Errorf("unable to focus search view: %w", err)
+	}
+	return nil
+}
+
+// IsVisible indicates if the search pane is currently shown.
+func (v *Search) IsVisible() bool {
+	if v == nil {
+		return false
+	}
+	return !v.hidden
+}
+
+// Edit intercepts the key press events in the search input. Enter runs the query (or, when the query has not changed
+// since the last search, selects the highlighted result) while the arrow and page keys move through the results.
+func (v *Search) Edit(view *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	if !v.IsVisible() {
+		return
+	}
+
+	cx, _ := view.Cursor()
+	ox, _ := view.Origin()
+	limit := ox+cx+1 > v.maxLength
+	switch {
+	case key == gocui.KeyEnter:
+		query := strings.TrimSpace(v.view.Buffer())
+		if query == v.query && v.err == nil {
+			v.notifySelectListeners()
+			return
+		}
+		v.search(query)
+	case key == gocui.KeyArrowUp:
+		v.moveSelection(-1)
+	case key == gocui.KeyArrowDown:
+		v.moveSelection(1)
+	case key == gocui.KeyPgup:
+		v.moveSelection(-v.pageSize())
+	case key == gocui.KeyPgdn:
+		v.moveSelection(v.pageSize())
+	case ch != 0 && mod == 0 && !limit:
+		view.EditWrite(ch)
+	case key == gocui.KeySpace && !limit:
+		view.EditWrite(' ')
+	case key == gocui.KeyBackspace || key == gocui.KeyBackspace2:
+		view.EditDelete(true)
+	}
+
+	if err := v.Render(); err != nil {
+		v.logger.WithFields("error", err).Debug("unable to render search view")
+	}
+	for _, listener := range v.changeListeners {
+		if err := listener(); err != nil {
+			// note: cannot propagate error from here since this is from the main gogui thread
+			v.logger.WithFields("error", err).Debug("unable to notify search change listeners")
+		}
+	}
+}
+
+func (v *Search) notifySelectListeners() {
+	if v.selected >= len(v.found) {
+		return
+	}
+	for _, listener := range v.selectListeners {
+		err := listener(v.found[v.selected])
+		if err != nil {
+			// note: cannot propagate error from here since this is from the main gogui thread
+			v.logger.WithFields("error", err).Debug("unable to notify search select listeners")
+		}
+	}
+}
+
+// search runs the given query against every layer, replacing the current results.
+func (v *Search) search(expr string) {
+	v.query, v.found, v.err = expr, nil, nil
+	v.selected, v.offset = 0, 0
+	if expr == "" {
+		return
+	}
+
+	query, err := filetree.ParseQuery(expr, v.trees)
+	if err == nil {
+		v.found, err = filetree.Search(v.trees, query)
+	}
+	v.err = err
+}
+
+func (v *Search) moveSelection(delta int) {
+	if len(v.found) == 0 {
+		return
+	}
+	v.selected = min(max(v.selected+delta, 0), len(v.found)-1)
+
+	height := v.pageSize()
+	if v.selected < v.offset {
+		v.offset = v.selected
+	} else if v.selected >= v.offset+height {
+		v.offset = v.selected - height + 1
+	}
+}
+
+func (v *Search) pageSize() int {
+	if v.results == nil {
+		return 1
+	}
+	_, height := v.results.Size()
+	return max(height, 1)
+}
+
+// Update refreshes the state objects for future rendering (currently does nothing, searches run on demand).
+func (v *Search) Update() error {
+	return nil
+}
+
+// Render flushes the state objects to the screen. Each row is a single change to a path that matched the query.
+func (v *Search) Render() error {
+	v.logger.Trace("render()")
+
+	v.gui.Update(func(g *gocui.Gui) error {
+		if v.label == nil || v.header == nil || v.results == nil || v.hidden {
+			return nil
+		}
+
+		v.label.Clear()
+		_, _ = fmt.Fprintln(v.label, format.Header(v.labelStr))
+
+		v.header.Clear()
+		_, _ = fmt.Fprintln(v.header, format.Header(fmt.Sprintf(searchFormat, "Layer", "Change", "Size", "Path")))
+
+		v.results.Clear()
+		switch {
+		case v.query == "":
+			_, err := fmt.Fprintln(v.results, format.Faint("(type a query and press enter to search every layer)"))
+			return err
+		case v.err != nil:
+			_, err := fmt.Fprintln(v.results, format.Faint("(invalid query)"))
+			return err
+		case len(v.found) == 0:
+			_, err := fmt.Fprintln(v.results, format.Faint("(no layer changes a matching path)"))
+			return err
+		}
+
+		stop := min(v.offset+v.pageSize(), len(v.found))
+		for idx := v.offset; idx < stop; idx++ {
+			line := v.row(v.found[idx])
+			if idx == v.selected {
+				line = format.Selected(line)
+			}
+			if _, err := fmt.Fprintln(v.results, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+func (v *Search) row(result filetree.SearchResult) string {
+	size := ""
+	if !result.Info.IsDir {
+		size = humanize.Bytes(uint64(result.Info.Size))
+	}
+	return fmt.Sprintf(searchFormat,
+		fmt.Sprintf("%d", result.Layer),
+		result.DiffType,
+		size,
+		result.Path,
+	)
+}
+
+// KeyHelp indicates all the possible actions a user can take while the current pane is selected.
+func (v *Search) KeyHelp() string {
+	switch {
+	case v.err != nil:
+		return format.StatusControlNormal("▏Invalid search: " + v.err.Error() + " ")
+	case v.query != "" && len(v.found) > 0:
+		return format.StatusControlNormal(fmt.Sprintf("▏%d of %d matches, ↑/↓ to choose, enter to go to the result ", v.selected+1, len(v.found)))
+	default:
+		return format.StatusControlNormal("▏Type to search every layer, enter to search ")
+	}
+}
+
+// OnLayoutChange is called whenever the screen dimensions are changed
+func (v *Search) OnLayoutChange() error {
+	err := v.Update()
+	if err != nil {
+		return err
+	}
+	return v.Render()
+}
+
+func (v *Search) Layout(g *gocui.Gui, minX, minY, maxX, maxY int) error {
+	v.logger.Tracef("layout(minX: %d, minY: %d, maxX: %d, maxY: %d)", minX, minY, maxX, maxY)
+
+	// input row + column names + results (the pane collapses entirely while hidden)
+	inputBottom := min(minY+2, maxY)
+	headerBottom := min(minY+3, maxY)
+	label, labelErr := g.SetView(v.Name()+"label", minX, minY, len(v.labelStr), inputBottom, 0)
+	view, viewErr := g.SetView(v.Name(), minX+(len(v.labelStr)-1), minY, maxX, inputBottom, 0)
+	header, headerErr := g.SetView(v.Name()+"header", minX, minY+1, maxX, headerBottom, 0)
+	results, resultsErr := g.SetView(v.Name()+"results", minX, minY+2, maxX, max(maxY, minY+2), 0)
+
+	if utils.IsNewView(viewErr, labelErr, headerErr, resultsErr) {
+		v.label = label
+		v.label.BgColor = gocui.AttrReverse
+		v.label.Editable = false
+		v.label.Frame = false
+
+		v.results = results
+		v.results.Editable = false
+		v.results.Wrap = false
+		v.results.Frame = false
+
+		err := v.Setup(view, header)
+		if err != nil {
+			return fmt.Errorf("unable to setup search controller: %w", err)
+		}
+	}
+	return nil
+}
+
+// RequestedSize fits all results (along with the input and header rows), but never more than a third of the screen.
+func (v *Search) RequestedSize(available int) *int {
+	rows := max(len(v.found), 1)
+	height := rows + 2
+	if limit := available / 3; height > limit {
+		height = max(limit, 3)
+	}
+	return &height
+}
@@ -23,6 +23,9 @@ type Status struct {
 	selectedView    Helper
 	requestedHeight int
 
+	message      string
+	messageIsErr bool
+
 	helpKeys []*key.Binding
 }
 
@@ -44,6 +47,13 @@ func (v *Status) SetCurrentView(r Helper) {
 	v.selectedView = r
 }
 
+// SetMessage shows the given message before the key help (e.g. the progress of a rebuild while watching the build
+// context), highlighting it when it reports an error.
+func (v *Status) SetMessage(message string, isErr bool) {
+	v.message = message
+	v.messageIsErr = isErr
+}
+
 func (v *Status) Name() string {
 	return v.name
 }
@@ -94,7 +104,17 @@ func (v *Status) Render() error {
 			selectedHelp = v.selectedView.KeyHelp()
 		}
 
-		_, err := fmt.Fprintln(v.view, v.KeyHelp()+selectedHelp+format.StatusNormal("▏"+strings.Repeat(" ", 1000)))
+		// the message is shown first since the key help commonly exceeds the width of the screen
+		var message string
+		if v.message != "" {
+			if v.messageIsErr {
+				message = format.StatusError(" " + v.message + " ")
+			} else {
+				message = format.StatusControlNormal(" " + v.message + " ")
+			}
+		}
+
+		_, err := fmt.Fprintln(v.view, message+v.KeyHelp()+selectedHelp+format.StatusNormal("▏"+strings.Repeat(" ", 1000)))
 		if err != nil {
 			v.logger.WithFields("error", err).Debug("unable to write to buffer")
 		}
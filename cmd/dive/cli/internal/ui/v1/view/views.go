@@ -16,6 +16,9 @@ type Views struct {
 	Layer        *Layer
 	Status       *Status
 	Filter       *Filter
+	PathHistory  *PathHistory
+	Search       *Search
+	Largest      *Largest
 	LayerDetails *LayerDetails
 	ImageDetails *ImageDetails
 	Debug        *Debug
@@ -38,19 +41,27 @@ func NewViews(g *gocui.Gui, cfg v1.Config) (*Views, error) {
 	status.SetCurrentView(layer)
 
 	return &Views{
-		Tree:   tree,
-		Layer:  layer,
-		Status: status,
-		Filter: newFilterView(g),
+		Tree:        tree,
+		Layer:       layer,
+		Status:      status,
+		Filter:      newFilterView(g),
+		PathHistory: newPathHistoryView(g, cfg.Analysis.Layers, cfg.Analysis.RefTrees),
+		Search:      newSearchView(g, cfg.Analysis.RefTrees),
+		Largest:     newLargestView(g, cfg.Analysis.Largest, cfg.Analysis.LayerLargest, cfg.Preferences.KeyBindings),
 		ImageDetails: &ImageDetails{
 			gui:            g,
 			imageName:      cfg.Analysis.Image,
 			imageSize:      cfg.Analysis.SizeBytes,
+			compressedSize: cfg.Analysis.CompressedSizeBytes,
+			layers:         cfg.Analysis.Layers,
 			efficiency:     cfg.Analysis.Efficiency,
 			inefficiencies: cfg.Analysis.Inefficiencies,
+			duplicates:     cfg.Analysis.Duplicates,
+			metadata:       cfg.Analysis.Metadata,
+			attestations:   cfg.Analysis.Attestations,
 			kb:             cfg.Preferences.KeyBindings,
 		},
-		LayerDetails: &LayerDetails{gui: g, kb: cfg.Preferences.KeyBindings},
+		LayerDetails: &LayerDetails{gui: g, layers: cfg.Analysis.Layers, kb: cfg.Preferences.KeyBindings},
 		Debug:        newDebugView(g),
 	}, nil
 }
@@ -61,6 +72,9 @@ func (views *Views) Renderers() []Renderer {
 		views.Layer,
 		views.Status,
 		views.Filter,
+		views.PathHistory,
+		views.Search,
+		views.Largest,
 		views.LayerDetails,
 		views.ImageDetails,
 	}
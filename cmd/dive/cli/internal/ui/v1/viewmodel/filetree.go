@@ -6,7 +6,6 @@ import (
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1"
 	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
 	"github.com/wagoodman/dive/internal/log"
-	"regexp"
 	"strings"
 
 	"github.com/lunixbochs/vtclean"
@@ -25,6 +24,7 @@ type FileTreeViewModel struct {
 
 	CollapseAll                 bool
 	ShowAttributes              bool
+	ShowTreemap                 bool
 	unconstrainedShowAttributes bool
 	HiddenDiffTypes             []bool
 	TreeIndex                   int
@@ -132,6 +132,30 @@ func (vm *FileTreeViewModel) SetTreeByLayer(bottomTreeStart, bottomTreeStop, top
 	return nil
 }
 
+// Restore carries the display options of another view model (e.g. one for a previous build of the image) over to this
+// one, along with the collapsed state of the directories present in both trees.
+func (vm *FileTreeViewModel) Restore(other *FileTreeViewModel) error {
+	vm.CollapseAll = other.CollapseAll
+	vm.ShowAttributes = other.ShowAttributes
+	vm.unconstrainedShowAttributes = other.unconstrainedShowAttributes
+	vm.ShowTreemap = other.ShowTreemap
+	vm.HiddenDiffTypes = append([]bool(nil), other.HiddenDiffTypes...)
+	vm.ModelTree.SortOrder = other.ModelTree.SortOrder
+
+	visitor := func(node *filetree.FileNode) error {
+		newNode, err := vm.ModelTree.GetNode(node.Path())
+		if err == nil {
+			newNode.Data.ViewInfo = node.Data.ViewInfo
+		}
+		return nil
+	}
+	err := other.ModelTree.VisitDepthChildFirst(visitor, nil)
+	if err != nil {
+		return fmt.Errorf("unable to restore tree view state: %w", err)
+	}
+	return nil
+}
+
 // CursorUp performs the internal view's buffer adjustments on cursor up. Note: this is independent of the gocui buffer.
 func (vm *FileTreeViewModel) CursorUp() bool {
 	if vm.TreeIndex <= 0 {
@@ -163,17 +187,17 @@ func (vm *FileTreeViewModel) CursorDown() bool {
 	return true
 }
 
-func (vm *FileTreeViewModel) CurrentNode(filterRegex *regexp.Regexp) *filetree.FileNode {
-	return vm.getAbsPositionNode(filterRegex)
+func (vm *FileTreeViewModel) CurrentNode() *filetree.FileNode {
+	return vm.getAbsPositionNode()
 }
 
 // CursorLeft moves the cursor up until we reach the Parent Node or top of the tree
-func (vm *FileTreeViewModel) CursorLeft(filterRegex *regexp.Regexp) error {
+func (vm *FileTreeViewModel) CursorLeft() error {
 	var visitor func(*filetree.FileNode) error
 	var evaluator func(*filetree.FileNode) bool
 	var dfsCounter, newIndex int
 	oldIndex := vm.TreeIndex
-	currentNode := vm.getAbsPositionNode(filterRegex)
+	currentNode := vm.getAbsPositionNode()
 
 	if currentNode == nil {
 		return nil
@@ -189,12 +213,7 @@ func (vm *FileTreeViewModel) CursorLeft(filterRegex *regexp.Regexp) error {
 	}
 
 	evaluator = func(curNode *filetree.FileNode) bool {
-		regexMatch := true
-		if filterRegex != nil {
-			match := filterRegex.Find([]byte(curNode.Path()))
-			regexMatch = match != nil
-		}
-		return !curNode.Parent.Data.ViewInfo.Collapsed && !curNode.Data.ViewInfo.Hidden && regexMatch
+		return !curNode.Parent.Data.ViewInfo.Collapsed && !curNode.Data.ViewInfo.Hidden
 	}
 
 	err := vm.ModelTree.VisitDepthParentFirst(visitor, evaluator)
@@ -218,8 +237,8 @@ func (vm *FileTreeViewModel) CursorLeft(filterRegex *regexp.Regexp) error {
 }
 
 // CursorRight descends into directory expanding it if needed
-func (vm *FileTreeViewModel) CursorRight(filterRegex *regexp.Regexp) error {
-	node := vm.getAbsPositionNode(filterRegex)
+func (vm *FileTreeViewModel) CursorRight() error {
+	node := vm.getAbsPositionNode()
 	if node == nil {
 		return nil
 	}
@@ -301,7 +320,7 @@ func (vm *FileTreeViewModel) PageUp() error {
 }
 
 // getAbsPositionNode determines the selected screen cursor's location in the file tree, returning the selected FileNode.
-func (vm *FileTreeViewModel) getAbsPositionNode(filterRegex *regexp.Regexp) (node *filetree.FileNode) {
+func (vm *FileTreeViewModel) getAbsPositionNode() (node *filetree.FileNode) {
 	var visitor func(*filetree.FileNode) error
 	var evaluator func(*filetree.FileNode) bool
 	var dfsCounter int
@@ -315,16 +334,11 @@ func (vm *FileTreeViewModel) getAbsPositionNode(filterRegex *regexp.Regexp) (nod
 	}
 
 	evaluator = func(curNode *filetree.FileNode) bool {
-		regexMatch := true
-		if filterRegex != nil {
-			match := filterRegex.Find([]byte(curNode.Path()))
-			regexMatch = match != nil
-		}
 		parentCollapsed := false
 		if curNode.Parent != nil {
 			parentCollapsed = curNode.Parent.Data.ViewInfo.Collapsed
 		}
-		return !parentCollapsed && !curNode.Data.ViewInfo.Hidden && regexMatch
+		return !parentCollapsed && !curNode.Data.ViewInfo.Hidden
 	}
 
 	err := vm.ModelTree.VisitDepthParentFirst(visitor, evaluator)
@@ -335,9 +349,59 @@ func (vm *FileTreeViewModel) getAbsPositionNode(filterRegex *regexp.Regexp) (nod
 	return node
 }
 
+// SelectPath expands all parent directories of the given path and moves the cursor onto it, scrolling the buffer
+// when the path is out of view. Returns false if the path is not part of the (visible) tree.
+func (vm *FileTreeViewModel) SelectPath(path string) (bool, error) {
+	target, err := vm.ModelTree.GetNode(path)
+	if err != nil || target == vm.ModelTree.Root {
+		return false, nil
+	}
+
+	for parent := target.Parent; parent != nil; parent = parent.Parent {
+		parent.Data.ViewInfo.Collapsed = false
+	}
+
+	var dfsCounter int
+	newIndex := -1
+
+	visitor := func(curNode *filetree.FileNode) error {
+		if curNode == target {
+			newIndex = dfsCounter
+		}
+		dfsCounter++
+		return nil
+	}
+
+	evaluator := func(curNode *filetree.FileNode) bool {
+		parentCollapsed := false
+		if curNode.Parent != nil {
+			parentCollapsed = curNode.Parent.Data.ViewInfo.Collapsed
+		}
+		return !parentCollapsed && !curNode.Data.ViewInfo.Hidden
+	}
+
+	err = vm.ModelTree.VisitDepthParentFirst(visitor, evaluator)
+	if err != nil {
+		return false, fmt.Errorf("unable to propagate tree on selectPath: %w", err)
+	}
+
+	if newIndex < 0 {
+		return false, nil
+	}
+
+	vm.TreeIndex = newIndex
+	if newIndex < vm.bufferIndexLowerBound || newIndex > vm.bufferIndexUpperBound() {
+		// center the selection in the pane
+		vm.bufferIndexLowerBound = max(newIndex-vm.height()/2, 0)
+	}
+	vm.bufferIndex = vm.TreeIndex - vm.bufferIndexLowerBound
+
+	return true, nil
+}
+
 // ToggleCollapse will collapse/expand the selected FileNode.
-func (vm *FileTreeViewModel) ToggleCollapse(filterRegex *regexp.Regexp) error {
-	node := vm.getAbsPositionNode(filterRegex)
+func (vm *FileTreeViewModel) ToggleCollapse() error {
+	node := vm.getAbsPositionNode()
 	if node != nil && node.Data.FileInfo.IsDir {
 		node.Data.ViewInfo.Collapsed = !node.Data.ViewInfo.Collapsed
 	}
@@ -397,13 +461,18 @@ func (vm *FileTreeViewModel) ToggleAttributes() error {
 	return nil
 }
 
+// ToggleTreemap will switch between rendering the tree and rendering the directory holding the selected node as a treemap
+func (vm *FileTreeViewModel) ToggleTreemap() {
+	vm.ShowTreemap = !vm.ShowTreemap
+}
+
 // ToggleShowDiffType will show/hide the selected DiffType in the filetree pane.
 func (vm *FileTreeViewModel) ToggleShowDiffType(diffType filetree.DiffType) {
 	vm.HiddenDiffTypes[diffType] = !vm.HiddenDiffTypes[diffType]
 }
 
 // Update refreshes the state objects for future rendering.
-func (vm *FileTreeViewModel) Update(filterRegex *regexp.Regexp, width, height int) error {
+func (vm *FileTreeViewModel) Update(filter *filetree.Query, width, height int) error {
 	vm.refWidth = width
 	vm.refHeight = height
 
@@ -417,10 +486,9 @@ func (vm *FileTreeViewModel) Update(filterRegex *regexp.Regexp, width, height in
 				node.Data.ViewInfo.Hidden = false
 			}
 		}
-		// hide nodes that do not match the current file filter regex (also don't unhide nodes that are already hidden)
-		if filterRegex != nil && !visibleChild && !node.Data.ViewInfo.Hidden {
-			match := filterRegex.FindString(node.Path())
-			node.Data.ViewInfo.Hidden = len(match) == 0
+		// hide nodes that do not match the current file filter (also don't unhide nodes that are already hidden)
+		if filter != nil && !visibleChild && !node.Data.ViewInfo.Hidden {
+			node.Data.ViewInfo.Hidden = !filter.Match(node)
 		}
 		return nil
 	}, nil)
@@ -450,6 +518,10 @@ func (vm *FileTreeViewModel) Update(filterRegex *regexp.Regexp, width, height in
 
 // Render flushes the state objects (file tree) to the pane.
 func (vm *FileTreeViewModel) Render() error {
+	if vm.ShowTreemap {
+		return vm.renderTreemap()
+	}
+
 	treeString := vm.ViewTree.StringBetween(vm.bufferIndexLowerBound, vm.bufferIndexUpperBound(), vm.ShowAttributes)
 	lines := strings.Split(treeString, "\n")
 
@@ -10,7 +10,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"testing"
 
@@ -78,9 +77,9 @@ func initializeTestViewModel(t *testing.T) *FileTreeViewModel {
 	return vm
 }
 
-func runTestCase(t *testing.T, vm *FileTreeViewModel, width, height int, filterRegex *regexp.Regexp) {
+func runTestCase(t *testing.T, vm *FileTreeViewModel, width, height int, filter *filetree.Query) {
 	t.Helper()
-	err := vm.Update(filterRegex, width, height)
+	err := vm.Update(filter, width, height)
 	if err != nil {
 		t.Errorf("failed to update viewmodel: %v", err)
 	}
@@ -141,6 +140,20 @@ func TestFileTreeRestrictedHeight(t *testing.T) {
 	runTestCase(t, vm, width, height, nil)
 }
 
+func TestFileTreeTreemap(t *testing.T) {
+	vm := initializeTestViewModel(t)
+
+	width, height := 80, 20
+	vm.Setup(0, height)
+	vm.ShowTreemap = true
+
+	// show the final image, compared against the base layer
+	err := vm.SetTreeByLayer(0, 0, 1, 13)
+	checkError(t, err, "unable to SetTreeByLayer")
+
+	runTestCase(t, vm, width, height, nil)
+}
+
 func TestFileTreeDirCollapse(t *testing.T) {
 	vm := initializeTestViewModel(t)
 
@@ -151,7 +164,7 @@ func TestFileTreeDirCollapse(t *testing.T) {
 	assertPath(t, vm, "/bin", "before toggle of bin")
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 	assertPath(t, vm, "/bin", "after toggle of bin")
 
@@ -164,7 +177,7 @@ func TestFileTreeDirCollapse(t *testing.T) {
 	assertPath(t, vm, "/etc", "down to etc")
 
 	// collapse /etc
-	err = vm.ToggleCollapse(nil)
+	err = vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /etc")
 	assertPath(t, vm, "/etc", "after toggle of etc")
 
@@ -173,7 +186,7 @@ func TestFileTreeDirCollapse(t *testing.T) {
 
 func assertPath(t *testing.T, vm *FileTreeViewModel, expected string, msg string) {
 	t.Helper()
-	n := vm.CurrentNode(nil)
+	n := vm.CurrentNode()
 	require.NotNil(t, n, "unable to get current node")
 	assert.Equal(t, expected, n.Path(), msg)
 }
@@ -199,7 +212,7 @@ func TestFileTreeSelectLayer(t *testing.T) {
 	vm.ShowAttributes = true
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 
 	// select the next layer, compareMode = layer
@@ -218,7 +231,7 @@ func TestFileShowAggregateChanges(t *testing.T) {
 	vm.ShowAttributes = true
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 
 	// select the next layer, compareMode = layer
@@ -280,7 +293,7 @@ func TestFileTreeDirCursorRight(t *testing.T) {
 	vm.ShowAttributes = true
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 
 	moved := vm.CursorDown()
@@ -294,11 +307,11 @@ func TestFileTreeDirCursorRight(t *testing.T) {
 	}
 
 	// collapse /etc
-	err = vm.ToggleCollapse(nil)
+	err = vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /etc")
 
 	// expand /etc
-	err = vm.CursorRight(nil)
+	err = vm.CursorRight()
 	checkError(t, err, "unable to cursor right")
 
 	runTestCase(t, vm, width, height, nil)
@@ -311,12 +324,60 @@ func TestFileTreeFilterTree(t *testing.T) {
 	vm.Setup(0, height)
 	vm.ShowAttributes = true
 
-	regex, err := regexp.Compile("network")
+	filter, err := filetree.ParseQuery("network", nil)
+	if err != nil {
+		t.Errorf("could not create filter: %+v", err)
+	}
+
+	runTestCase(t, vm, width, height, filter)
+}
+
+func TestFileTreeFilterQuery(t *testing.T) {
+	vm := initializeTestViewModel(t)
+
+	width, height := 100, 1000
+	vm.Setup(0, height)
+	vm.ShowAttributes = true
+
+	// the last layer is shown, so this selects everything written by the build (but not the base image)
+	err := vm.SetTreeByLayer(0, 0, 1, len(vm.RefTrees)-1)
+	checkError(t, err, "unable to SetTreeByLayer")
+
+	filter, err := filetree.ParseQuery("layer>=1 type:file size>1kB", vm.RefTrees)
 	if err != nil {
-		t.Errorf("could not create filter regex: %+v", err)
+		t.Errorf("could not create filter: %+v", err)
 	}
 
-	runTestCase(t, vm, width, height, regex)
+	runTestCase(t, vm, width, height, filter)
+}
+
+func TestFileTreeFilterNavigation(t *testing.T) {
+	vm := initializeTestViewModel(t)
+
+	width, height := 100, 1000
+	vm.Setup(0, height)
+
+	require.NoError(t, vm.SetTreeByLayer(0, 0, 1, len(vm.RefTrees)-1))
+
+	filter, err := filetree.ParseQuery("**/*.txt", vm.RefTrees)
+	require.NoError(t, err)
+	checkError(t, vm.Update(filter, width, height), "unable to update viewmodel")
+
+	// every row shown (including parent directories that do not match the filter themselves) can be selected
+	var rows []string
+	err = vm.ViewTree.VisitDepthParentFirst(func(node *filetree.FileNode) error {
+		rows = append(rows, node.Path())
+		return nil
+	}, func(node *filetree.FileNode) bool {
+		return !node.Parent.Data.ViewInfo.Collapsed
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+
+	for idx, row := range rows {
+		require.Equal(t, row, vm.CurrentNode().Path(), "row %d", idx)
+		vm.CursorDown()
+	}
 }
 
 func TestFileTreeHideAddedRemovedModified(t *testing.T) {
@@ -327,7 +388,7 @@ func TestFileTreeHideAddedRemovedModified(t *testing.T) {
 	vm.ShowAttributes = true
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 
 	// select the 7th layer, compareMode = layer
@@ -356,7 +417,7 @@ func TestFileTreeHideUnmodified(t *testing.T) {
 	vm.ShowAttributes = true
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 
 	// select the 7th layer, compareMode = layer
@@ -379,7 +440,7 @@ func TestFileTreeHideTypeWithFilter(t *testing.T) {
 	vm.ShowAttributes = true
 
 	// collapse /bin
-	err := vm.ToggleCollapse(nil)
+	err := vm.ToggleCollapse()
 	checkError(t, err, "unable to collapse /bin")
 
 	// select the 7th layer, compareMode = layer
@@ -391,12 +452,12 @@ func TestFileTreeHideTypeWithFilter(t *testing.T) {
 	// hide added files
 	vm.ToggleShowDiffType(filetree.Added)
 
-	regex, err := regexp.Compile("saved")
+	filter, err := filetree.ParseQuery("saved", nil)
 	if err != nil {
-		t.Errorf("could not create filter regex: %+v", err)
+		t.Errorf("could not create filter: %+v", err)
 	}
 
-	runTestCase(t, vm, width, height, regex)
+	runTestCase(t, vm, width, height, filter)
 }
 
 func repoPath(t testing.TB, path string) string {
@@ -420,3 +481,58 @@ func repoRoot(t testing.TB) string {
 	repoRootCache.Store(val)
 	return val
 }
+
+func TestFileTreeSelectPath(t *testing.T) {
+	vm := initializeTestViewModel(t)
+
+	width, height := 100, 4
+	vm.Setup(0, height)
+	vm.ShowAttributes = false
+
+	checkError(t, vm.ToggleCollapseAll(), "unable to collapse all dirs")
+	checkError(t, vm.Update(nil, width, height), "unable to update viewmodel")
+
+	found, err := vm.SelectPath("/var/spool/mail")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	assert.Equal(t, "/var/spool/mail", vm.CurrentNode().Path())
+	assert.False(t, vm.CurrentNode().Parent.Data.ViewInfo.Collapsed)
+	// the selection is out of view of the collapsed tree, so the buffer is scrolled
+	assert.Greater(t, vm.bufferIndexLowerBound, 0)
+	assert.Equal(t, vm.TreeIndex-vm.bufferIndexLowerBound, vm.bufferIndex)
+	assert.LessOrEqual(t, vm.bufferIndex, vm.height())
+
+	found, err = vm.SelectPath("/does/not/exist")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestFileTreeRestore(t *testing.T) {
+	previous := initializeTestViewModel(t)
+
+	width, height := 100, 100
+	previous.Setup(0, height)
+	previous.ShowAttributes = false
+	previous.ToggleShowDiffType(filetree.Removed)
+	checkError(t, previous.ToggleCollapse(), "unable to collapse /bin")
+	assertPath(t, previous, "/bin", "collapsed bin")
+
+	vm := initializeTestViewModel(t)
+	vm.Setup(0, height)
+	checkError(t, vm.Restore(previous), "unable to restore viewmodel")
+
+	assert.False(t, vm.ShowAttributes)
+	assert.True(t, vm.HiddenDiffTypes[filetree.Removed])
+
+	bin, err := vm.ModelTree.GetNode("/bin")
+	require.NoError(t, err)
+	assert.True(t, bin.Data.ViewInfo.Collapsed)
+
+	// the restored state is not shared with the other view model
+	previous.ToggleShowDiffType(filetree.Removed)
+	assert.True(t, vm.HiddenDiffTypes[filetree.Removed])
+
+	checkError(t, vm.Update(nil, width, height), "unable to update viewmodel")
+	assertPath(t, vm, "/bin", "restored collapsed bin")
+}
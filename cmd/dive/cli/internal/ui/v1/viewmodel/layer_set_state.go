@@ -1,12 +1,24 @@
 package viewmodel
 
-import "github.com/wagoodman/dive/dive/image"
+import (
+	"sort"
+
+	"github.com/wagoodman/dive/dive/image"
+)
 
 type LayerSetState struct {
 	LayerIndex        int
 	Layers            []*image.Layer
 	CompareMode       LayerCompareMode
 	CompareStartIndex int
+	// Wasted is the number of bytes of each layer that are not visible in the final image (whited out or overwritten
+	// by a later layer), by layer index
+	Wasted []int64
+	// SortByWaste lists the most wasteful layers first (instead of in build order)
+	SortByWaste bool
+	// Rebuilt indicates which layers differ from the previous build of the image, by layer index (only set when the
+	// image has been rebuilt while watching the build context)
+	Rebuilt []bool
 }
 
 func NewLayerSetState(layers []*image.Layer, compareMode LayerCompareMode) *LayerSetState {
@@ -34,3 +46,68 @@ func (state *LayerSetState) GetCompareIndexes() (bottomTreeStart, bottomTreeStop
 
 	return bottomTreeStart, bottomTreeStop, topTreeStart, topTreeStop
 }
+
+// Order returns the layer indexes in the order they are listed.
+func (state *LayerSetState) Order() []int {
+	order := make([]int, len(state.Layers))
+	for idx := range order {
+		order[idx] = idx
+	}
+	if state.SortByWaste {
+		sort.SliceStable(order, func(i, j int) bool {
+			return state.wasted(order[i]) > state.wasted(order[j])
+		})
+	}
+	return order
+}
+
+// Step returns the index of the layer listed the given number of rows away from the selected layer (stopping at the
+// first or last row).
+func (state *LayerSetState) Step(rows int) int {
+	order := state.Order()
+	if len(order) == 0 {
+		return state.LayerIndex
+	}
+	position := 0
+	for idx, layerIdx := range order {
+		if layerIdx == state.LayerIndex {
+			position = idx
+			break
+		}
+	}
+	position = min(max(position+rows, 0), len(order)-1)
+	return order[position]
+}
+
+// MostWasted returns the index of the layer with the most wasted bytes, or -1 when no layer wastes any bytes.
+func (state *LayerSetState) MostWasted() int {
+	result := -1
+	for idx := range state.Layers {
+		if state.wasted(idx) > 0 && (result < 0 || state.wasted(idx) > state.wasted(result)) {
+			result = idx
+		}
+	}
+	return result
+}
+
+// IsRebuilt indicates if the given layer differs from the previous build of the image.
+func (state *LayerSetState) IsRebuilt(layerIdx int) bool {
+	return layerIdx < len(state.Rebuilt) && state.Rebuilt[layerIdx]
+}
+
+func (state *LayerSetState) wasted(layerIdx int) int64 {
+	if layerIdx < len(state.Wasted) {
+		return state.Wasted[layerIdx]
+	}
+	return 0
+}
+
+// RebuiltLayers compares the layers of a rebuilt image with the layers of its previous build, indicating (by layer
+// index) which layers have different content than the layer at the same index of the previous build.
+func RebuiltLayers(previous, current []*image.Layer) []bool {
+	rebuilt := make([]bool, len(current))
+	for idx, layer := range current {
+		rebuilt[idx] = idx >= len(previous) || previous[idx].Digest != layer.Digest
+	}
+	return rebuilt
+}
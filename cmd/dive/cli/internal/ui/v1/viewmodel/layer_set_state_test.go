@@ -1,7 +1,10 @@
 package viewmodel
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/wagoodman/dive/dive/image"
 )
 
 func TestGetCompareIndexes(t *testing.T) {
@@ -50,3 +53,56 @@ func TestGetCompareIndexes(t *testing.T) {
 		})
 	}
 }
+
+func TestLayerSetState_SortByWaste(t *testing.T) {
+	state := NewLayerSetState(make([]*image.Layer, 4), CompareSingleLayer)
+	state.Wasted = []int64{0, 300, 0, 500}
+
+	if actual := state.MostWasted(); actual != 3 {
+		t.Errorf("expected most wasted layer 3, got %d", actual)
+	}
+
+	state.LayerIndex = 1
+	if actual := state.Step(1); actual != 2 {
+		t.Errorf("expected build order step to layer 2, got %d", actual)
+	}
+
+	state.SortByWaste = true
+	if actual, expected := state.Order(), []int{3, 1, 0, 2}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected order %v, got %v", expected, actual)
+	}
+	if actual := state.Step(1); actual != 0 {
+		t.Errorf("expected sorted step to layer 0, got %d", actual)
+	}
+	if actual := state.Step(-10); actual != 3 {
+		t.Errorf("expected the step to stop at the first row (layer 3), got %d", actual)
+	}
+
+	state.Wasted = nil
+	if actual := state.MostWasted(); actual != -1 {
+		t.Errorf("expected no wasteful layer, got %d", actual)
+	}
+}
+
+func TestRebuiltLayers(t *testing.T) {
+	layers := func(digests ...string) []*image.Layer {
+		var result []*image.Layer
+		for idx, digest := range digests {
+			result = append(result, &image.Layer{Index: idx, Digest: digest})
+		}
+		return result
+	}
+
+	previous := layers("sha256:base", "sha256:deps", "sha256:app")
+	current := layers("sha256:base", "sha256:deps", "sha256:app-changed", "sha256:extra")
+
+	state := NewLayerSetState(current, CompareSingleLayer)
+	state.Rebuilt = RebuiltLayers(previous, current)
+
+	if actual, expected := state.Rebuilt, []bool{false, false, true, true}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected rebuilt layers %v, got %v", expected, actual)
+	}
+	if state.IsRebuilt(1) || !state.IsRebuilt(3) || state.IsRebuilt(4) {
+		t.Errorf("unexpected rebuilt state: %v", state.Rebuilt)
+	}
+}
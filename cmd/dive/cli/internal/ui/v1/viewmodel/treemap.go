@@ -0,0 +1,120 @@
+package viewmodel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+
+	"github.com/wagoodman/dive/cmd/dive/cli/internal/ui/v1/format"
+	"github.com/wagoodman/dive/dive/filetree"
+)
+
+// treemapColor matches the colours used for each DiffType within the tree.
+var treemapColor = map[filetree.DiffType]*color.Color{
+	filetree.Added:      color.New(color.FgGreen),
+	filetree.Removed:    color.New(color.FgRed),
+	filetree.Modified:   color.New(color.FgYellow),
+	filetree.Unmodified: color.New(color.Reset),
+}
+
+// treemapFill alternates between neighbouring tiles so that tiles of the same colour can be told apart.
+var treemapFill = []rune{'▓', '░'}
+
+// renderTreemap draws the contents of the directory holding the selected node as a treemap, where the area of each
+// tile is proportional to its size and the selected node is highlighted.
+func (vm *FileTreeViewModel) renderTreemap() error {
+	vm.Buffer.Reset()
+
+	width, height := vm.refWidth, vm.height()
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	selected := vm.getAbsPositionNode()
+	root := vm.ViewTree.Root
+	if selected != nil && selected.Parent != nil {
+		if node, err := vm.ViewTree.GetNode(selected.Parent.Path()); err == nil {
+			root = node
+		}
+	}
+
+	// terminal cells are roughly twice as tall as they are wide, so lay the tiles out on a grid of square units
+	tiles := filetree.Treemap(root, float64(width), float64(height*2), 1, 0)
+	if len(tiles) == 0 {
+		_, err := fmt.Fprintln(&vm.Buffer, format.Faint(" (nothing to show)"))
+		return err
+	}
+
+	owners := make([][]int, height)
+	labels := make([][]rune, height)
+	for y := range owners {
+		owners[y] = make([]int, width)
+		labels[y] = make([]rune, width)
+		for x := range owners[y] {
+			owners[y][x] = -1
+		}
+	}
+
+	for idx, tile := range tiles {
+		x0, x1 := treemapCell(tile.X, width), treemapCell(tile.X+tile.Width, width)
+		y0, y1 := treemapCell(tile.Y/2, height), treemapCell((tile.Y+tile.Height)/2, height)
+		if x1 <= x0 || y1 <= y0 {
+			// too small to draw
+			continue
+		}
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				owners[y][x] = idx
+			}
+		}
+
+		label := []rune(fmt.Sprintf("%s %s", tile.Node.Name, humanize.Bytes(uint64(tile.Node.GetSize()))))
+		if len(label) > x1-x0 {
+			label = []rune(tile.Node.Name)
+		}
+		for offset := 0; offset < len(label) && x0+offset < x1; offset++ {
+			labels[y0][x0+offset] = label[offset]
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		for x := 0; x < width; {
+			owner := owners[y][x]
+			var run strings.Builder
+			for ; x < width && owners[y][x] == owner; x++ {
+				switch {
+				case owner < 0:
+					run.WriteRune(' ')
+				case labels[y][x] != 0:
+					run.WriteRune(labels[y][x])
+				default:
+					run.WriteRune(treemapFill[owner%len(treemapFill)])
+				}
+			}
+
+			switch {
+			case owner < 0:
+				line.WriteString(run.String())
+			case selected != nil && tiles[owner].Node.Path() == selected.Path():
+				line.WriteString(format.Selected(run.String()))
+			default:
+				line.WriteString(treemapColor[tiles[owner].Node.Data.DiffType].Sprint(run.String()))
+			}
+		}
+
+		_, err := fmt.Fprintln(&vm.Buffer, line.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// treemapCell rounds a treemap coordinate to the nearest cell, staying within the given bound.
+func treemapCell(value float64, bound int) int {
+	return max(0, min(bound, int(math.Round(value))))
+}
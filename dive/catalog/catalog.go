@@ -0,0 +1,167 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// MaxPackageFileSize is the largest package database or manifest that is kept for cataloging.
+const MaxPackageFileSize = 32 * 1024 * 1024
+
+// package types, which match the purl types
+const (
+	TypeApk  = "apk"
+	TypeDeb  = "deb"
+	TypeNpm  = "npm"
+	TypePyPI = "pypi"
+	TypeGem  = "gem"
+)
+
+// Package is a piece of software installed in the image, along with the layer that introduced it.
+type Package struct {
+	Name    string
+	Version string
+	Type    string
+	PURL    string
+	// Path is the package database or manifest the package was found in
+	Path string
+	// Layer is the layer that introduced the package (at this version)
+	Layer *image.Layer
+}
+
+func (p Package) key() string {
+	return strings.Join([]string{p.Type, p.Name, p.Version, p.Path}, "|")
+}
+
+// IsPackageFile indicates if the file at the given (absolute) path describes installed software, so should be kept
+// when reading the image.
+func IsPackageFile(filePath string) bool {
+	if isOSRelease(filePath) {
+		return true
+	}
+	for _, c := range catalogers {
+		if c.match(filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Packages catalogs the software installed in the final image from the package files found in each layer. Each
+// package is attributed to the first layer in which it is present (at its final version), which answers which build
+// step brought it in.
+func Packages(layers []*image.Layer) []Package {
+	files := make(map[string][]byte)
+	parsed := make(map[string][]Package)
+	introducedBy := make(map[string]*image.Layer)
+	var previous map[string]bool
+
+	for _, layer := range layers {
+		for _, removed := range removedPaths(layer.Tree) {
+			for filePath := range files {
+				if filePath == removed || strings.HasPrefix(filePath, removed+"/") {
+					delete(files, filePath)
+					delete(parsed, filePath)
+				}
+			}
+		}
+
+		for filePath, content := range layer.PackageFiles {
+			files[filePath] = content
+			parsed[filePath] = parse(filePath, content)
+		}
+
+		current := make(map[string]bool)
+		for _, pkgs := range parsed {
+			for _, pkg := range pkgs {
+				k := pkg.key()
+				current[k] = true
+				if !previous[k] {
+					introducedBy[k] = layer
+				}
+			}
+		}
+		previous = current
+	}
+
+	distro := distroID(files)
+
+	var result []Package
+	for _, pkgs := range parsed {
+		for _, pkg := range pkgs {
+			pkg.Layer = introducedBy[pkg.key()]
+			pkg.PURL = purl(pkg, distro)
+			result = append(result, pkg)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].key() < result[j].key()
+	})
+
+	return result
+}
+
+// removedPaths lists the paths that are deleted by the given layer (via whiteouts and opaque directories).
+func removedPaths(tree *filetree.FileTree) []string {
+	if tree == nil {
+		return nil
+	}
+
+	var removed []string
+	if tree.Root.Opaque {
+		removed = append(removed, tree.Root.Path())
+	}
+	visitor := func(node *filetree.FileNode) error {
+		switch {
+		case node.Opaque:
+			removed = append(removed, node.Path())
+		case node.IsWhiteout():
+			removed = append(removed, node.Path())
+		}
+		return nil
+	}
+	if err := tree.VisitDepthChildFirst(visitor, nil); err != nil {
+		log.WithFields("layer", tree.Name, "error", err).Debug("unable to find removed paths")
+	}
+	return removed
+}
+
+func parse(filePath string, content []byte) []Package {
+	for _, c := range catalogers {
+		if !c.match(filePath) {
+			continue
+		}
+		pkgs := c.parse(filePath, content)
+		for idx := range pkgs {
+			pkgs[idx].Type = c.packageType
+			pkgs[idx].Path = filePath
+		}
+		return pkgs
+	}
+	return nil
+}
+
+func isOSRelease(filePath string) bool {
+	return filePath == "/etc/os-release" || filePath == "/usr/lib/os-release"
+}
+
+// distroID is the ID of the linux distribution (from os-release), which namespaces OS packages.
+func distroID(files map[string][]byte) string {
+	for _, filePath := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		content, exists := files[filePath]
+		if !exists {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(line), "ID="); ok {
+				return strings.Trim(value, `"'`)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package catalog
+
+import (
+	"archive/tar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/filetree"
+	"github.com/wagoodman/dive/dive/image"
+)
+
+func newLayer(t *testing.T, index int, command string, files map[string]string, removed ...string) *image.Layer {
+	t.Helper()
+	tree := filetree.NewFileTree()
+	packageFiles := make(map[string][]byte)
+	for filePath, content := range files {
+		_, _, err := tree.AddPath(filePath, filetree.FileInfo{Path: filePath, TypeFlag: tar.TypeReg, Size: int64(len(content))})
+		require.NoError(t, err)
+		packageFiles[filePath] = []byte(content)
+	}
+	for _, filePath := range removed {
+		_, _, err := tree.AddPath(filePath, filetree.FileInfo{Path: filePath, TypeFlag: tar.TypeReg})
+		require.NoError(t, err)
+	}
+	return &image.Layer{Index: index, Command: command, Tree: tree, PackageFiles: packageFiles}
+}
+
+func TestPackages(t *testing.T) {
+	base := newLayer(t, 0, "ADD alpine.tar /", map[string]string{
+		"/etc/os-release":       "NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.19.0\n",
+		"/lib/apk/db/installed": "P:musl\nV:1.2.4-r2\n\nP:busybox\nV:1.36.1-r15\n",
+	})
+	curl := newLayer(t, 1, "RUN apk add curl", map[string]string{
+		"/lib/apk/db/installed": "P:musl\nV:1.2.4-r2\n\nP:busybox\nV:1.36.1-r15\n\nP:curl\nV:8.5.0-r0\n",
+	})
+	npm := newLayer(t, 2, "RUN npm install", map[string]string{
+		"/app/node_modules/left-pad/package.json":      `{"name": "left-pad", "version": "1.3.0"}`,
+		"/app/node_modules/@types/node/package.json":   `{"name": "@types/node", "version": "20.1.0"}`,
+		"/app/node_modules/left-pad/lib/package.json":  `{"name": "not-a-package", "version": "0.0.0"}`,
+		"/usr/lib/python3/dist-packages/x.dist-info/X": "ignored",
+	})
+	upgrade := newLayer(t, 3, "RUN apk upgrade busybox && rm -rf /app/node_modules/left-pad", map[string]string{
+		"/lib/apk/db/installed": "P:musl\nV:1.2.4-r2\n\nP:busybox\nV:1.36.1-r16\n\nP:curl\nV:8.5.0-r0\n",
+	}, "/app/node_modules/.wh.left-pad")
+
+	pkgs := Packages([]*image.Layer{base, curl, npm, upgrade})
+
+	type summary struct {
+		purl  string
+		layer int
+	}
+	var actual []summary
+	for _, pkg := range pkgs {
+		actual = append(actual, summary{purl: pkg.PURL, layer: pkg.Layer.Index})
+	}
+
+	assert.Equal(t, []summary{
+		{purl: "pkg:apk/alpine/busybox@1.36.1-r16", layer: 3},
+		{purl: "pkg:apk/alpine/curl@8.5.0-r0", layer: 1},
+		{purl: "pkg:apk/alpine/musl@1.2.4-r2", layer: 0},
+		{purl: "pkg:npm/%40types/node@20.1.0", layer: 2},
+	}, actual)
+}
+
+func TestParseDpkgStatus(t *testing.T) {
+	content := `Package: libc6
+Status: install ok installed
+Version: 2.36-9
+Description: GNU C Library
+ continuation of the description
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+
+Package: zlib1g
+Status: install ok installed
+Version: 1:1.2.13.dfsg-1
+`
+	assert.Equal(t, []Package{
+		{Name: "libc6", Version: "2.36-9"},
+		{Name: "zlib1g", Version: "1:1.2.13.dfsg-1"},
+	}, parseDpkgStatus("/var/lib/dpkg/status", []byte(content)))
+}
+
+func TestIsPackageFile(t *testing.T) {
+	tests := map[string]bool{
+		"/lib/apk/db/installed":                                      true,
+		"/var/lib/dpkg/status":                                       true,
+		"/var/lib/dpkg/status.d/libc6":                               true,
+		"/var/lib/dpkg/status.d/libc6.md5sums":                       false,
+		"/usr/lib/node_modules/npm/package.json":                     true,
+		"/usr/lib/node_modules/npm/lib/package.json":                 false,
+		"/app/node_modules/@scope/pkg/package.json":                  true,
+		"/usr/lib/python3/site-packages/requests.dist-info/METADATA": true,
+		"/usr/lib/python3/site-packages/six.egg-info/PKG-INFO":       true,
+		"/usr/local/bundle/specifications/rake-13.0.6.gemspec":       true,
+		"/etc/os-release":                                            true,
+		"/etc/passwd":                                                false,
+	}
+	for filePath, expected := range tests {
+		assert.Equal(t, expected, IsPackageFile(filePath), filePath)
+	}
+}
+
+func TestParseGemspecName(t *testing.T) {
+	assert.Equal(t, []Package{{Name: "aws-sdk-core", Version: "3.190.0"}},
+		parseGemspecName("/usr/local/bundle/specifications/aws-sdk-core-3.190.0.gemspec", nil))
+}
@@ -0,0 +1,222 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type cataloger struct {
+	packageType string
+	match       func(filePath string) bool
+	parse       func(filePath string, content []byte) []Package
+}
+
+var catalogers = []cataloger{
+	{
+		packageType: TypeApk,
+		match:       func(filePath string) bool { return filePath == "/lib/apk/db/installed" },
+		parse:       parseApkDB,
+	},
+	{
+		packageType: TypeDeb,
+		match: func(filePath string) bool {
+			if filePath == "/var/lib/dpkg/status" {
+				return true
+			}
+			// distroless images keep one status file per package
+			return path.Dir(filePath) == "/var/lib/dpkg/status.d" && !strings.Contains(path.Base(filePath), ".")
+		},
+		parse: parseDpkgStatus,
+	},
+	{
+		packageType: TypeNpm,
+		match:       isNodeModulePackageJSON,
+		parse:       parsePackageJSON,
+	},
+	{
+		packageType: TypePyPI,
+		match: func(filePath string) bool {
+			return (path.Base(filePath) == "METADATA" && strings.HasSuffix(path.Dir(filePath), ".dist-info")) ||
+				(path.Base(filePath) == "PKG-INFO" && strings.HasSuffix(path.Dir(filePath), ".egg-info"))
+		},
+		parse: parsePythonMetadata,
+	},
+	{
+		packageType: TypeGem,
+		match: func(filePath string) bool {
+			return path.Base(path.Dir(filePath)) == "specifications" && strings.HasSuffix(filePath, ".gemspec")
+		},
+		parse: parseGemspecName,
+	},
+}
+
+// parseApkDB reads the alpine package database, where each package is a block of "K:value" lines.
+func parseApkDB(_ string, content []byte) []Package {
+	var pkgs []Package
+	var current Package
+
+	flush := func() {
+		if current.Name != "" {
+			pkgs = append(pkgs, current)
+		}
+		current = Package{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxPackageFileSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "P":
+			current.Name = value
+		case "V":
+			current.Version = value
+		}
+	}
+	flush()
+
+	return pkgs
+}
+
+// parseDpkgStatus reads the debian package database, where each package is a paragraph of "Key: value" fields.
+func parseDpkgStatus(_ string, content []byte) []Package {
+	var pkgs []Package
+	for _, fields := range paragraphs(content) {
+		// packages that were removed (but not purged) are still listed
+		if status, exists := fields["Status"]; exists && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		if fields["Package"] == "" {
+			continue
+		}
+		pkgs = append(pkgs, Package{
+			Name:    fields["Package"],
+			Version: fields["Version"],
+		})
+	}
+	return pkgs
+}
+
+// parsePythonMetadata reads the (email header style) metadata of an installed python distribution.
+func parsePythonMetadata(_ string, content []byte) []Package {
+	all := paragraphs(content)
+	if len(all) == 0 || all[0]["Name"] == "" {
+		return nil
+	}
+	return []Package{{
+		Name:    all[0]["Name"],
+		Version: all[0]["Version"],
+	}}
+}
+
+func parsePackageJSON(_ string, content []byte) []Package {
+	var manifest struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil || manifest.Name == "" {
+		return nil
+	}
+	return []Package{{
+		Name:    manifest.Name,
+		Version: manifest.Version,
+	}}
+}
+
+// parseGemspecName derives the package from the name of an installed gem specification (e.g. "rake-13.0.6.gemspec").
+func parseGemspecName(filePath string, _ []byte) []Package {
+	base := strings.TrimSuffix(path.Base(filePath), ".gemspec")
+	idx := strings.LastIndex(base, "-")
+	if idx <= 0 {
+		return nil
+	}
+	return []Package{{
+		Name:    base[:idx],
+		Version: base[idx+1:],
+	}}
+}
+
+// isNodeModulePackageJSON matches the manifest of a package installed in node_modules (optionally scoped), but not
+// any other package.json files found within installed packages.
+func isNodeModulePackageJSON(filePath string) bool {
+	if path.Base(filePath) != "package.json" {
+		return false
+	}
+	pkgDir := path.Dir(filePath)
+	parent := path.Dir(pkgDir)
+	if path.Base(parent) == "node_modules" {
+		return true
+	}
+	return strings.HasPrefix(path.Base(parent), "@") && path.Base(path.Dir(parent)) == "node_modules"
+}
+
+// paragraphs splits RFC822 style content into blocks of fields, joining continuation lines.
+func paragraphs(content []byte) []map[string]string {
+	var result []map[string]string
+	current := make(map[string]string)
+	var lastKey string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxPackageFileSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(current) > 0 {
+				result = append(result, current)
+			}
+			current = make(map[string]string)
+			lastKey = ""
+		case line[0] == ' ' || line[0] == '\t':
+			if lastKey != "" {
+				current[lastKey] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			lastKey = key
+			current[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// purl creates the package URL (see https://github.com/package-url/purl-spec) for the given package.
+func purl(pkg Package, distro string) string {
+	name := url.PathEscape(pkg.Name)
+	switch pkg.Type {
+	case TypeApk, TypeDeb:
+		if distro == "" {
+			return fmt.Sprintf("pkg:%s/%s@%s", pkg.Type, name, url.PathEscape(pkg.Version))
+		}
+		return fmt.Sprintf("pkg:%s/%s/%s@%s", pkg.Type, url.PathEscape(distro), name, url.PathEscape(pkg.Version))
+	case TypeNpm:
+		if scope, pkgName, ok := strings.Cut(pkg.Name, "/"); ok && strings.HasPrefix(scope, "@") {
+			name = "%40" + url.PathEscape(strings.TrimPrefix(scope, "@")) + "/" + url.PathEscape(pkgName)
+		}
+	case TypePyPI:
+		name = url.PathEscape(strings.ToLower(strings.ReplaceAll(pkg.Name, "_", "-")))
+	}
+	if pkg.Version == "" {
+		return fmt.Sprintf("pkg:%s/%s", pkg.Type, name)
+	}
+	return fmt.Sprintf("pkg:%s/%s@%s", pkg.Type, name, url.PathEscape(pkg.Version))
+}
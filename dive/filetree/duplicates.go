@@ -0,0 +1,98 @@
+package filetree
+
+import (
+	"archive/tar"
+	"sort"
+
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// DuplicateData represents a set of files with identical content that are found at different paths in the final image.
+type DuplicateData struct {
+	Paths []string
+	Nodes []*FileNode
+	// Size is the size of a single copy of the content
+	Size int64
+	// WastedSize is the number of bytes spent on all copies other than the first
+	WastedSize int64
+}
+
+// DuplicateSlice represents an ordered set of DuplicateData data structures.
+type DuplicateSlice []*DuplicateData
+
+// Len is required for sorting.
+func (dups DuplicateSlice) Len() int {
+	return len(dups)
+}
+
+// Swap operation is required for sorting.
+func (dups DuplicateSlice) Swap(i, j int) {
+	dups[i], dups[j] = dups[j], dups[i]
+}
+
+// Less comparison is required for sorting.
+func (dups DuplicateSlice) Less(i, j int) bool {
+	if dups[i].WastedSize == dups[j].WastedSize {
+		return dups[i].Paths[0] < dups[j].Paths[0]
+	}
+	return dups[i].WastedSize < dups[j].WastedSize
+}
+
+type contentKey struct {
+	hash uint64
+	size int64
+}
+
+// Duplicates returns all sets of files within the final (stacked) image that share the same content, but live at
+// different paths. Unlike Efficiency (which only considers the same path across layers) this catches content that has
+// been copied or vendored into several places. Hardlinks already share their content, so they are not considered.
+func Duplicates(trees []*FileTree) DuplicateSlice {
+	if len(trees) == 0 {
+		return DuplicateSlice{}
+	}
+
+	stackedTree, failedPaths, err := StackTreeRange(trees, 0, len(trees)-1)
+	if len(failedPaths) > 0 {
+		for _, path := range failedPaths {
+			log.WithFields("path", path.String()).Debug("unable to include path in stacked tree")
+		}
+	}
+	if err != nil {
+		log.WithFields("error", err).Debug("unable to stack trees for duplicate detection")
+		return DuplicateSlice{}
+	}
+
+	groups := make(map[contentKey]*DuplicateData)
+	visitor := func(node *FileNode) error {
+		info := node.Data.FileInfo
+		if info.IsDir || info.TypeFlag != tar.TypeReg || info.Size == 0 {
+			return nil
+		}
+
+		key := contentKey{hash: info.hash, size: info.Size}
+		if _, ok := groups[key]; !ok {
+			groups[key] = &DuplicateData{Size: info.Size}
+		}
+		data := groups[key]
+		data.Paths = append(data.Paths, node.Path())
+		data.Nodes = append(data.Nodes, node)
+		return nil
+	}
+
+	if err := stackedTree.VisitDepthChildFirst(visitor, nil); err != nil {
+		log.WithFields("error", err).Debug("unable to propagate stacked tree for duplicate detection")
+	}
+
+	duplicates := make(DuplicateSlice, 0)
+	for _, data := range groups {
+		if len(data.Paths) < 2 {
+			continue
+		}
+		data.WastedSize = data.Size * int64(len(data.Paths)-1)
+		duplicates = append(duplicates, data)
+	}
+
+	sort.Sort(duplicates)
+
+	return duplicates
+}
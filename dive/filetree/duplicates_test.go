@@ -0,0 +1,74 @@
+package filetree
+
+import (
+	"archive/tar"
+	"testing"
+)
+
+func TestDuplicates(t *testing.T) {
+	trees := make([]*FileTree, 2)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	lib := FileInfo{TypeFlag: tar.TypeReg, Size: 1000, hash: 1}
+	_, _, err := trees[0].AddPath("/usr/lib/libssl.so", lib)
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[0].AddPath("/opt/app1/libssl.so", lib)
+	checkError(t, err, "could not setup test")
+	// same hash, different size: not the same content
+	_, _, err = trees[0].AddPath("/opt/other.so", FileInfo{TypeFlag: tar.TypeReg, Size: 10, hash: 1})
+	checkError(t, err, "could not setup test")
+	// empty files are never considered
+	_, _, err = trees[0].AddPath("/etc/empty1", FileInfo{TypeFlag: tar.TypeReg, hash: 2})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[0].AddPath("/etc/empty2", FileInfo{TypeFlag: tar.TypeReg, hash: 2})
+	checkError(t, err, "could not setup test")
+
+	_, _, err = trees[1].AddPath("/opt/app2/libssl.so", lib)
+	checkError(t, err, "could not setup test")
+	// hardlinks already share content
+	_, _, err = trees[1].AddPath("/opt/app2/libssl.so.1", FileInfo{TypeFlag: tar.TypeLink, Linkname: "/opt/app2/libssl.so", hash: 1, Links: 2})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[1].AddPath("/etc/config", FileInfo{TypeFlag: tar.TypeReg, Size: 20, hash: 3})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[1].AddPath("/etc/config.bak", FileInfo{TypeFlag: tar.TypeReg, Size: 20, hash: 3})
+	checkError(t, err, "could not setup test")
+	// removing a copy in a later layer means it no longer counts
+	_, _, err = trees[1].AddPath("/usr/lib/.wh.libssl.so", FileInfo{})
+	checkError(t, err, "could not setup test")
+
+	duplicates := Duplicates(trees)
+
+	if len(duplicates) != 2 {
+		for _, dup := range duplicates {
+			t.Logf("   duplicate: %+v", dup)
+		}
+		t.Fatalf("Expected to find 2 duplicate sets, but found %d", len(duplicates))
+	}
+
+	// most wasteful sets are last
+	expected := []struct {
+		paths  []string
+		wasted int64
+	}{
+		{paths: []string{"/etc/config", "/etc/config.bak"}, wasted: 20},
+		{paths: []string{"/opt/app1/libssl.so", "/opt/app2/libssl.so"}, wasted: 1000},
+	}
+
+	for idx, exp := range expected {
+		actual := duplicates[idx]
+		if actual.WastedSize != exp.wasted {
+			t.Errorf("Expected wasted size of %d but got %d", exp.wasted, actual.WastedSize)
+		}
+		if len(actual.Paths) != len(exp.paths) {
+			t.Fatalf("Expected paths %v but got %v", exp.paths, actual.Paths)
+		}
+		for pIdx := range exp.paths {
+			if actual.Paths[pIdx] != exp.paths[pIdx] {
+				t.Errorf("Expected paths %v but got %v", exp.paths, actual.Paths)
+				break
+			}
+		}
+	}
+}
@@ -34,14 +34,35 @@ func (efs EfficiencySlice) Less(i, j int) bool {
 
 // Efficiency returns the score and file set of the given set of FileTrees (layers). This is loosely based on:
 // 1. Files that are duplicated across layers discounts your score, weighted by file size
-// 2. Files that are removed discounts your score, weighted by the original file size
+// 2. Files that are removed discounts your score, weighted by the original file size (this includes everything
+// hidden by an opaque whiteout directory)
 func Efficiency(trees []*FileTree) (float64, EfficiencySlice) {
 	efficiencyMap := make(map[string]*EfficiencyData)
 	inefficientMatches := make(EfficiencySlice, 0)
 	currentTree := 0
 
-	visitor := func(node *FileNode) error {
-		path := node.Path()
+	// the stacked tree of all layers below the current layer is only needed for whiteouts, and is reused for
+	// every whiteout found within the same layer
+	var lowerTree *FileTree
+	lowerTreeIdx := -1
+	getLowerTree := func() (*FileTree, error) {
+		if lowerTreeIdx == currentTree {
+			return lowerTree, nil
+		}
+		stackedTree, failedPaths, err := StackTreeRange(trees, 0, currentTree-1)
+		if len(failedPaths) > 0 {
+			for _, path := range failedPaths {
+				log.WithFields("path", path.String()).Debug("unable to include path in stacked tree")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to stack tree range: %w", err)
+		}
+		lowerTree, lowerTreeIdx = stackedTree, currentTree
+		return lowerTree, nil
+	}
+
+	record := func(path string, node *FileNode, sizeBytes int64) {
 		if _, ok := efficiencyMap[path]; !ok {
 			efficiencyMap[path] = &EfficiencyData{
 				Path:              path,
@@ -51,25 +72,43 @@ func Efficiency(trees []*FileTree) (float64, EfficiencySlice) {
 		}
 		data := efficiencyMap[path]
 
-		// this node may have had children that were deleted, however, we won't explicitly list out every child, only
-		// the top-most parent with the cumulative size. These operations will need to be done on the full (stacked)
-		// tree.
-		// Note: whiteout files may also represent directories, so we need to find out if this was previously a file or dir.
-		var sizeBytes int64
+		data.CumulativeSize += sizeBytes
+		if data.minDiscoveredSize < 0 || sizeBytes < data.minDiscoveredSize {
+			data.minDiscoveredSize = sizeBytes
+		}
+		data.Nodes = append(data.Nodes, node)
+
+		if len(data.Nodes) == 2 {
+			inefficientMatches = append(inefficientMatches, data)
+		}
+	}
 
-		if node.IsWhiteout() {
+	// removedSize is the number of bytes removed from the lower layers when the given (lower) node is whited out.
+	// Note: whiteout files may also represent directories, so we need to find out if this was previously a file or dir.
+	removedSize := func(previousTreeNode *FileNode) (int64, error) {
+		var sizeBytes int64
+		if previousTreeNode.Data.FileInfo.IsDir {
 			sizer := func(curNode *FileNode) error {
 				sizeBytes += curNode.Data.FileInfo.Size
 				return nil
 			}
-			stackedTree, failedPaths, err := StackTreeRange(trees, 0, currentTree-1)
-			if len(failedPaths) > 0 {
-				for _, path := range failedPaths {
-					log.WithFields("path", path.String()).Debug("unable to include path in stacked tree")
-				}
+			err := previousTreeNode.VisitDepthChildFirst(sizer, nil, nil)
+			if err != nil {
+				return 0, fmt.Errorf("unable to propagate whiteout dir: %w", err)
 			}
+		}
+		return sizeBytes, nil
+	}
+
+	visitor := func(node *FileNode) error {
+		// this node may have had children that were deleted, however, we won't explicitly list out every child, only
+		// the top-most parent with the cumulative size. These operations will need to be done on the full (stacked)
+		// tree.
+		switch {
+		case node.IsWhiteout():
+			stackedTree, err := getLowerTree()
 			if err != nil {
-				return fmt.Errorf("unable to stack tree range: %w", err)
+				return err
 			}
 
 			previousTreeNode, err := stackedTree.GetNode(node.Path())
@@ -77,24 +116,13 @@ func Efficiency(trees []*FileTree) (float64, EfficiencySlice) {
 				return err
 			}
 
-			if previousTreeNode.Data.FileInfo.IsDir {
-				err = previousTreeNode.VisitDepthChildFirst(sizer, nil, nil)
-				if err != nil {
-					return fmt.Errorf("unable to propagate whiteout dir: %w", err)
-				}
+			sizeBytes, err := removedSize(previousTreeNode)
+			if err != nil {
+				return err
 			}
-		} else {
-			sizeBytes = node.Data.FileInfo.Size
-		}
-
-		data.CumulativeSize += sizeBytes
-		if data.minDiscoveredSize < 0 || sizeBytes < data.minDiscoveredSize {
-			data.minDiscoveredSize = sizeBytes
-		}
-		data.Nodes = append(data.Nodes, node)
-
-		if len(data.Nodes) == 2 {
-			inefficientMatches = append(inefficientMatches, data)
+			record(node.Path(), node, sizeBytes)
+		default:
+			record(node.Path(), node, node.Data.FileInfo.Size)
 		}
 
 		return nil
@@ -102,9 +130,34 @@ func Efficiency(trees []*FileTree) (float64, EfficiencySlice) {
 	visitEvaluator := func(node *FileNode) bool {
 		return node.IsLeaf()
 	}
+	// an opaque directory removes every lower path that is not provided again by the current layer
+	opaqueVisitor := func(dir *FileNode) error {
+		stackedTree, err := getLowerTree()
+		if err != nil {
+			return err
+		}
+		for _, hiddenNode := range stackedTree.hiddenByOpaque(dir) {
+			sizeBytes, err := removedSize(hiddenNode)
+			if err != nil {
+				return err
+			}
+			record(hiddenNode.Path(), dir, sizeBytes)
+		}
+		return nil
+	}
 	for idx, tree := range trees {
 		currentTree = idx
-		err := tree.VisitDepthChildFirst(visitor, visitEvaluator)
+		opaqueDirs, err := tree.opaqueDirs()
+		if err != nil {
+			log.WithFields("layer", tree.Id, "error", err).Debug("unable to find opaque directories")
+		}
+		for _, dir := range opaqueDirs {
+			if err := opaqueVisitor(dir); err != nil {
+				log.WithFields("layer", tree.Id, "error", err).Debug("unable to propagate opaque directory")
+			}
+		}
+
+		err = tree.VisitDepthChildFirst(visitor, visitEvaluator)
 		if err != nil {
 			log.WithFields("layer", tree.Id, "error", err).Debug("unable to propagate layer tree")
 		}
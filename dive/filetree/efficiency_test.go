@@ -78,3 +78,42 @@ func TestEfficiency_ScratchImage(t *testing.T) {
 	}
 
 }
+
+func TestEfficiency_OpaqueWhiteout(t *testing.T) {
+	trees := make([]*FileTree, 2)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	_, _, err := trees[0].AddPath("/etc/nginx/nginx.conf", FileInfo{Size: 2000})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[0].AddPath("/etc/nginx/public", FileInfo{IsDir: true})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[0].AddPath("/etc/nginx/public/index.html", FileInfo{Size: 3000})
+	checkError(t, err, "could not setup test")
+
+	_, _, err = trees[1].AddPath("/etc/nginx/.wh..wh..opq", FileInfo{})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[1].AddPath("/etc/nginx/nginx.conf", FileInfo{Size: 1000})
+	checkError(t, err, "could not setup test")
+
+	// nginx.conf is replaced (2000+1000, min 1000), public is hidden by the opaque directory (3000 counted
+	// against both the file and the removed directory)
+	var expectedScore = 7000.0 / 9000.0
+	actualScore, actualMatches := Efficiency(trees)
+
+	if expectedScore != actualScore {
+		t.Errorf("Expected score of %v but go %v", expectedScore, actualScore)
+	}
+
+	if len(actualMatches) != 1 {
+		for _, match := range actualMatches {
+			t.Logf("   match: %+v", match)
+		}
+		t.Fatalf("Expected to find 1 inefficient path, but found %d", len(actualMatches))
+	}
+
+	if actualMatches[0].Path != "/etc/nginx/nginx.conf" {
+		t.Errorf("Expected path of /etc/nginx/nginx.conf but go %s", actualMatches[0].Path)
+	}
+}
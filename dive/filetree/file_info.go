@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/phayes/permbits"
 )
 
 // FileInfo contains tar metadata for a specific FileNode
@@ -20,21 +23,29 @@ type FileInfo struct {
 	Uid      int         `json:"uid"`
 	Gid      int         `json:"gid"`
 	IsDir    bool        `json:"isDir"`
+	Links    int         `json:"links"` // number of paths in the layer sharing this content (hardlinks), 0 if unknown
 }
 
-// NewFileInfoFromTarHeader extracts the metadata from a tar header and file contents and generates a new FileInfo object.
-func NewFileInfoFromTarHeader(reader *tar.Reader, header *tar.Header, path string) FileInfo {
+// NewFileInfoFromTarHeader extracts the metadata from a tar header and file contents (read from the given reader,
+// typically the tar reader positioned at the entry) and generates a new FileInfo object.
+func NewFileInfoFromTarHeader(reader io.Reader, header *tar.Header, path string) FileInfo {
 	var hash uint64
 	if header.Typeflag != tar.TypeDir {
 		hash = getHashFromReader(reader)
 	}
 
+	// the content of a hardlink is owned by the file it links to (see ResolveHardlinks)
+	size := header.FileInfo().Size()
+	if header.Typeflag == tar.TypeLink {
+		size = 0
+	}
+
 	return FileInfo{
 		Path:     path,
 		TypeFlag: header.Typeflag,
 		Linkname: header.Linkname,
 		hash:     hash,
-		Size:     header.FileInfo().Size(),
+		Size:     size,
 		Mode:     header.FileInfo().Mode(),
 		Uid:      header.Uid,
 		Gid:      header.Gid,
@@ -104,6 +115,131 @@ func (data *FileInfo) Copy() *FileInfo {
 		Uid:      data.Uid,
 		Gid:      data.Gid,
 		IsDir:    data.IsDir,
+		Links:    data.Links,
+	}
+}
+
+// ResolveHardlinks groups every hardlink (tar.TypeLink) with the file it refers to within the same layer. The content
+// bytes remain attributed to the link target only, while every member of a group shares the content hash of the
+// target and the number of paths in the group. Links that refer to a path outside the given set are left untouched.
+func ResolveHardlinks(files []FileInfo) {
+	key := func(p string) string {
+		return strings.TrimPrefix(path.Clean(p), "/")
+	}
+
+	index := make(map[string]int, len(files))
+	for idx, file := range files {
+		index[key(file.Path)] = idx
+	}
+
+	// find the (non-link) file that owns the content for the given link, following chained links
+	owner := func(idx int) (int, bool) {
+		seen := make(map[int]bool)
+		for files[idx].TypeFlag == tar.TypeLink {
+			if seen[idx] {
+				return 0, false
+			}
+			seen[idx] = true
+
+			next, ok := index[key(files[idx].Linkname)]
+			if !ok {
+				return 0, false
+			}
+			idx = next
+		}
+		return idx, true
+	}
+
+	groups := make(map[int][]int)
+	for idx, file := range files {
+		if file.TypeFlag != tar.TypeLink {
+			continue
+		}
+		target, ok := owner(idx)
+		if !ok {
+			continue
+		}
+		if _, exists := groups[target]; !exists {
+			groups[target] = []int{target}
+		}
+		groups[target] = append(groups[target], idx)
+	}
+
+	for target, members := range groups {
+		for _, idx := range members {
+			files[idx].Links = len(members)
+			if idx != target {
+				files[idx].hash = files[target].hash
+				files[idx].Size = 0
+			}
+		}
+	}
+}
+
+// ModeString returns the file type and permission bits in the style of "ls -l" (e.g. "drwxr-xr-x").
+func (data *FileInfo) ModeString() string {
+	dir := "-"
+	if data.IsDir {
+		dir = "d"
+	}
+
+	fm := permbits.FileMode(data.Mode)
+	var fileMode strings.Builder
+	fileMode.Grow(9)
+	cond := func(c bool, x, y byte) byte {
+		if c {
+			return x
+		} else {
+			return y
+		}
+	}
+	fileMode.WriteByte(cond(fm.UserRead(), 'r', '-'))
+	fileMode.WriteByte(cond(fm.UserWrite(), 'w', '-'))
+	fileMode.WriteByte(cond(fm.UserExecute(), cond(fm.Setuid(), 's', 'x'), cond(fm.Setuid(), 'S', '-')))
+
+	fileMode.WriteByte(cond(fm.GroupRead(), 'r', '-'))
+	fileMode.WriteByte(cond(fm.GroupWrite(), 'w', '-'))
+	fileMode.WriteByte(cond(fm.GroupExecute(), cond(fm.Setgid(), 's', 'x'), cond(fm.Setgid(), 'S', '-')))
+
+	fileMode.WriteByte(cond(fm.OtherRead(), 'r', '-'))
+	fileMode.WriteByte(cond(fm.OtherWrite(), 'w', '-'))
+	fileMode.WriteByte(cond(fm.OtherExecute(), cond(fm.Sticky(), 't', 'x'), cond(fm.Sticky(), 'T', '-')))
+
+	return dir + fileMode.String()
+}
+
+// Hash returns the xxHash64 digest of the file contents (zero for directories).
+func (data *FileInfo) Hash() uint64 {
+	return data.hash
+}
+
+// SetHash records the content digest for a FileInfo that is restored from a record of the file (e.g. an export)
+// instead of being read from the file itself.
+func (data *FileInfo) SetHash(hash uint64) {
+	data.hash = hash
+}
+
+// TypeName describes the kind of entry, using the names accepted by the "type:" query term (file, dir, symlink,
+// hardlink, char, block or fifo). Any other tar type is reported as "other".
+func (data *FileInfo) TypeName() string {
+	if data.IsDir || data.TypeFlag == tar.TypeDir {
+		return "dir"
+	}
+	switch data.TypeFlag {
+	case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck // TypeRegA may still be found in old archives
+		return "file"
+	case tar.TypeSymlink:
+		return "symlink"
+	case tar.TypeLink:
+		return "hardlink"
+	case tar.TypeChar:
+		return "char"
+	case tar.TypeBlock:
+		return "block"
+	case tar.TypeFifo:
+		return "fifo"
+	default:
+		return "other"
 	}
 }
 
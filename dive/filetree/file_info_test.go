@@ -0,0 +1,64 @@
+package filetree
+
+import (
+	"archive/tar"
+	"testing"
+)
+
+func TestResolveHardlinks(t *testing.T) {
+	files := []FileInfo{
+		{Path: "bin", TypeFlag: tar.TypeDir, IsDir: true},
+		{Path: "bin/busybox", TypeFlag: tar.TypeReg, Size: 1000, hash: 42},
+		{Path: "bin/sh", TypeFlag: tar.TypeLink, Linkname: "bin/busybox"},
+		{Path: "bin/ls", TypeFlag: tar.TypeLink, Linkname: "./bin/busybox", Size: 1000},
+		{Path: "bin/chained", TypeFlag: tar.TypeLink, Linkname: "bin/sh"},
+		{Path: "bin/dangling", TypeFlag: tar.TypeLink, Linkname: "bin/missing"},
+		{Path: "etc/hosts", TypeFlag: tar.TypeReg, Size: 10, hash: 7},
+	}
+
+	ResolveHardlinks(files)
+
+	for _, idx := range []int{1, 2, 3, 4} {
+		if files[idx].Links != 4 {
+			t.Errorf("expected %q to have 4 links, got %d", files[idx].Path, files[idx].Links)
+		}
+		if files[idx].hash != 42 {
+			t.Errorf("expected %q to share the target hash, got %d", files[idx].Path, files[idx].hash)
+		}
+	}
+
+	if files[1].Size != 1000 {
+		t.Errorf("expected the link target to keep its size, got %d", files[1].Size)
+	}
+
+	for _, idx := range []int{2, 3, 4} {
+		if files[idx].Size != 0 {
+			t.Errorf("expected %q to not be attributed any bytes, got %d", files[idx].Path, files[idx].Size)
+		}
+	}
+
+	for _, idx := range []int{0, 5, 6} {
+		if files[idx].Links != 0 {
+			t.Errorf("expected %q to not be part of a hardlink group, got %d links", files[idx].Path, files[idx].Links)
+		}
+	}
+}
+
+func TestFileInfoTypeName(t *testing.T) {
+	cases := map[string]FileInfo{
+		"dir":      {TypeFlag: tar.TypeDir, IsDir: true},
+		"file":     {TypeFlag: tar.TypeReg},
+		"symlink":  {TypeFlag: tar.TypeSymlink},
+		"hardlink": {TypeFlag: tar.TypeLink},
+		"char":     {TypeFlag: tar.TypeChar},
+		"block":    {TypeFlag: tar.TypeBlock},
+		"fifo":     {TypeFlag: tar.TypeFifo},
+		"other":    {TypeFlag: tar.TypeXGlobalHeader},
+	}
+
+	for expected, info := range cases {
+		if actual := info.TypeName(); actual != expected {
+			t.Errorf("expected type %q, got %q", expected, actual)
+		}
+	}
+}
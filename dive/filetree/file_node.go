@@ -8,11 +8,10 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
-	"github.com/phayes/permbits"
 )
 
 const (
-	AttributeFormat = "%s%s %11s %10s "
+	AttributeFormat = "%s%s %5s %11s %10s "
 )
 
 var diffTypeColor = map[DiffType]*color.Color{
@@ -30,7 +29,9 @@ type FileNode struct {
 	Name     string
 	Data     NodeData
 	Children map[string]*FileNode
-	path     string
+	// Opaque is set on directories marked by an opaque whiteout, which hides all lower contents of the directory
+	Opaque bool
+	path   string
 }
 
 // NewNode creates a new FileNode relative to the given parent node with a payload.
@@ -79,6 +80,7 @@ func (node *FileNode) Copy(parent *FileNode) *FileNode {
 	newNode := NewNode(parent, node.Name, node.Data.FileInfo)
 	newNode.Data.ViewInfo = node.Data.ViewInfo
 	newNode.Data.DiffType = node.Data.DiffType
+	newNode.Opaque = node.Opaque
 	for name, child := range node.Children {
 		newNode.Children[name] = child.Copy(newNode)
 		child.Parent = newNode
@@ -141,33 +143,12 @@ func (node *FileNode) MetadataString() string {
 		return ""
 	}
 
-	dir := "-"
-	if node.Data.FileInfo.IsDir {
-		dir = "d"
+	// only hardlinked files show a link count
+	var links string
+	if node.Data.FileInfo.Links > 1 {
+		links = fmt.Sprintf("%d", node.Data.FileInfo.Links)
 	}
 
-	fm := permbits.FileMode(node.Data.FileInfo.Mode)
-	var fileMode strings.Builder
-	fileMode.Grow(9)
-	cond := func(c bool, x, y byte) byte {
-		if c {
-			return x
-		} else {
-			return y
-		}
-	}
-	fileMode.WriteByte(cond(fm.UserRead(), 'r', '-'))
-	fileMode.WriteByte(cond(fm.UserWrite(), 'w', '-'))
-	fileMode.WriteByte(cond(fm.UserExecute(), cond(fm.Setuid(), 's', 'x'), cond(fm.Setuid(), 'S', '-')))
-
-	fileMode.WriteByte(cond(fm.GroupRead(), 'r', '-'))
-	fileMode.WriteByte(cond(fm.GroupWrite(), 'w', '-'))
-	fileMode.WriteByte(cond(fm.GroupExecute(), cond(fm.Setgid(), 's', 'x'), cond(fm.Setgid(), 'S', '-')))
-
-	fileMode.WriteByte(cond(fm.OtherRead(), 'r', '-'))
-	fileMode.WriteByte(cond(fm.OtherWrite(), 'w', '-'))
-	fileMode.WriteByte(cond(fm.OtherExecute(), cond(fm.Sticky(), 't', 'x'), cond(fm.Sticky(), 'T', '-')))
-
 	user := node.Data.FileInfo.Uid
 	group := node.Data.FileInfo.Gid
 	userGroup := fmt.Sprintf("%d:%d", user, group)
@@ -178,7 +159,7 @@ func (node *FileNode) MetadataString() string {
 
 	size := humanize.Bytes(uint64(sizeBytes))
 
-	return diffTypeColor[node.Data.DiffType].Sprint(fmt.Sprintf(AttributeFormat, dir, fileMode.String(), userGroup, size))
+	return diffTypeColor[node.Data.DiffType].Sprint(fmt.Sprintf(AttributeFormat, "", node.Data.FileInfo.ModeString(), links, userGroup, size))
 }
 
 func (node *FileNode) GetSize() int64 {
@@ -1,6 +1,7 @@
 package filetree
 
 import (
+	"archive/tar"
 	"testing"
 )
 
@@ -161,7 +162,33 @@ func TestDirSize(t *testing.T) {
 	checkError(t, err, "unable to setup test")
 
 	node, _ := tree1.GetNode("/etc/nginx")
-	expected, actual := "----------         0:0      600 B ", node.MetadataString()
+	expected, actual := "----------               0:0      600 B ", node.MetadataString()
+	if expected != actual {
+		t.Errorf("Expected metadata '%s' got '%s'", expected, actual)
+	}
+}
+
+func TestDirSizeWithHardlinks(t *testing.T) {
+	files := []FileInfo{
+		{Path: "/bin/busybox", TypeFlag: tar.TypeReg, Size: 1000, Mode: 0755},
+		{Path: "/bin/sh", TypeFlag: tar.TypeLink, Linkname: "/bin/busybox"},
+		{Path: "/bin/ls", TypeFlag: tar.TypeLink, Linkname: "/bin/busybox"},
+	}
+	ResolveHardlinks(files)
+
+	tree := NewFileTree()
+	for _, file := range files {
+		_, _, err := tree.AddPath(file.Path, file)
+		checkError(t, err, "unable to setup test")
+	}
+
+	node, _ := tree.GetNode("/bin")
+	if node.GetSize() != 1000 {
+		t.Errorf("Expected hardlinked content to be counted once, got %d bytes", node.GetSize())
+	}
+
+	node, _ = tree.GetNode("/bin/sh")
+	expected, actual := "----------     3         0:0        0 B ", node.MetadataString()
 	if expected != actual {
 		t.Errorf("Expected metadata '%s' got '%s'", expected, actual)
 	}
@@ -17,6 +17,7 @@ const (
 	lastItem             = "└─"
 	whiteoutPrefix       = ".wh."
 	doubleWhiteoutPrefix = ".wh..wh.."
+	opaqueWhiteout       = ".wh..wh..opq"
 	uncollapsedItem      = "─ "
 	collapsedItem        = "⊕ "
 )
@@ -206,6 +207,19 @@ func (tree *FileTree) VisitDepthParentFirst(visitor Visitor, evaluator VisitEval
 
 // Stack takes two trees and combines them together. This is done by "stacking" the given tree on top of the owning tree.
 func (tree *FileTree) Stack(upper *FileTree) (failed []PathError, stackErr error) {
+	// opaque directories must hide the lower contents before grafting, since the upper tree may re-add paths within
+	// the same directory.
+	hidden, stackErr := tree.opaqueHidden(upper)
+	if stackErr != nil {
+		return failed, stackErr
+	}
+	for _, node := range hidden {
+		err := node.Remove()
+		if err != nil {
+			failed = append(failed, NewPathError(node.Path(), ActionRemove, err))
+		}
+	}
+
 	graft := func(node *FileNode) error {
 		if node.IsWhiteout() {
 			err := tree.RemovePath(node.Path())
@@ -257,8 +271,11 @@ func (tree *FileTree) AddPath(filepath string, data FileInfo) (*FileNode, []*Fil
 		if node.Children[name] != nil {
 			node = node.Children[name]
 		} else {
-			// don't add paths that should be deleted
+			// don't add paths that should be deleted (an opaque marker flags its directory instead, see FileNode.Opaque)
 			if strings.HasPrefix(name, doubleWhiteoutPrefix) {
+				if name == opaqueWhiteout {
+					node.Opaque = true
+				}
 				return nil, addedNodes, nil
 			}
 
@@ -305,6 +322,18 @@ func (tree *FileTree) CompareAndMark(upper *FileTree) ([]PathError, error) {
 	modifications := make([]compareMark, 0)
 	failed := make([]PathError, 0)
 
+	// lower content hidden by an opaque directory in the upper tree is removed (unless the upper tree provides it again)
+	hidden, err := tree.opaqueHidden(upper)
+	if err != nil {
+		return failed, err
+	}
+	for _, node := range hidden {
+		err = node.AssignDiffType(Removed)
+		if err != nil {
+			return failed, err
+		}
+	}
+
 	graft := func(upperNode *FileNode) error {
 		if upperNode.IsWhiteout() {
 			err := tree.markRemoved(upperNode.Path())
@@ -339,7 +368,7 @@ func (tree *FileTree) CompareAndMark(upper *FileTree) ([]PathError, error) {
 		return nil
 	}
 	// we must visit from the leaves upwards to ensure that diff types can be derived from and assigned to children
-	err := upper.VisitDepthChildFirst(graft, nil)
+	err = upper.VisitDepthChildFirst(graft, nil)
 	if err != nil {
 		return failed, err
 	}
@@ -373,6 +402,70 @@ func (tree *FileTree) markRemoved(path string) error {
 	return node.AssignDiffType(Removed)
 }
 
+// opaqueDirs returns the directories of the tree that are marked as opaque (including the root).
+func (tree *FileTree) opaqueDirs() ([]*FileNode, error) {
+	var dirs []*FileNode
+	if tree.Root.Opaque {
+		dirs = append(dirs, tree.Root)
+	}
+	visitor := func(node *FileNode) error {
+		dirs = append(dirs, node)
+		return nil
+	}
+	evaluator := func(node *FileNode) bool {
+		return node.Opaque
+	}
+	err := tree.VisitDepthChildFirst(visitor, evaluator)
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// opaqueHidden returns the nodes within the owning (lower) tree that are hidden by the opaque directories of the given
+// (upper) tree. Only the top-most node of each hidden subtree is returned.
+func (tree *FileTree) opaqueHidden(upper *FileTree) ([]*FileNode, error) {
+	dirs, err := upper.opaqueDirs()
+	if err != nil {
+		return nil, err
+	}
+	var hidden []*FileNode
+	for _, dir := range dirs {
+		hidden = append(hidden, tree.hiddenByOpaque(dir)...)
+	}
+	return hidden, nil
+}
+
+// hiddenByOpaque returns the nodes within the owning (lower) tree that are hidden by the given opaque directory from
+// the upper tree. Per the OCI image spec, all lower contents of the marked directory are hidden, except for the paths
+// that the upper tree provides itself. Paths that are explicitly whited out by the upper tree are left to the regular
+// whiteout handling.
+func (tree *FileTree) hiddenByOpaque(upperDir *FileNode) []*FileNode {
+	lowerDir, err := tree.GetNode(upperDir.Path())
+	if err != nil {
+		// the directory does not exist in the lower tree, so there is nothing to hide
+		return nil
+	}
+
+	var hidden []*FileNode
+	var collect func(lowerDir, upperDir *FileNode)
+	collect = func(lowerDir, upperDir *FileNode) {
+		for _, name := range GetSortOrderStrategy(ByName).orderKeys(lowerDir.Children) {
+			if upperChild, exists := upperDir.Children[name]; exists {
+				collect(lowerDir.Children[name], upperChild)
+				continue
+			}
+			if _, exists := upperDir.Children[whiteoutPrefix+name]; exists {
+				continue
+			}
+			hidden = append(hidden, lowerDir.Children[name])
+		}
+	}
+	collect(lowerDir, upperDir)
+
+	return hidden
+}
+
 // StackTreeRange combines an array of trees into a single tree
 func StackTreeRange(trees []*FileTree, start, stop int) (*FileTree, []PathError, error) {
 	errors := make([]PathError, 0)
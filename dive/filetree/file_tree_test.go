@@ -333,7 +333,7 @@ func TestStack(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not setup test: %v", err)
 	}
-	// ignore opaque whiteout files entirely
+	// opaque whiteout files never become nodes, they mark the directory holding them instead
 	node, _, err := tree2.AddPath("/.wh..wh..opq", FileInfo{})
 	if err != nil {
 		t.Errorf("expected no error on whiteout file add, but got %v", err)
@@ -341,6 +341,9 @@ func TestStack(t *testing.T) {
 	if node != nil {
 		t.Errorf("expected no node on whiteout file add, but got %v", node)
 	}
+	if !tree2.Root.Opaque {
+		t.Errorf("expected the root to be marked as opaque")
+	}
 
 	failedPaths, err := tree1.Stack(tree2)
 
@@ -352,11 +355,11 @@ func TestStack(t *testing.T) {
 		t.Errorf("expected no filepath errors, got %d", len(failedPaths))
 	}
 
+	// the opaque root hides the lower paths that are not provided again (the public directory)
 	expected :=
 		`├── etc
 │   └── nginx
-│       ├── nginx.conf
-│       └── public
+│       └── nginx.conf
 └── var
     └── run
         └── systemd
@@ -379,6 +382,43 @@ func TestStack(t *testing.T) {
 
 }
 
+func TestStackOpaqueWhiteout(t *testing.T) {
+	lower := NewFileTree()
+	for _, p := range []string{"/etc/nginx/nginx.conf", "/etc/nginx/conf.d/default.conf", "/etc/nginx/public/index.html", "/etc/hosts", "/var/run/systemd"} {
+		_, _, err := lower.AddPath(p, FileInfo{})
+		checkError(t, err, "could not setup test")
+	}
+
+	upper := NewFileTree()
+	for _, p := range []string{"/etc/nginx/.wh..wh..opq", "/etc/nginx/nginx.conf", "/etc/nginx/public/other.html", "/etc/nginx/.wh.conf.d"} {
+		_, _, err := upper.AddPath(p, FileInfo{})
+		checkError(t, err, "could not setup test")
+	}
+
+	failedPaths, err := lower.Stack(upper)
+	checkError(t, err, "could not stack trees")
+	if len(failedPaths) > 0 {
+		t.Errorf("expected no filepath errors, got %+v", failedPaths)
+	}
+
+	expected :=
+		`├── etc
+│   ├── hosts
+│   └── nginx
+│       ├── nginx.conf
+│       └── public
+│           └── other.html
+└── var
+    └── run
+        └── systemd
+`
+	actual := lower.String(false)
+
+	if expected != actual {
+		t.Errorf("Expected tree string:\n--->%s<---\nGot:\n--->%s<---", expected, actual)
+	}
+}
+
 func TestCopy(t *testing.T) {
 	tree := NewFileTree()
 	_, _, err := tree.AddPath("/etc/nginx/nginx.conf", FileInfo{})
@@ -730,6 +770,58 @@ func TestCompareWithRemoves(t *testing.T) {
 	}
 }
 
+func TestCompareWithOpaqueWhiteout(t *testing.T) {
+	lowerTree := NewFileTree()
+	upperTree := NewFileTree()
+	lowerPaths := [...]string{"/etc/hosts", "/root/example/some1", "/root/example/some2", "/root/other"}
+	upperPaths := [...]string{"/root/.wh..wh..opq", "/root/example/some1"}
+
+	for _, value := range lowerPaths {
+		_, _, err := lowerTree.AddPath(value, FileInfo{Path: value, TypeFlag: 1, hash: 123})
+		checkError(t, err, "could not setup test")
+	}
+
+	for _, value := range upperPaths {
+		_, _, err := upperTree.AddPath(value, FileInfo{Path: value, TypeFlag: 1, hash: 123})
+		checkError(t, err, "could not setup test")
+	}
+
+	failedPaths, err := lowerTree.CompareAndMark(upperTree)
+	checkError(t, err, "could not setup test")
+	if len(failedPaths) > 0 {
+		t.Errorf("expected no filepath errors, got %d", len(failedPaths))
+	}
+
+	failedAssertions := []error{}
+	asserter := func(n *FileNode) error {
+		p := n.Path()
+		if p == "/" {
+			return nil
+		} else if stringInSlice(p, []string{"/root/example/some2", "/root/other"}) {
+			if err := AssertDiffType(n, Removed); err != nil {
+				failedAssertions = append(failedAssertions, err)
+			}
+		} else if stringInSlice(p, []string{"/root", "/root/example"}) {
+			if err := AssertDiffType(n, Modified); err != nil {
+				failedAssertions = append(failedAssertions, err)
+			}
+		} else {
+			if err := AssertDiffType(n, Unmodified); err != nil {
+				failedAssertions = append(failedAssertions, err)
+			}
+		}
+		return nil
+	}
+	err = lowerTree.VisitDepthChildFirst(asserter, nil)
+	if err != nil {
+		t.Errorf("Expected no errors when visiting nodes, got: %+v", err)
+	}
+
+	for _, value := range failedAssertions {
+		t.Errorf("  - %s", value.Error())
+	}
+}
+
 func TestStackRange(t *testing.T) {
 	tree := NewFileTree()
 	_, _, err := tree.AddPath("/etc/nginx/nginx.conf", FileInfo{})
@@ -0,0 +1,154 @@
+package filetree
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	HistoryAdded HistoryChange = iota
+	HistoryModified
+	HistoryChmodded
+	HistoryUnmodified
+	HistoryRemoved
+)
+
+// HistoryChange describes what a single layer did to a path.
+type HistoryChange int
+
+// String of a HistoryChange
+func (change HistoryChange) String() string {
+	switch change {
+	case HistoryAdded:
+		return "Added"
+	case HistoryModified:
+		return "Modified"
+	case HistoryChmodded:
+		return "Chmodded"
+	case HistoryUnmodified:
+		return "Unmodified"
+	case HistoryRemoved:
+		return "Removed"
+	default:
+		return fmt.Sprintf("%d", int(change))
+	}
+}
+
+// HistoryEntry is the state of a path right after a layer touched it.
+type HistoryEntry struct {
+	Layer  int
+	Change HistoryChange
+	// Info is the state of the path after the change (or, when removed, the state that was removed)
+	Info FileInfo
+	// Hash is the content hash of the file (0 for directories)
+	Hash uint64
+	// Size is the file size, or the size of everything the layer wrote beneath a directory
+	Size int64
+}
+
+// History returns every layer (in order) that added, modified, chmodded, rewrote or removed the given path, including
+// removals caused by a whiteout or opaque directory on any of its parents. Directories are considered modified by any
+// layer that writes beneath them.
+func History(trees []*FileTree, nodePath string) []HistoryEntry {
+	nodePath = path.Clean("/" + strings.Trim(nodePath, "/"))
+
+	entries := make([]HistoryEntry, 0)
+	var current *HistoryEntry
+
+	for idx, tree := range trees {
+		removed := isRemovedIn(tree, nodePath)
+		node, _ := tree.GetNode(nodePath)
+		if nodePath == "/" {
+			node, removed = nil, false
+		}
+
+		if node == nil {
+			if removed && current != nil {
+				entry := *current
+				entry.Layer, entry.Change = idx, HistoryRemoved
+				entries = append(entries, entry)
+				current = nil
+			}
+			continue
+		}
+
+		entry := HistoryEntry{Layer: idx, Info: node.Data.FileInfo, Hash: node.Data.FileInfo.hash}
+		explicit := entry.Info.Path != ""
+		isDir := entry.Info.IsDir || !explicit
+		if !explicit {
+			// the layer only wrote beneath this directory, the directory entry itself is unchanged
+			entry.Info = FileInfo{Path: nodePath, IsDir: true}
+			if current != nil {
+				entry.Info = current.Info
+			}
+		}
+
+		if isDir {
+			entry.Hash = 0
+			entry.Size = node.GetSize()
+		} else {
+			entry.Size = entry.Info.Size
+		}
+
+		switch {
+		case current == nil:
+			entry.Change = HistoryAdded
+		case removed:
+			// removed by an opaque parent and written again within the same layer
+			entry.Change = HistoryModified
+		default:
+			entry.Change = compareHistory(current, &entry, explicit, isDir && len(node.Children) > 0)
+		}
+
+		entries = append(entries, entry)
+		current = &entries[len(entries)-1]
+	}
+
+	return entries
+}
+
+// compareHistory determines how the given entry changed the previous state of the same path.
+func compareHistory(previous, entry *HistoryEntry, explicit, wroteChildren bool) HistoryChange {
+	before, after := previous.Info, entry.Info
+	if explicit && (before.TypeFlag != after.TypeFlag || before.IsDir != after.IsDir || before.Linkname != after.Linkname) {
+		return HistoryModified
+	}
+	if !after.IsDir && explicit && before.hash != after.hash {
+		return HistoryModified
+	}
+	if explicit && (before.Mode != after.Mode || before.Uid != after.Uid || before.Gid != after.Gid) {
+		return HistoryChmodded
+	}
+	if wroteChildren {
+		return HistoryModified
+	}
+	return HistoryUnmodified
+}
+
+// isRemovedIn indicates if the given layer tree removes the path, either by a whiteout of the path (or any parent), an
+// opaque directory marker on any parent, or by replacing any parent with something other than a directory.
+func isRemovedIn(tree *FileTree, nodePath string) bool {
+	names := strings.Split(strings.Trim(nodePath, "/"), "/")
+	node := tree.Root
+	for idx, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, exists := node.Children[whiteoutPrefix+name]; exists {
+			return true
+		}
+		if node.Opaque {
+			return true
+		}
+		child, exists := node.Children[name]
+		if !exists {
+			return false
+		}
+		if idx < len(names)-1 && child.Data.FileInfo.Path != "" && !child.Data.FileInfo.IsDir {
+			return true
+		}
+		node = child
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package filetree
+
+import (
+	"archive/tar"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	trees := make([]*FileTree, 6)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	lib := FileInfo{Path: "/usr/lib/libfoo.so", TypeFlag: tar.TypeReg, Size: 100, Mode: 0644, hash: 1}
+
+	// 0: added
+	_, _, err := trees[0].AddPath("/usr/lib/libfoo.so", lib)
+	checkError(t, err, "could not setup test")
+
+	// 1: content changed
+	modified := lib
+	modified.Size, modified.hash = 200, 2
+	_, _, err = trees[1].AddPath("/usr/lib/libfoo.so", modified)
+	checkError(t, err, "could not setup test")
+
+	// 2: untouched
+	_, _, err = trees[2].AddPath("/etc/hosts", FileInfo{Path: "/etc/hosts", TypeFlag: tar.TypeReg})
+	checkError(t, err, "could not setup test")
+
+	// 3: mode changed only
+	chmodded := modified
+	chmodded.Mode, chmodded.Uid = 0755, 1000
+	_, _, err = trees[3].AddPath("/usr/lib/libfoo.so", chmodded)
+	checkError(t, err, "could not setup test")
+
+	// 4: removed by an opaque parent
+	_, _, err = trees[4].AddPath("/usr/lib/.wh..wh..opq", *BlankFileChangeInfo("/usr/lib/.wh..wh..opq"))
+	checkError(t, err, "could not setup test")
+
+	// 5: added back
+	_, _, err = trees[5].AddPath("/usr/lib/libfoo.so", lib)
+	checkError(t, err, "could not setup test")
+
+	expected := []struct {
+		layer  int
+		change HistoryChange
+		size   int64
+	}{
+		{layer: 0, change: HistoryAdded, size: 100},
+		{layer: 1, change: HistoryModified, size: 200},
+		{layer: 3, change: HistoryChmodded, size: 200},
+		{layer: 4, change: HistoryRemoved, size: 200},
+		{layer: 5, change: HistoryAdded, size: 100},
+	}
+
+	actual := History(trees, "/usr/lib/libfoo.so")
+	if len(actual) != len(expected) {
+		for _, entry := range actual {
+			t.Logf("   entry: %+v", entry)
+		}
+		t.Fatalf("Expected %d history entries, but found %d", len(expected), len(actual))
+	}
+
+	for idx, entry := range actual {
+		if entry.Layer != expected[idx].layer || entry.Change != expected[idx].change || entry.Size != expected[idx].size {
+			t.Errorf("Expected entry %d to be %+v, but got layer=%d change=%s size=%d", idx, expected[idx], entry.Layer, entry.Change, entry.Size)
+		}
+	}
+
+	if actual[2].Info.Mode != 0755 || actual[2].Info.Uid != 1000 {
+		t.Errorf("Expected chmodded entry to carry the new mode and owner, got %+v", actual[2].Info)
+	}
+}
+
+func TestHistory_Directory(t *testing.T) {
+	trees := make([]*FileTree, 3)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	_, _, err := trees[0].AddPath("/app", FileInfo{Path: "/app", TypeFlag: tar.TypeDir, IsDir: true, Mode: 0755})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[1].AddPath("/app/main", FileInfo{Path: "/app/main", TypeFlag: tar.TypeReg, Size: 10})
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[2].AddPath("/.wh.app", *BlankFileChangeInfo("/.wh.app"))
+	checkError(t, err, "could not setup test")
+
+	actual := History(trees, "/app/")
+	expected := []HistoryChange{HistoryAdded, HistoryModified, HistoryRemoved}
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %d history entries, but found %d: %+v", len(expected), len(actual), actual)
+	}
+	for idx, entry := range actual {
+		if entry.Layer != idx || entry.Change != expected[idx] {
+			t.Errorf("Expected entry %d to be %s in layer %d, but got %s in layer %d", idx, expected[idx], idx, entry.Change, entry.Layer)
+		}
+	}
+	if actual[1].Size != 10 {
+		t.Errorf("Expected the directory modification to account for 10 bytes, got %d", actual[1].Size)
+	}
+}
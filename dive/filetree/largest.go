@@ -0,0 +1,88 @@
+package filetree
+
+import (
+	"sort"
+
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// LargestPath is a single file or directory ranked by its size.
+type LargestPath struct {
+	Path string
+	// Size is the file size, or the size of everything beneath a directory (see FileNode.GetSize)
+	Size  int64
+	IsDir bool
+}
+
+// LargestPaths holds the largest files and the largest directories within a tree (largest first).
+type LargestPaths struct {
+	Files       []LargestPath
+	Directories []LargestPath
+}
+
+// Largest ranks every file and directory within the given tree by size, keeping the n largest of each (empty files and
+// directories are left out). Unlike sorting the tree by size (which only orders siblings) this gives a flat view over
+// all depths. For a single layer tree the size of a directory is the size of everything the layer wrote beneath it.
+func Largest(tree *FileTree, n int) LargestPaths {
+	var result LargestPaths
+	if tree == nil || n <= 0 {
+		return result
+	}
+
+	err := tree.VisitDepthParentFirst(func(node *FileNode) error {
+		if node.IsWhiteout() || node.GetSize() == 0 {
+			return nil
+		}
+		entry := LargestPath{
+			Path:  node.Path(),
+			Size:  node.GetSize(),
+			IsDir: node.Data.FileInfo.IsDir || len(node.Children) > 0,
+		}
+		if entry.IsDir {
+			result.Directories = append(result.Directories, entry)
+		} else {
+			result.Files = append(result.Files, entry)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		log.WithFields("error", err).Debug("unable to visit tree to rank paths by size")
+	}
+
+	result.Files = topLargest(result.Files, n)
+	result.Directories = topLargest(result.Directories, n)
+	return result
+}
+
+// LargestInImage ranks the files and directories of the final (stacked) image by size, keeping the n largest of each.
+func LargestInImage(trees []*FileTree, n int) LargestPaths {
+	if len(trees) == 0 {
+		return LargestPaths{}
+	}
+
+	stackedTree, failedPaths, err := StackTreeRange(trees, 0, len(trees)-1)
+	if len(failedPaths) > 0 {
+		for _, path := range failedPaths {
+			log.WithFields("path", path.String()).Debug("unable to include path in stacked tree")
+		}
+	}
+	if err != nil {
+		log.WithFields("error", err).Debug("unable to stack trees to rank paths by size")
+		return LargestPaths{}
+	}
+
+	return Largest(stackedTree, n)
+}
+
+func topLargest(entries []LargestPath, n int) []LargestPath {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Size == entries[j].Size {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Size > entries[j].Size
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
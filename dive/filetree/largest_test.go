@@ -0,0 +1,68 @@
+package filetree
+
+import (
+	"archive/tar"
+	"testing"
+)
+
+func TestLargest(t *testing.T) {
+	trees := make([]*FileTree, 2)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	lower := []FileInfo{
+		{Path: "/usr/lib/libbig.so", TypeFlag: tar.TypeReg, Size: 5000},
+		{Path: "/usr/lib/libsmall.so", TypeFlag: tar.TypeReg, Size: 100},
+		{Path: "/usr/bin/tool", TypeFlag: tar.TypeReg, Size: 700},
+		{Path: "/etc/config", TypeFlag: tar.TypeReg, Size: 10},
+	}
+	for _, info := range lower {
+		_, _, err := trees[0].AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+	}
+
+	upper := []FileInfo{
+		{Path: "/app/data.bin", TypeFlag: tar.TypeReg, Size: 3000},
+		{Path: "/usr/lib/.wh.libbig.so", TypeFlag: tar.TypeReg},
+	}
+	for _, info := range upper {
+		_, _, err := trees[1].AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+	}
+
+	type entry struct {
+		path string
+		size int64
+	}
+
+	check := func(t *testing.T, kind string, expected []entry, actual []LargestPath) {
+		t.Helper()
+		if len(actual) != len(expected) {
+			t.Fatalf("expected %d %s, got %+v", len(expected), kind, actual)
+		}
+		for idx := range expected {
+			if actual[idx].Path != expected[idx].path || actual[idx].Size != expected[idx].size {
+				t.Errorf("%s %d: expected %+v, got %+v", kind, idx, expected[idx], actual[idx])
+			}
+		}
+	}
+
+	t.Run("layer", func(t *testing.T) {
+		actual := Largest(trees[0], 2)
+		check(t, "files", []entry{{"/usr/lib/libbig.so", 5000}, {"/usr/bin/tool", 700}}, actual.Files)
+		check(t, "directories", []entry{{"/usr", 5800}, {"/usr/lib", 5100}}, actual.Directories)
+	})
+
+	t.Run("layer ignores whiteouts and empty paths", func(t *testing.T) {
+		actual := Largest(trees[1], 5)
+		check(t, "files", []entry{{"/app/data.bin", 3000}}, actual.Files)
+		check(t, "directories", []entry{{"/app", 3000}}, actual.Directories)
+	})
+
+	t.Run("image", func(t *testing.T) {
+		actual := LargestInImage(trees, 3)
+		check(t, "files", []entry{{"/app/data.bin", 3000}, {"/usr/bin/tool", 700}, {"/usr/lib/libsmall.so", 100}}, actual.Files)
+		check(t, "directories", []entry{{"/app", 3000}, {"/usr", 800}, {"/usr/bin", 700}}, actual.Directories)
+	})
+}
@@ -0,0 +1,176 @@
+package filetree
+
+import (
+	"path"
+
+	"github.com/wagoodman/dive/internal/log"
+)
+
+// LayerStat summarizes the changes a single layer makes to the layers beneath it, and how much of what it writes is
+// replaced or removed by the layers above it. Counts are of files (any entry other than a directory).
+type LayerStat struct {
+	// Added is the number of files the layer writes at paths not found in any lower layer
+	Added int
+	// Modified is the number of lower files the layer replaces with different contents, type, mode or owner
+	Modified int
+	// Removed is the number of lower files the layer removes (by a whiteout or opaque directory)
+	Removed int
+	// RemovedBytes is the size of the lower files the layer removes
+	RemovedBytes int64
+	// OverwrittenBytes is the size of the files written by the layer that a later layer replaces or removes, which
+	// remain stored in the image without being visible in it
+	OverwrittenBytes int64
+}
+
+// LayerStats compares every layer with the stack of all layers beneath it (and with the layers above it), giving a
+// per layer summary of the same changes the file tree shows.
+func LayerStats(trees []*FileTree) []LayerStat {
+	stats := make([]LayerStat, len(trees))
+	overwritten := overwrittenBytes(trees)
+	lower := NewFileTree()
+
+	for idx, tree := range trees {
+		stat := &stats[idx]
+		stat.OverwrittenBytes = overwritten[idx]
+
+		// the lower files hidden by a whiteout (or an opaque directory) are removed by this layer
+		removed := func(node *FileNode) {
+			_ = node.VisitDepthChildFirst(func(n *FileNode) error {
+				if !n.Data.FileInfo.IsDir && n.Data.FileInfo.Path != "" {
+					stat.Removed++
+					stat.RemovedBytes += n.Data.FileInfo.Size
+				}
+				return nil
+			}, nil, nil)
+		}
+
+		hidden, err := lower.opaqueHidden(tree)
+		if err != nil {
+			log.WithFields("layer", tree.Id, "error", err).Debug("unable to find opaque directories")
+		}
+		for _, node := range hidden {
+			removed(node)
+		}
+
+		err = tree.VisitDepthChildFirst(func(node *FileNode) error {
+			info := node.Data.FileInfo
+			switch {
+			case node == tree.Root:
+			case node.IsWhiteout():
+				if previous, _ := lower.GetNode(node.Path()); previous != nil {
+					removed(previous)
+				}
+			case info.Path == "" || info.IsDir:
+			default:
+				previous, _ := lower.GetNode(node.Path())
+				switch {
+				case previous == nil || previous.Data.FileInfo.Path == "":
+					stat.Added++
+				case previous.Data.FileInfo.Compare(info) != Unmodified:
+					stat.Modified++
+				}
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			log.WithFields("layer", tree.Id, "error", err).Debug("unable to summarize layer tree")
+		}
+
+		if _, err := lower.Stack(tree); err != nil {
+			log.WithFields("layer", tree.Id, "error", err).Debug("unable to stack layer tree")
+		}
+	}
+
+	return stats
+}
+
+// overwrittenBytes gives the size of the files each layer writes that a layer above it writes again or removes. The
+// layers are walked from the top down, keeping track of the paths written or removed above the current layer.
+func overwrittenBytes(trees []*FileTree) []int64 {
+	sizes := make([]int64, len(trees))
+	above := newUpperPaths()
+
+	for idx := len(trees) - 1; idx >= 0; idx-- {
+		tree := trees[idx]
+		err := tree.VisitDepthChildFirst(func(node *FileNode) error {
+			info := node.Data.FileInfo
+			if node == tree.Root || node.IsWhiteout() || info.Path == "" || info.IsDir {
+				return nil
+			}
+			if above.hides(node.Path()) {
+				sizes[idx] += info.Size
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			log.WithFields("layer", tree.Id, "error", err).Debug("unable to summarize overwritten files")
+		}
+
+		above.add(tree)
+	}
+
+	return sizes
+}
+
+// upperPaths is the set of paths written or removed by the layers above a layer.
+type upperPaths struct {
+	// written are the paths of the entries written, and whether each is a file (which replaces anything beneath it)
+	written map[string]bool
+	// removed are the paths removed by a whiteout (along with anything beneath them)
+	removed map[string]bool
+	// cleared are the directories whose lower contents are removed by an opaque whiteout
+	cleared map[string]bool
+}
+
+func newUpperPaths() *upperPaths {
+	return &upperPaths{
+		written: make(map[string]bool),
+		removed: make(map[string]bool),
+		cleared: make(map[string]bool),
+	}
+}
+
+// add records the paths written or removed by the given layer.
+func (u *upperPaths) add(tree *FileTree) {
+	dirs, err := tree.opaqueDirs()
+	if err != nil {
+		log.WithFields("layer", tree.Id, "error", err).Debug("unable to find opaque directories")
+	}
+	for _, dir := range dirs {
+		u.cleared[dir.Path()] = true
+	}
+
+	err = tree.VisitDepthChildFirst(func(node *FileNode) error {
+		info := node.Data.FileInfo
+		switch {
+		case node == tree.Root:
+		case node.IsWhiteout():
+			u.removed[node.Path()] = true
+		case info.Path != "":
+			u.written[node.Path()] = u.written[node.Path()] || !info.IsDir
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		log.WithFields("layer", tree.Id, "error", err).Debug("unable to record upper layer paths")
+	}
+}
+
+// hides indicates if the path is written or removed by the recorded layers, either directly or through one of its
+// parent directories.
+func (u *upperPaths) hides(nodePath string) bool {
+	if _, ok := u.written[nodePath]; ok || u.removed[nodePath] {
+		return true
+	}
+	for dir := path.Dir(nodePath); ; dir = path.Dir(dir) {
+		if u.cleared[dir] {
+			return true
+		}
+		if dir == "/" || dir == "." {
+			return false
+		}
+		if u.removed[dir] || u.written[dir] {
+			return true
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package filetree
+
+import (
+	"archive/tar"
+	"testing"
+)
+
+func TestLayerStats(t *testing.T) {
+	layers := [][]FileInfo{
+		{
+			{Path: "/usr/lib/libbig.so", TypeFlag: tar.TypeReg, Size: 5000, hash: 1},
+			{Path: "/usr/lib/libsmall.so", TypeFlag: tar.TypeReg, Size: 100, hash: 2},
+			{Path: "/etc/config", TypeFlag: tar.TypeReg, Size: 10, hash: 3},
+			{Path: "/tmp/cache/a", TypeFlag: tar.TypeReg, Size: 300, hash: 4},
+			{Path: "/tmp/cache/b", TypeFlag: tar.TypeReg, Size: 200, hash: 5},
+		},
+		{
+			// modified, rewritten unchanged, added
+			{Path: "/etc/config", TypeFlag: tar.TypeReg, Size: 20, hash: 6},
+			{Path: "/usr/lib/libsmall.so", TypeFlag: tar.TypeReg, Size: 100, hash: 2},
+			{Path: "/app/data.bin", TypeFlag: tar.TypeReg, Size: 3000, hash: 7},
+		},
+		{
+			// removes a file and everything beneath a directory
+			{Path: "/usr/lib/.wh.libbig.so", TypeFlag: tar.TypeReg},
+			{Path: "/tmp/cache/.wh..wh..opq", TypeFlag: tar.TypeReg},
+			{Path: "/tmp/cache/b", TypeFlag: tar.TypeReg, Size: 50, hash: 8},
+		},
+	}
+
+	trees := make([]*FileTree, len(layers))
+	for idx, infos := range layers {
+		trees[idx] = NewFileTree()
+		for _, info := range infos {
+			_, _, err := trees[idx].AddPath(info.Path, info)
+			checkError(t, err, "could not setup test")
+		}
+	}
+
+	expected := []LayerStat{
+		// every file is replaced or removed by a later layer
+		{Added: 5, OverwrittenBytes: 5000 + 100 + 10 + 300 + 200},
+		{Added: 1, Modified: 1},
+		// the opaque directory removes a (b is written again by the layer), the whiteout removes libbig
+		{Added: 0, Modified: 1, Removed: 2, RemovedBytes: 5000 + 300},
+	}
+
+	actual := LayerStats(trees)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d layers, got %d", len(expected), len(actual))
+	}
+	for idx := range expected {
+		if actual[idx] != expected[idx] {
+			t.Errorf("layer %d: expected %+v, got %+v", idx, expected[idx], actual[idx])
+		}
+	}
+}
+
+func TestLayerStats_OverwrittenThroughParents(t *testing.T) {
+	layers := [][]FileInfo{
+		{
+			{Path: "/opt/tool/bin/run", TypeFlag: tar.TypeReg, Size: 100, hash: 1},
+			{Path: "/srv/www/index.html", TypeFlag: tar.TypeReg, Size: 10, hash: 2},
+			{Path: "/var/log/app.log", TypeFlag: tar.TypeReg, Size: 1, hash: 3},
+			{Path: "/home/app/.profile", TypeFlag: tar.TypeReg, Size: 1000, hash: 4},
+		},
+		{
+			// a file replaces a directory, and a whiteout removes a whole directory
+			{Path: "/opt/tool", TypeFlag: tar.TypeSymlink, Linkname: "/usr/local/tool"},
+			{Path: "/srv/.wh.www", TypeFlag: tar.TypeReg},
+		},
+		{
+			// an opaque directory removes everything beneath it
+			{Path: "/var/.wh..wh..opq", TypeFlag: tar.TypeReg},
+		},
+	}
+
+	trees := make([]*FileTree, len(layers))
+	for idx, infos := range layers {
+		trees[idx] = NewFileTree()
+		for _, info := range infos {
+			_, _, err := trees[idx].AddPath(info.Path, info)
+			checkError(t, err, "could not setup test")
+		}
+	}
+
+	actual := LayerStats(trees)
+	if actual[0].OverwrittenBytes != 100+10+1 {
+		t.Errorf("expected %d overwritten bytes, got %d", 100+10+1, actual[0].OverwrittenBytes)
+	}
+	if actual[1].OverwrittenBytes != 0 {
+		t.Errorf("expected no overwritten bytes, got %d", actual[1].OverwrittenBytes)
+	}
+}
@@ -0,0 +1,596 @@
+package filetree
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Query is a compiled filter expression that selects file tree nodes. A query is made of terms, combined with "and"
+// (or "&&", which is also implied between adjacent terms), "or" (or "||"), "not" (or a "!" prefix) and parentheses:
+//
+//	**/*.so                  glob on the path (relative globs match at any depth; "**" spans directories)
+//	path:/etc/*.conf         explicit glob on the path
+//	re:"lib(c|m)"            regular expression on the path (a bare term without glob characters is also a regex)
+//	.*\.so, lib(c|m)         a bare term with regex syntax (\ ^ $ + | ( ) { } or ".*") is a regex, as in earlier releases
+//	size>10MB                file size (operators: = != > >= < <=, or ":" for equality)
+//	uid:1000, gid:0          file owner
+//	mode:+s, mode:-x         permission bits that must be present (+) or absent (-): r, w, x, s (setuid/setgid), t
+//	mode:0755                exact permission bits
+//	type:symlink             file, dir, symlink, hardlink, char, block or fifo
+//	diff:added               the change shown for the node: added, removed, modified, unmodified or changed
+//	layer:3                  the path was touched (written or removed) by the given layer (comparisons allowed)
+//
+// Values containing spaces or parentheses can be quoted with single or double quotes.
+type Query struct {
+	expr string
+	root queryMatcher
+	keys map[string]bool
+}
+
+type queryMatcher func(node *FileNode) bool
+
+// ParseQuery compiles the given filter expression. The layer trees are used to evaluate "layer:" terms (and may be
+// nil when such terms are not needed).
+func ParseQuery(expr string, layers []*FileTree) (*Query, error) {
+	if isBareRegex(expr) {
+		// a single regular expression (the filter syntax of earlier releases), which may contain parentheses
+		root, err := regexMatcher(expr)
+		if err != nil {
+			return nil, err
+		}
+		return &Query{
+			expr: expr,
+			root: root,
+			keys: map[string]bool{"path": true},
+		}, nil
+	}
+
+	tokens, err := tokenizeQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	p := &queryParser{tokens: tokens, layers: layers, keys: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q in query", p.peek())
+	}
+
+	return &Query{
+		expr: expr,
+		root: root,
+		keys: p.keys,
+	}, nil
+}
+
+// Match indicates if the given node satisfies the query.
+func (q *Query) Match(node *FileNode) bool {
+	if q == nil {
+		return true
+	}
+	return q.root(node)
+}
+
+// Uses indicates if the query has any term with the given key (e.g. "diff" or "layer"). Terms matching the path are
+// reported as "path".
+func (q *Query) Uses(key string) bool {
+	return q != nil && q.keys[key]
+}
+
+// String returns the expression the query was parsed from.
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.expr
+}
+
+// tokenizeQuery splits the expression into parentheses and words, keeping quoted sections (which are unquoted) within
+// a single word.
+func tokenizeQuery(expr string) ([]string, error) {
+	var tokens []string
+	var word strings.Builder
+	inWord := false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(expr)
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == '"' || r == '\'':
+			end := idx + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote in query")
+			}
+			word.WriteString(string(runes[idx+1 : end]))
+			inWord = true
+			idx = end
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []string
+	pos    int
+	layers []*FileTree
+	keys   map[string]bool
+}
+
+func (p *queryParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *queryParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) parseOr() (queryMatcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for !p.done() && isQueryOperator(p.peek(), "or", "||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orMatcher(left, right)
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryMatcher, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for !p.done() && p.peek() != ")" && !isQueryOperator(p.peek(), "or", "||") {
+		if isQueryOperator(p.peek(), "and", "&&") {
+			p.pos++
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andMatcher(left, right)
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (queryMatcher, error) {
+	if p.done() {
+		return nil, fmt.Errorf("incomplete query")
+	}
+
+	token := p.peek()
+	switch {
+	case isQueryOperator(token, "not", "!"):
+		p.pos++
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notMatcher(inner), nil
+	case strings.HasPrefix(token, "!") && len(token) > 1:
+		// a negated term (e.g. "!diff:added")
+		p.tokens[p.pos] = token[1:]
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notMatcher(inner), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryMatcher, error) {
+	token := p.peek()
+	switch {
+	case token == "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis in query")
+		}
+		p.pos++
+		return inner, nil
+	case token == ")":
+		return nil, fmt.Errorf("unexpected ')' in query")
+	case isQueryOperator(token, "and", "&&", "or", "||"):
+		return nil, fmt.Errorf("unexpected %q in query", token)
+	}
+
+	p.pos++
+	return p.parseTerm(token)
+}
+
+func isQueryOperator(token string, operators ...string) bool {
+	for _, operator := range operators {
+		if strings.EqualFold(token, operator) {
+			return true
+		}
+	}
+	return false
+}
+
+var queryTermPattern = regexp.MustCompile(`^(?i)(path|glob|re|regex|size|uid|gid|mode|type|diff|layer)(:|!=|>=|<=|=|>|<)(.*)$`)
+
+var queryKeyPattern = regexp.MustCompile(`(?i)(^|[(!&|])(path|glob|re|regex|size|uid|gid|mode|type|diff|layer)(:|!=|>=|<=|=|>|<)`)
+
+// hasRegexSyntax indicates if the term uses syntax that only makes sense in a regular expression (and so should not be
+// read as a glob).
+func hasRegexSyntax(term string) bool {
+	return strings.ContainsAny(term, `\^$+|(){}`) || strings.Contains(term, ".*")
+}
+
+// isBareRegex indicates if the whole expression is a single regular expression: a word with regex syntax that has no
+// key terms, negation or quoting and that compiles.
+func isBareRegex(expr string) bool {
+	if expr == "" || strings.ContainsAny(expr, " \t\n\"'") || strings.HasPrefix(expr, "!") {
+		return false
+	}
+	if !hasRegexSyntax(expr) || queryKeyPattern.MatchString(expr) {
+		return false
+	}
+	_, err := regexp.Compile(expr)
+	return err == nil
+}
+
+// parseTerm compiles a single term (a "key<op>value" predicate or a bare path pattern).
+func (p *queryParser) parseTerm(token string) (queryMatcher, error) {
+	groups := queryTermPattern.FindStringSubmatch(token)
+	if groups == nil {
+		p.keys["path"] = true
+		if !hasRegexSyntax(token) && strings.ContainsAny(token, "*?") {
+			return globMatcher(token)
+		}
+		return regexMatcher(token)
+	}
+
+	key, op, value := strings.ToLower(groups[1]), groups[2], groups[3]
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %q in query", key)
+	}
+
+	switch key {
+	case "path", "glob", "re", "regex":
+		if op != ":" {
+			return nil, fmt.Errorf("unsupported operator %q for %q", op, key)
+		}
+		p.keys["path"] = true
+		if key == "re" || key == "regex" {
+			return regexMatcher(value)
+		}
+		return globMatcher(value)
+	case "size":
+		size, err := humanize.ParseBytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", value, err)
+		}
+		compare, err := numericComparison(op, int64(size))
+		if err != nil {
+			return nil, err
+		}
+		p.keys[key] = true
+		return func(node *FileNode) bool {
+			return compare(node.Data.FileInfo.Size)
+		}, nil
+	case "uid", "gid":
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+		compare, err := numericComparison(op, id)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[key] = true
+		if key == "uid" {
+			return func(node *FileNode) bool { return compare(int64(node.Data.FileInfo.Uid)) }, nil
+		}
+		return func(node *FileNode) bool { return compare(int64(node.Data.FileInfo.Gid)) }, nil
+	case "layer":
+		return p.layerMatcher(op, value)
+	}
+
+	if op != ":" && op != "=" && op != "!=" {
+		return nil, fmt.Errorf("unsupported operator %q for %q", op, key)
+	}
+
+	var matcher queryMatcher
+	var err error
+	switch key {
+	case "mode":
+		matcher, err = modeMatcher(value)
+	case "type":
+		matcher, err = typeMatcher(value)
+	case "diff":
+		matcher, err = diffMatcher(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.keys[key] = true
+	if op == "!=" {
+		return notMatcher(matcher), nil
+	}
+	return matcher, nil
+}
+
+// layerMatcher selects paths that were written or removed by any layer satisfying the comparison.
+func (p *queryParser) layerMatcher(op, value string) (queryMatcher, error) {
+	if p.layers == nil {
+		return nil, fmt.Errorf("layer constraints are not supported here")
+	}
+	index, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layer %q: %w", value, err)
+	}
+	compare, err := numericComparison(op, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	for idx := range p.layers {
+		if compare(int64(idx)) {
+			indexes = append(indexes, idx)
+		}
+	}
+	p.keys["layer"] = true
+
+	layers := p.layers
+	return func(node *FileNode) bool {
+		nodePath := node.Path()
+		for _, idx := range indexes {
+			if found, _ := layers[idx].GetNode(nodePath); found != nil || isRemovedIn(layers[idx], nodePath) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func numericComparison(op string, expected int64) (func(int64) bool, error) {
+	switch op {
+	case ":", "=":
+		return func(v int64) bool { return v == expected }, nil
+	case "!=":
+		return func(v int64) bool { return v != expected }, nil
+	case ">":
+		return func(v int64) bool { return v > expected }, nil
+	case ">=":
+		return func(v int64) bool { return v >= expected }, nil
+	case "<":
+		return func(v int64) bool { return v < expected }, nil
+	case "<=":
+		return func(v int64) bool { return v <= expected }, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+func regexMatcher(expr string) (queryMatcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+	}
+	return func(node *FileNode) bool {
+		return re.MatchString(node.Path())
+	}, nil
+}
+
+func globMatcher(glob string) (queryMatcher, error) {
+	re, err := regexp.Compile(globToRegex(glob))
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	return func(node *FileNode) bool {
+		return re.MatchString(node.Path())
+	}, nil
+}
+
+// globToRegex converts a path glob to an anchored regular expression. "**" matches across directories, "*" and "?"
+// match within a single path element, and globs not starting with "/" may match at any depth.
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	if strings.HasPrefix(glob, "/") {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("(^|/)")
+	}
+
+	runes := []rune(glob)
+	for idx := 0; idx < len(runes); idx++ {
+		r := runes[idx]
+		switch r {
+		case '*':
+			if idx+1 < len(runes) && runes[idx+1] == '*' {
+				idx++
+				if idx+1 < len(runes) && runes[idx+1] == '/' {
+					idx++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := idx + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			class := string(runes[idx+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			idx = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// modeMatcher handles "+bits" (all present), "-bits" (none present) and exact octal permissions.
+func modeMatcher(value string) (queryMatcher, error) {
+	if value[0] != '+' && value[0] != '-' {
+		perm, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mode %q: expected octal permissions or +/- bits", value)
+		}
+		return func(node *FileNode) bool {
+			return uint64(node.Data.FileInfo.Mode.Perm()) == perm
+		}, nil
+	}
+
+	var checks []func(os.FileMode) bool
+	for _, bit := range value[1:] {
+		switch bit {
+		case 'r':
+			checks = append(checks, func(m os.FileMode) bool { return m&0o444 != 0 })
+		case 'w':
+			checks = append(checks, func(m os.FileMode) bool { return m&0o222 != 0 })
+		case 'x':
+			checks = append(checks, func(m os.FileMode) bool { return m&0o111 != 0 })
+		case 's':
+			checks = append(checks, func(m os.FileMode) bool { return m&(os.ModeSetuid|os.ModeSetgid) != 0 })
+		case 't':
+			checks = append(checks, func(m os.FileMode) bool { return m&os.ModeSticky != 0 })
+		default:
+			return nil, fmt.Errorf("invalid mode bit %q (expected one of r, w, x, s, t)", bit)
+		}
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("invalid mode %q: no bits given", value)
+	}
+
+	present := value[0] == '+'
+	return func(node *FileNode) bool {
+		for _, check := range checks {
+			if check(node.Data.FileInfo.Mode) != present {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func typeMatcher(value string) (queryMatcher, error) {
+	var flags []byte
+	switch strings.ToLower(value) {
+	case "dir", "directory":
+		return isQueryDir, nil
+	case "file", "reg", "regular":
+		flags = []byte{tar.TypeReg, tar.TypeRegA} //nolint:staticcheck // TypeRegA may still be found in old archives
+	case "symlink", "link":
+		flags = []byte{tar.TypeSymlink}
+	case "hardlink":
+		flags = []byte{tar.TypeLink}
+	case "char":
+		flags = []byte{tar.TypeChar}
+	case "block":
+		flags = []byte{tar.TypeBlock}
+	case "fifo":
+		flags = []byte{tar.TypeFifo}
+	default:
+		return nil, fmt.Errorf("invalid type %q (expected file, dir, symlink, hardlink, char, block or fifo)", value)
+	}
+	return func(node *FileNode) bool {
+		if isQueryDir(node) {
+			return false
+		}
+		for _, flag := range flags {
+			if node.Data.FileInfo.TypeFlag == flag {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// isQueryDir indicates if the node is a directory, including directories that are only implied by their children.
+func isQueryDir(node *FileNode) bool {
+	return node.Data.FileInfo.IsDir || len(node.Children) > 0
+}
+
+func diffMatcher(value string) (queryMatcher, error) {
+	var diffType DiffType
+	switch strings.ToLower(value) {
+	case "added":
+		diffType = Added
+	case "removed":
+		diffType = Removed
+	case "modified":
+		diffType = Modified
+	case "unmodified":
+		diffType = Unmodified
+	case "changed":
+		return func(node *FileNode) bool { return node.Data.DiffType != Unmodified }, nil
+	default:
+		return nil, fmt.Errorf("invalid diff type %q (expected added, removed, modified, unmodified or changed)", value)
+	}
+	return func(node *FileNode) bool { return node.Data.DiffType == diffType }, nil
+}
+
+func andMatcher(left, right queryMatcher) queryMatcher {
+	return func(node *FileNode) bool { return left(node) && right(node) }
+}
+
+func orMatcher(left, right queryMatcher) queryMatcher {
+	return func(node *FileNode) bool { return left(node) || right(node) }
+}
+
+func notMatcher(inner queryMatcher) queryMatcher {
+	return func(node *FileNode) bool { return !inner(node) }
+}
@@ -0,0 +1,121 @@
+package filetree
+
+import (
+	"archive/tar"
+	"os"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	trees := make([]*FileTree, 3)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	files := []FileInfo{
+		{Path: "/usr/lib/libfoo.so", TypeFlag: tar.TypeReg, Size: 20_000_000, Mode: 0644},
+		{Path: "/usr/lib/libfoo.so.1", TypeFlag: tar.TypeSymlink, Linkname: "libfoo.so", Mode: 0777},
+		{Path: "/usr/bin/sudo", TypeFlag: tar.TypeReg, Size: 200_000, Mode: 0755 | os.ModeSetuid},
+		{Path: "/home/app/config.yaml", TypeFlag: tar.TypeReg, Size: 100, Mode: 0600, Uid: 1000, Gid: 1000},
+	}
+	layerOf := []int{0, 0, 1, 2}
+
+	image := NewFileTree()
+	for idx, info := range files {
+		_, _, err := trees[layerOf[idx]].AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+		node, _, err := image.AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+		if layerOf[idx] == 2 {
+			node.Data.DiffType = Added
+		}
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "**/*.so", expected: []string{"/usr/lib/libfoo.so"}},
+		{query: "*.so*", expected: []string{"/usr/lib/libfoo.so", "/usr/lib/libfoo.so.1"}},
+		{query: "path:/usr/*", expected: []string{"/usr/bin", "/usr/lib"}},
+		{query: "/usr/**", expected: []string{"/usr/bin", "/usr/bin/sudo", "/usr/lib", "/usr/lib/libfoo.so", "/usr/lib/libfoo.so.1"}},
+		{query: "sudo", expected: []string{"/usr/bin/sudo"}},
+		{query: `.*\.so`, expected: []string{"/usr/lib/libfoo.so", "/usr/lib/libfoo.so.1"}},
+		{query: "lib(foo|bar)\\.so$", expected: []string{"/usr/lib/libfoo.so"}},
+		{query: "^/usr/b.*", expected: []string{"/usr/bin", "/usr/bin/sudo"}},
+		{query: `.*\.so$ type:file`, expected: []string{"/usr/lib/libfoo.so"}},
+		{query: `re:"lib(foo)\.so$"`, expected: []string{"/usr/lib/libfoo.so"}},
+		{query: "size>10MB", expected: []string{"/usr/lib/libfoo.so"}},
+		{query: "size>100 and size<=1MB", expected: []string{"/usr/bin/sudo"}},
+		{query: "mode:+s", expected: []string{"/usr/bin/sudo"}},
+		{query: "mode:0600", expected: []string{"/home/app/config.yaml"}},
+		{query: "type:file mode:-x", expected: []string{"/home/app/config.yaml", "/usr/lib/libfoo.so"}},
+		{query: "type:symlink", expected: []string{"/usr/lib/libfoo.so.1"}},
+		{query: "uid:1000", expected: []string{"/home/app/config.yaml"}},
+		{query: "diff:added", expected: []string{"/home/app/config.yaml"}},
+		{query: "layer:1", expected: []string{"/usr", "/usr/bin", "/usr/bin/sudo"}},
+		{query: "layer>=1 type:file", expected: []string{"/home/app/config.yaml", "/usr/bin/sudo"}},
+		{query: "(mode:+s || size>10MB) !layer:1", expected: []string{"/usr/lib/libfoo.so"}},
+		{query: "not type:dir and not (**/*.so or OR_LIKE_WORD)", expected: []string{"/home/app/config.yaml", "/usr/bin/sudo", "/usr/lib/libfoo.so.1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseQuery(test.query, trees)
+			if err != nil {
+				t.Fatalf("unable to parse query: %v", err)
+			}
+
+			var actual []string
+			err = image.VisitDepthParentFirst(func(node *FileNode) error {
+				if node != image.Root && query.Match(node) {
+					actual = append(actual, node.Path())
+				}
+				return nil
+			}, nil)
+			checkError(t, err, "could not visit tree")
+
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+			for idx := range actual {
+				if actual[idx] != test.expected[idx] {
+					t.Errorf("expected %v, got %v", test.expected, actual)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"size>",
+		"size>big",
+		"(mode:+s",
+		"mode:+s)",
+		"mode:+q",
+		"type:socket",
+		"diff:gone",
+		"uid>=x",
+		"re:(",
+		`path:"unterminated`,
+		"and size>1",
+		"layer:1",
+	} {
+		if _, err := ParseQuery(query, nil); err == nil {
+			t.Errorf("expected an error for query %q", query)
+		}
+	}
+}
+
+func TestQuery_Uses(t *testing.T) {
+	query, err := ParseQuery("**/*.so diff:added", nil)
+	if err != nil {
+		t.Fatalf("unable to parse query: %v", err)
+	}
+	if !query.Uses("path") || !query.Uses("diff") || query.Uses("layer") {
+		t.Errorf("unexpected keys reported for query %q", query)
+	}
+}
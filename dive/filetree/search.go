@@ -0,0 +1,89 @@
+package filetree
+
+import (
+	"fmt"
+)
+
+// SearchResult is a single path that a layer added, modified or removed and that matched a search.
+type SearchResult struct {
+	Layer    int
+	Path     string
+	DiffType DiffType
+	// Info is the state of the path after the change (or, when removed, the state that was removed)
+	Info FileInfo
+}
+
+// Search scans every layer (in order) for the paths it wrote or removed that match the given query. Unlike filtering a
+// (stacked) tree, every change is reported on its own, so a path touched by several layers is listed once per layer.
+// The diff type of each result is relative to the stack of all layers beneath it. Removing a directory (by whiteout or
+// by an opaque marker) reports the directory and everything beneath it.
+func Search(trees []*FileTree, query *Query) ([]SearchResult, error) {
+	results := make([]SearchResult, 0)
+	if len(trees) == 0 {
+		return results, nil
+	}
+
+	add := func(idx int, node *FileNode, diff DiffType) {
+		candidate := *node
+		candidate.Data.DiffType = diff
+		if !query.Match(&candidate) {
+			return
+		}
+		results = append(results, SearchResult{
+			Layer:    idx,
+			Path:     node.Path(),
+			DiffType: diff,
+			Info:     node.Data.FileInfo,
+		})
+	}
+
+	removeAll := func(idx int, node *FileNode) error {
+		return node.VisitDepthParentFirst(func(removed *FileNode) error {
+			add(idx, removed, Removed)
+			return nil
+		}, nil, nil)
+	}
+
+	stacked := NewFileTree()
+	for idx, tree := range trees {
+		hidden, err := stacked.opaqueHidden(tree)
+		if err != nil {
+			return nil, fmt.Errorf("could not search layer %d: %w", idx, err)
+		}
+		for _, node := range hidden {
+			if err := removeAll(idx, node); err != nil {
+				return nil, fmt.Errorf("could not search layer %d: %w", idx, err)
+			}
+		}
+
+		err = tree.VisitDepthParentFirst(func(node *FileNode) error {
+			if node == tree.Root {
+				return nil
+			}
+			switch {
+			case node.IsWhiteout():
+				if lower, _ := stacked.GetNode(node.Path()); lower != nil {
+					return removeAll(idx, lower)
+				}
+			case node.Data.FileInfo.Path == "":
+				// the directory is only implied by the paths the layer wrote beneath it
+			default:
+				diff := Added
+				if lower, _ := stacked.GetNode(node.Path()); lower != nil {
+					diff = lower.Data.FileInfo.Compare(node.Data.FileInfo)
+				}
+				add(idx, node, diff)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not search layer %d: %w", idx, err)
+		}
+
+		if _, err := stacked.Stack(tree); err != nil {
+			return nil, fmt.Errorf("could not stack layer %d: %w", idx, err)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package filetree
+
+import (
+	"archive/tar"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	trees := make([]*FileTree, 4)
+	for idx := range trees {
+		trees[idx] = NewFileTree()
+	}
+
+	lib := FileInfo{Path: "/usr/lib/libfoo.so", TypeFlag: tar.TypeReg, Size: 100, Mode: 0644, hash: 1}
+	libbar := FileInfo{Path: "/usr/lib/libbar.so", TypeFlag: tar.TypeReg, Size: 50, Mode: 0644, hash: 3}
+	secret := FileInfo{Path: "/app/secret.key", TypeFlag: tar.TypeReg, Size: 10, Mode: 0600, hash: 4}
+
+	// 0: libraries added
+	for _, info := range []FileInfo{lib, libbar} {
+		_, _, err := trees[0].AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+	}
+
+	// 1: one library rewritten, a secret added
+	modified := lib
+	modified.Size, modified.hash = 200, 2
+	for _, info := range []FileInfo{modified, secret} {
+		_, _, err := trees[1].AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+	}
+
+	// 2: the secret is removed, the library directory is made opaque
+	_, _, err := trees[2].AddPath("/app/.wh.secret.key", *BlankFileChangeInfo("/app/.wh.secret.key"))
+	checkError(t, err, "could not setup test")
+	_, _, err = trees[2].AddPath("/usr/lib/.wh..wh..opq", *BlankFileChangeInfo("/usr/lib/.wh..wh..opq"))
+	checkError(t, err, "could not setup test")
+
+	// 3: a library is added back, unchanged from layer 0
+	_, _, err = trees[3].AddPath(libbar.Path, libbar)
+	checkError(t, err, "could not setup test")
+
+	type result struct {
+		layer int
+		path  string
+		diff  DiffType
+	}
+
+	tests := []struct {
+		query    string
+		expected []result
+	}{
+		{
+			query: "*.so",
+			expected: []result{
+				{layer: 0, path: "/usr/lib/libbar.so", diff: Added},
+				{layer: 0, path: "/usr/lib/libfoo.so", diff: Added},
+				{layer: 1, path: "/usr/lib/libfoo.so", diff: Modified},
+				{layer: 2, path: "/usr/lib/libbar.so", diff: Removed},
+				{layer: 2, path: "/usr/lib/libfoo.so", diff: Removed},
+				{layer: 3, path: "/usr/lib/libbar.so", diff: Added},
+			},
+		},
+		{
+			query: "*.so diff:modified",
+			expected: []result{
+				{layer: 1, path: "/usr/lib/libfoo.so", diff: Modified},
+			},
+		},
+		{
+			query: "secret diff:changed",
+			expected: []result{
+				{layer: 1, path: "/app/secret.key", diff: Added},
+				{layer: 2, path: "/app/secret.key", diff: Removed},
+			},
+		},
+		{
+			query: "size>=100",
+			expected: []result{
+				{layer: 0, path: "/usr/lib/libfoo.so", diff: Added},
+				{layer: 1, path: "/usr/lib/libfoo.so", diff: Modified},
+				{layer: 2, path: "/usr/lib/libfoo.so", diff: Removed},
+			},
+		},
+		{
+			query:    "nothing-matches-this",
+			expected: []result{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := ParseQuery(test.query, trees)
+			if err != nil {
+				t.Fatalf("unable to parse query: %v", err)
+			}
+
+			actual, err := Search(trees, query)
+			checkError(t, err, "could not search")
+
+			if len(actual) != len(test.expected) {
+				for _, r := range actual {
+					t.Logf("   result: %+v", r)
+				}
+				t.Fatalf("expected %d results, got %d", len(test.expected), len(actual))
+			}
+			for idx, r := range actual {
+				expected := test.expected[idx]
+				if r.Layer != expected.layer || r.Path != expected.path || r.DiffType != expected.diff {
+					t.Errorf("result %d: expected %+v, got layer=%d path=%s diff=%v", idx, expected, r.Layer, r.Path, r.DiffType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package filetree
+
+import (
+	"math"
+	"sort"
+)
+
+// TreemapTile is a single rectangle within a treemap, with an area proportional to the size of the node it represents.
+type TreemapTile struct {
+	Node *FileNode
+	// Depth is 1 for the children of the treemap root, 2 for their children, and so on
+	Depth  int
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// Treemap lays out the nodes beneath the given root as a squarified treemap that fills a width x height area, where
+// the area of each tile is proportional to FileNode.GetSize. Directories are subdivided into tiles for their own
+// children, down to maxDepth levels beneath the root, keeping the top "header" units of each directory tile free (e.g.
+// for a label) when there is room to do so. Tiles are returned parent first; whiteouts and empty nodes are left out.
+func Treemap(root *FileNode, width, height float64, maxDepth int, header float64) []TreemapTile {
+	var tiles []TreemapTile
+	if root == nil || width <= 0 || height <= 0 || maxDepth <= 0 {
+		return tiles
+	}
+	layoutTreemap(root, 1, maxDepth, header, width, height, 0, 0, &tiles)
+	return tiles
+}
+
+func layoutTreemap(parent *FileNode, depth, maxDepth int, header, width, height, x, y float64, tiles *[]TreemapTile) {
+	var children []*FileNode
+	var total float64
+	for _, child := range parent.Children {
+		if child.IsWhiteout() || child.GetSize() <= 0 {
+			continue
+		}
+		children = append(children, child)
+		total += float64(child.GetSize())
+	}
+	if len(children) == 0 {
+		return
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].GetSize() == children[j].GetSize() {
+			return children[i].Name < children[j].Name
+		}
+		return children[i].GetSize() > children[j].GetSize()
+	})
+
+	scale := width * height / total
+	areas := make([]float64, len(children))
+	for idx, child := range children {
+		areas[idx] = float64(child.GetSize()) * scale
+	}
+
+	squarify(areas, x, y, width, height, func(idx int, x, y, w, h float64) {
+		child := children[idx]
+		*tiles = append(*tiles, TreemapTile{Node: child, Depth: depth, X: x, Y: y, Width: w, Height: h})
+		if depth >= maxDepth || len(child.Children) == 0 {
+			return
+		}
+		if h > 2*header {
+			y += header
+			h -= header
+		}
+		layoutTreemap(child, depth+1, maxDepth, header, w, h, x, y, tiles)
+	})
+}
+
+// squarify places the given areas (largest first) within the rectangle, filling rows along the shorter side for as
+// long as adding another area does not make the worst aspect ratio within the row any worse.
+func squarify(areas []float64, x, y, width, height float64, place func(idx int, x, y, w, h float64)) {
+	start := 0
+	for start < len(areas) {
+		side := math.Min(width, height)
+		if side <= 0 {
+			// there is no room left (only possible through rounding), collapse whatever remains
+			for idx := start; idx < len(areas); idx++ {
+				place(idx, x, y, 0, 0)
+			}
+			return
+		}
+
+		stop := start + 1
+		rowArea := areas[start]
+		for stop < len(areas) {
+			if worstRatio(areas[start:stop+1], rowArea+areas[stop], side) > worstRatio(areas[start:stop], rowArea, side) {
+				break
+			}
+			rowArea += areas[stop]
+			stop++
+		}
+
+		if width >= height {
+			// lay the row out as a column on the left
+			rowWidth := rowArea / height
+			offset := y
+			for idx := start; idx < stop; idx++ {
+				h := areas[idx] / rowWidth
+				place(idx, x, offset, rowWidth, h)
+				offset += h
+			}
+			x += rowWidth
+			width -= rowWidth
+		} else {
+			// lay the row out along the top
+			rowHeight := rowArea / width
+			offset := x
+			for idx := start; idx < stop; idx++ {
+				w := areas[idx] / rowHeight
+				place(idx, offset, y, w, rowHeight)
+				offset += w
+			}
+			y += rowHeight
+			height -= rowHeight
+		}
+		start = stop
+	}
+}
+
+// worstRatio is the largest aspect ratio of any tile within a row of the given areas laid along the given side.
+func worstRatio(row []float64, rowArea, side float64) float64 {
+	smallest, largest := row[0], row[0]
+	for _, area := range row {
+		smallest = math.Min(smallest, area)
+		largest = math.Max(largest, area)
+	}
+	sideSq := side * side
+	areaSq := rowArea * rowArea
+	return math.Max(sideSq*largest/areaSq, areaSq/(sideSq*smallest))
+}
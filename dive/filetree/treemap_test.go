@@ -0,0 +1,100 @@
+package filetree
+
+import (
+	"archive/tar"
+	"math"
+	"testing"
+)
+
+func TestTreemap(t *testing.T) {
+	tree := NewFileTree()
+	infos := []FileInfo{
+		{Path: "/usr/lib/libbig.so", TypeFlag: tar.TypeReg, Size: 4000},
+		{Path: "/usr/lib/libsmall.so", TypeFlag: tar.TypeReg, Size: 1000},
+		{Path: "/usr/bin/tool", TypeFlag: tar.TypeReg, Size: 1000},
+		{Path: "/etc/config", TypeFlag: tar.TypeReg, Size: 2000},
+		{Path: "/etc/empty", TypeFlag: tar.TypeReg},
+		{Path: "/.wh.gone", TypeFlag: tar.TypeReg},
+	}
+	for _, info := range infos {
+		_, _, err := tree.AddPath(info.Path, info)
+		checkError(t, err, "could not setup test")
+	}
+
+	const width, height = 80.0, 20.0
+
+	t.Run("areas follow size", func(t *testing.T) {
+		tiles := Treemap(tree.Root, width, height, 1, 0)
+		if len(tiles) != 2 {
+			t.Fatalf("expected 2 tiles, got %+v", tiles)
+		}
+
+		expected := map[string]float64{
+			"/usr": width * height * 6000 / 8000,
+			"/etc": width * height * 2000 / 8000,
+		}
+		for _, tile := range tiles {
+			if tile.Depth != 1 {
+				t.Errorf("expected depth 1 for %s, got %d", tile.Node.Path(), tile.Depth)
+			}
+			area := tile.Width * tile.Height
+			if math.Abs(area-expected[tile.Node.Path()]) > 0.001 {
+				t.Errorf("expected area %v for %s, got %v", expected[tile.Node.Path()], tile.Node.Path(), area)
+			}
+			if tile.X < 0 || tile.Y < 0 || tile.X+tile.Width > width+0.001 || tile.Y+tile.Height > height+0.001 {
+				t.Errorf("tile for %s is out of bounds: %+v", tile.Node.Path(), tile)
+			}
+		}
+	})
+
+	t.Run("nested tiles fit their parent", func(t *testing.T) {
+		tiles := Treemap(tree.Root, width, height, 3, 2)
+
+		byPath := make(map[string]TreemapTile)
+		var total float64
+		for _, tile := range tiles {
+			byPath[tile.Node.Path()] = tile
+			if tile.Depth == 1 {
+				total += tile.Width * tile.Height
+			}
+		}
+		if math.Abs(total-width*height) > 0.001 {
+			t.Errorf("expected the top level tiles to fill the area, got %v", total)
+		}
+
+		for _, missing := range []string{"/etc/empty", "/gone", "/.wh.gone"} {
+			if _, ok := byPath[missing]; ok {
+				t.Errorf("expected no tile for %s", missing)
+			}
+		}
+
+		for p, tile := range byPath {
+			if tile.Node.Parent == tree.Root {
+				continue
+			}
+			parent, ok := byPath[tile.Node.Parent.Path()]
+			if !ok {
+				t.Fatalf("expected a tile for the parent of %s", p)
+			}
+			if tile.Depth != parent.Depth+1 {
+				t.Errorf("expected depth %d for %s, got %d", parent.Depth+1, p, tile.Depth)
+			}
+			if tile.X < parent.X-0.001 || tile.Y < parent.Y+2-0.001 ||
+				tile.X+tile.Width > parent.X+parent.Width+0.001 || tile.Y+tile.Height > parent.Y+parent.Height+0.001 {
+				t.Errorf("tile for %s (%+v) is not within (the body of) its parent (%+v)", p, tile, parent)
+			}
+		}
+
+		lib := byPath["/usr/lib"]
+		big := byPath["/usr/lib/libbig.so"]
+		if ratio := (big.Width * big.Height) / (lib.Width * (lib.Height - 2)); math.Abs(ratio-0.8) > 0.001 {
+			t.Errorf("expected libbig.so to take 80%% of /usr/lib, got %v", ratio)
+		}
+	})
+
+	t.Run("no room", func(t *testing.T) {
+		if tiles := Treemap(tree.Root, 0, height, 1, 0); len(tiles) != 0 {
+			t.Errorf("expected no tiles, got %+v", tiles)
+		}
+	})
+}
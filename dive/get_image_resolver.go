@@ -71,3 +71,16 @@ func GetImageResolver(r ImageSource) (image.Resolver, error) {
 
 	return nil, fmt.Errorf("unable to determine image resolver")
 }
+
+// GetBuildxImageResolver returns a resolver that builds images with BuildKit (`docker buildx build`, or `podman build`
+// which supports the same arguments), noting which layers were reused from the build cache.
+func GetBuildxImageResolver(r ImageSource) (image.Resolver, error) {
+	switch r {
+	case SourceDockerEngine:
+		return docker.NewResolverFromBuildx(), nil
+	case SourcePodmanEngine:
+		return podman.NewResolverFromBuildx(), nil
+	}
+
+	return nil, fmt.Errorf("unable to build with buildx from source '%s'", r)
+}
@@ -5,43 +5,81 @@ import (
 	"github.com/wagoodman/dive/dive/filetree"
 )
 
+// LargestCount is the number of files (and directories) ranked by size for the image and for each layer.
+const LargestCount = 10
+
 type Analysis struct {
-	Image             string
-	Layers            []*Layer
-	RefTrees          []*filetree.FileTree
-	Efficiency        float64
-	SizeBytes         uint64
-	UserSizeByes      uint64  // this is all bytes except for the base image
-	WastedUserPercent float64 // = wasted-bytes/user-size-bytes
-	WastedBytes       uint64
-	Inefficiencies    filetree.EfficiencySlice
+	Image               string
+	Digest              string // the digest of the image config (the image ID), if known
+	Metadata            Metadata
+	Attestations        []Attestation
+	Layers              []*Layer
+	RefTrees            []*filetree.FileTree
+	Efficiency          float64
+	SizeBytes           uint64
+	UserSizeByes        uint64  // this is all bytes except for the base image
+	CompressedSizeBytes uint64  // the sum of all layer blob sizes (zero when the size of any layer blob is unknown)
+	WastedUserPercent   float64 // = wasted-bytes/user-size-bytes
+	WastedBytes         uint64
+	Inefficiencies      filetree.EfficiencySlice
+	Duplicates          filetree.DuplicateSlice
+	DuplicateBytes      uint64                  // bytes spent on extra copies of identical content at different paths
+	Largest             filetree.LargestPaths   // the largest files and directories in the final image
+	LayerLargest        []filetree.LargestPaths // the largest files and directories written by each layer
+	LayerStats          []filetree.LayerStat    // the files added, modified and removed by each layer
 }
 
 func Analyze(ctx context.Context, img *Image) (*Analysis, error) {
 	efficiency, inefficiencies := filetree.Efficiency(img.Trees)
-	var sizeBytes, userSizeBytes uint64
+	var sizeBytes, userSizeBytes, compressedSizeBytes uint64
+	compressedSizeKnown := true
 
 	for i, v := range img.Layers {
 		sizeBytes += v.Size
+		compressedSizeBytes += v.CompressedSize
+		compressedSizeKnown = compressedSizeKnown && v.CompressedSize > 0
 		if i != 0 {
 			userSizeBytes += v.Size
 		}
 	}
+	if !compressedSizeKnown {
+		compressedSizeBytes = 0
+	}
 
 	var wastedBytes uint64
 	for _, file := range inefficiencies {
 		wastedBytes += uint64(file.CumulativeSize)
 	}
 
+	duplicates := filetree.Duplicates(img.Trees)
+	var duplicateBytes uint64
+	for _, dup := range duplicates {
+		duplicateBytes += uint64(dup.WastedSize)
+	}
+
+	layerLargest := make([]filetree.LargestPaths, len(img.Trees))
+	for idx, tree := range img.Trees {
+		layerLargest[idx] = filetree.Largest(tree, LargestCount)
+	}
+
 	return &Analysis{
-		Image:             img.Request,
-		Layers:            img.Layers,
-		RefTrees:          img.Trees,
-		Efficiency:        efficiency,
-		UserSizeByes:      userSizeBytes,
-		SizeBytes:         sizeBytes,
-		WastedBytes:       wastedBytes,
-		WastedUserPercent: float64(wastedBytes) / float64(userSizeBytes),
-		Inefficiencies:    inefficiencies,
+		Image:               img.Request,
+		Digest:              img.Digest,
+		Metadata:            img.Metadata,
+		Attestations:        img.Attestations,
+		Layers:              img.Layers,
+		RefTrees:            img.Trees,
+		Efficiency:          efficiency,
+		UserSizeByes:        userSizeBytes,
+		SizeBytes:           sizeBytes,
+		CompressedSizeBytes: compressedSizeBytes,
+		WastedBytes:         wastedBytes,
+		WastedUserPercent:   float64(wastedBytes) / float64(userSizeBytes),
+		Inefficiencies:      inefficiencies,
+		Duplicates:          duplicates,
+		DuplicateBytes:      duplicateBytes,
+		Largest:             filetree.LargestInImage(img.Trees, LargestCount),
+		LayerLargest:        layerLargest,
+		LayerStats:          filetree.LayerStats(img.Trees),
 	}, nil
 }
@@ -0,0 +1,31 @@
+package image
+
+// kinds of attestations that are understood
+const (
+	AttestationProvenance = "provenance"
+	AttestationSBOM       = "sbom"
+	AttestationUnknown    = "unknown"
+)
+
+// Attestation is a statement about the image (such as build provenance or an SBOM) that is shipped alongside the image
+// within the index, but is not part of the image content itself.
+type Attestation struct {
+	Kind          string `json:"kind"`
+	PredicateType string `json:"predicateType"`
+	// Subject is the digest of the image manifest the attestation refers to
+	Subject   string `json:"subject"`
+	SizeBytes uint64 `json:"sizeBytes"`
+
+	// Materials are the inputs of the build (e.g. base images and git sources), as found in provenance attestations
+	Materials []Material `json:"materials,omitempty"`
+
+	// SBOMFormat and PackageCount describe SBOM attestations
+	SBOMFormat   string `json:"sbomFormat,omitempty"`
+	PackageCount int    `json:"packageCount,omitempty"`
+}
+
+// Material is a single input of the build, as recorded in a provenance attestation.
+type Material struct {
+	URI    string `json:"uri"`
+	Digest string `json:"digest,omitempty"`
+}
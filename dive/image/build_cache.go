@@ -0,0 +1,127 @@
+package image
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BuildCache indicates if a layer was reused from the build cache or rebuilt, which is only known for images built
+// by dive with a builder that reports it (see ApplyBuildSteps).
+type BuildCache int
+
+const (
+	BuildCacheUnknown BuildCache = iota
+	BuildCacheHit
+	BuildCacheMiss
+)
+
+func (c BuildCache) String() string {
+	return [...]string{"unknown", "hit", "miss"}[c]
+}
+
+// BuildStep is a Dockerfile instruction as reported by the builder while building an image.
+type BuildStep struct {
+	// Instruction is the Dockerfile instruction, e.g. "RUN apk add curl"
+	Instruction string
+	// Cached indicates that the result of the step was reused from the build cache
+	Cached bool
+}
+
+// ApplyBuildSteps sets the build cache state of each layer that was created by one of the given build steps. Layers
+// are matched to steps by their command (from the image history), so layers of the base image (and steps that did not
+// create a layer) are left as unknown.
+func ApplyBuildSteps(layers []*Layer, steps []BuildStep) {
+	used := make([]bool, len(steps))
+	// later layers are matched with later steps, so that repeated instructions are paired in build order
+	for i := len(layers) - 1; i >= 0; i-- {
+		keyword, args := normalizeHistoryCommand(layers[i].Command)
+		if keyword == "" {
+			continue
+		}
+		for j := len(steps) - 1; j >= 0; j-- {
+			if used[j] || !instructionMatches(keyword, args, steps[j].Instruction) {
+				continue
+			}
+			used[j] = true
+			layers[i].BuildCache = BuildCacheMiss
+			if steps[j].Cached {
+				layers[i].BuildCache = BuildCacheHit
+			}
+			break
+		}
+	}
+}
+
+// FirstCacheMiss returns the index of the first layer that was rebuilt (the step that invalidated the build cache for
+// all later steps), or -1 when no layer is known to be rebuilt.
+func FirstCacheMiss(layers []*Layer) int {
+	for idx, layer := range layers {
+		if layer.BuildCache == BuildCacheMiss {
+			return idx
+		}
+	}
+	return -1
+}
+
+func instructionMatches(keyword, args, instruction string) bool {
+	fields := strings.Fields(instruction)
+	if len(fields) == 0 || !isKeyword(fields[0]) {
+		return false
+	}
+	stepKeyword, stepArgs := normalizeHistoryCommand(instruction)
+	if stepKeyword != keyword {
+		return false
+	}
+	if stepArgs == args {
+		return true
+	}
+
+	// some builders record the source of a COPY/ADD as a content digest in the image history (e.g. "COPY
+	// dir:4f1b... in /app"), in which case only the destination can be compared
+	if keyword == "COPY" || keyword == "ADD" {
+		historyFields, stepFields := strings.Fields(args), strings.Fields(stepArgs)
+		return len(historyFields) > 1 && len(stepFields) > 1 && historyFields[len(historyFields)-1] == stepFields[len(stepFields)-1]
+	}
+	return false
+}
+
+// normalizeHistoryCommand splits the command of a layer (as recorded in the image history) into its Dockerfile
+// keyword and arguments, removing what the builder adds to the instruction (the shell, build arguments and markers).
+func normalizeHistoryCommand(command string) (string, string) {
+	command = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(command), "# buildkit"))
+	command = strings.TrimSpace(strings.TrimPrefix(command, "#(nop)"))
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	keyword := "RUN"
+	if isKeyword(fields[0]) {
+		keyword = fields[0]
+		fields = fields[1:]
+	}
+
+	if keyword == "RUN" {
+		// build arguments are recorded as "|<count> NAME=value ..." ahead of the shell
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "|") {
+			if count, err := strconv.Atoi(strings.TrimPrefix(fields[0], "|")); err == nil && count+1 <= len(fields) {
+				fields = fields[count+1:]
+			}
+		}
+		args := strings.Join(fields, " ")
+		args = strings.Replace(args, "/bin/sh -c ", "", 1)
+		return keyword, strings.TrimSpace(args)
+	}
+
+	return keyword, strings.Join(fields, " ")
+}
+
+func isKeyword(s string) bool {
+	switch s {
+	case "ADD", "ARG", "CMD", "COPY", "ENTRYPOINT", "ENV", "EXPOSE", "HEALTHCHECK", "LABEL", "ONBUILD", "RUN", "SHELL",
+		"STOPSIGNAL", "USER", "VOLUME", "WORKDIR":
+		return true
+	}
+	return false
+}
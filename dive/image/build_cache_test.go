@@ -0,0 +1,83 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyBuildSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		steps    []BuildStep
+		expected []BuildCache
+	}{
+		{
+			name: "buildkit history",
+			commands: []string{
+				"#(nop) ADD file:5f8a2ff1e39a1bd in / ",
+				"RUN /bin/sh -c apk add --no-cache curl # buildkit",
+				"RUN |1 VERSION=1.2 /bin/sh -c echo $VERSION > /version # buildkit",
+				"COPY app /app # buildkit",
+				"RUN --mount=type=cache,target=/root/.cache /bin/sh -c make -C /app # buildkit",
+			},
+			steps: []BuildStep{
+				{Instruction: "FROM docker.io/library/alpine:3.20", Cached: true},
+				{Instruction: "RUN apk add --no-cache curl", Cached: true},
+				{Instruction: "RUN echo $VERSION > /version", Cached: true},
+				{Instruction: "COPY app /app"},
+				{Instruction: "RUN --mount=type=cache,target=/root/.cache make -C /app"},
+			},
+			expected: []BuildCache{BuildCacheUnknown, BuildCacheHit, BuildCacheHit, BuildCacheMiss, BuildCacheMiss},
+		},
+		{
+			name: "podman history",
+			commands: []string{
+				"#(nop) ADD file:5f8a2ff1e39a1bd in / ",
+				"apk add --no-cache curl",
+				"#(nop) COPY dir:0b3f2c8e1d7a in /app ",
+			},
+			steps: []BuildStep{
+				{Instruction: "RUN apk add --no-cache curl", Cached: true},
+				{Instruction: "COPY app /app"},
+			},
+			expected: []BuildCache{BuildCacheUnknown, BuildCacheHit, BuildCacheMiss},
+		},
+		{
+			name: "repeated instructions are paired in build order",
+			commands: []string{
+				"RUN /bin/sh -c apk update # buildkit",
+				"RUN /bin/sh -c apk update # buildkit",
+			},
+			steps: []BuildStep{
+				{Instruction: "RUN apk update", Cached: true},
+				{Instruction: "RUN apk update"},
+			},
+			expected: []BuildCache{BuildCacheHit, BuildCacheMiss},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var layers []*Layer
+			for idx, command := range test.commands {
+				layers = append(layers, &Layer{Index: idx, Command: command})
+			}
+
+			ApplyBuildSteps(layers, test.steps)
+
+			var actual []BuildCache
+			for _, layer := range layers {
+				actual = append(actual, layer.BuildCache)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestFirstCacheMiss(t *testing.T) {
+	layers := []*Layer{{}, {BuildCache: BuildCacheHit}, {BuildCache: BuildCacheMiss}, {BuildCache: BuildCacheMiss}}
+	assert.Equal(t, 2, FirstCacheMiss(layers))
+	assert.Equal(t, -1, FirstCacheMiss(layers[:2]))
+}
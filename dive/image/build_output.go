@@ -0,0 +1,23 @@
+package image
+
+import (
+	"context"
+	"io"
+)
+
+type buildOutputKey struct{}
+
+// WithBuildOutput returns a context that directs the output of an image build to the given writer (instead of the
+// current tty).
+func WithBuildOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, buildOutputKey{}, w)
+}
+
+// BuildOutput returns the writer set by WithBuildOutput, or nil when the build should write to the current tty.
+func BuildOutput(ctx context.Context) io.Writer {
+	w, ok := ctx.Value(buildOutputKey{}).(io.Writer)
+	if !ok {
+		return nil
+	}
+	return w
+}
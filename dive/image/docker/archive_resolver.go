@@ -26,7 +26,7 @@ func (r *archiveResolver) Fetch(ctx context.Context, path string) (*image.Image,
 	}
 	defer reader.Close()
 
-	img, err := NewImageArchive(reader)
+	img, err := NewImageArchiveWithContext(ctx, reader)
 	if err != nil {
 		return nil, err
 	}
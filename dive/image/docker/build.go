@@ -2,6 +2,7 @@ package docker
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 
@@ -14,7 +15,9 @@ const (
 	defaultContainerfileName = "Containerfile"
 )
 
-func buildImageFromCli(fs afero.Fs, buildArgs []string) (string, error) {
+// buildImageFromCli runs `docker build` with the given arguments, writing the build output to the given writer (or to
+// the current tty when no writer is given), and returns the ID of the built image.
+func buildImageFromCli(fs afero.Fs, buildArgs []string, out io.Writer) (string, error) {
 	iidfile, err := afero.TempFile(fs, "", "dive.*.iid")
 	if err != nil {
 		return "", err
@@ -33,7 +36,7 @@ func buildImageFromCli(fs afero.Fs, buildArgs []string) (string, error) {
 		allArgs = append([]string{"--iidfile", iidfile.Name(), "-f", containerFilePath}, buildArgs...)
 	}
 
-	err = runDockerCmd("build", allArgs...)
+	err = runDockerCmdWithOutput(out, "build", allArgs...)
 	if err != nil {
 		return "", err
 	}
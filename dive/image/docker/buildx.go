@@ -0,0 +1,197 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+var (
+	// e.g. "#7 [builder 2/4] RUN go build ./..." (internal steps, like "[internal] load .dockerignore", have no position)
+	buildkitStepPattern   = regexp.MustCompile(`^#(\d+) \[[^\]]*\d+/\d+\] (.+)$`)
+	buildkitCachedPattern = regexp.MustCompile(`^#(\d+) CACHED$`)
+)
+
+// buildxResult describes an image built with `docker buildx build`.
+type buildxResult struct {
+	// id is the image config digest (the image ID)
+	id string
+	// name is the first name the image was tagged with (if any)
+	name string
+	// archive is the path of the OCI archive the image was exported to (when not loaded into the docker engine)
+	archive string
+	// steps are the build steps as reported by BuildKit
+	steps []image.BuildStep
+}
+
+// buildImageFromBuildx runs `docker buildx build` with the given arguments, writing the build output to the given
+// writer (or to the current tty when no writer is given). Unless the image is loaded into the docker engine (with
+// --load, or by the default "docker" driver of buildx), the image is exported to an OCI archive in the given directory,
+// which saves a round trip through the engine.
+func buildImageFromBuildx(fs afero.Fs, buildArgs []string, out io.Writer, dir string) (*buildxResult, error) {
+	if isFlagSet(buildArgs, "-o", "--output", "--push") {
+		return nil, fmt.Errorf("the build output is managed by dive (use --load to also load the image into the docker engine)")
+	}
+
+	metadataFile, err := afero.TempFile(fs, "", "dive.*.metadata.json")
+	if err != nil {
+		return nil, err
+	}
+	defer fs.Remove(metadataFile.Name()) // nolint:errcheck
+	defer metadataFile.Close()
+
+	allArgs := []string{"build", "--metadata-file", metadataFile.Name(), "--progress", "plain"}
+
+	var archive string
+	if !isFlagSet(buildArgs, "--load") {
+		driver, err := buildxDriver(buildArgs)
+		if err != nil {
+			return nil, err
+		}
+		if driver == "docker" {
+			// the default driver can only export OCI archives when docker uses the containerd image store, so the
+			// image is loaded into the docker engine instead
+			allArgs = append(allArgs, "--load")
+		} else {
+			archive = filepath.Join(dir, "image.tar")
+			allArgs = append(allArgs, "--output", "type=oci,dest="+archive)
+		}
+	}
+
+	if !isFileFlagsAreSet(buildArgs, "-f", "--file") {
+		containerFilePath, err := tryFindContainerfile(fs, buildArgs)
+		if err != nil {
+			return nil, err
+		}
+		allArgs = append(allArgs, "-f", containerFilePath)
+	}
+	allArgs = append(allArgs, buildArgs...)
+
+	var progress bytes.Buffer
+	err = runDockerCmdWithCapture(out, &progress, "buildx", allArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := afero.ReadFile(fs, metadataFile.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseBuildxMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	result.archive = archive
+	result.steps = parseBuildkitSteps(progress.String())
+
+	return result, nil
+}
+
+// isFlagSet indicates if any of the given flags is present in the argument list (either alone or as --flag=value).
+func isFlagSet(args []string, flags ...string) bool {
+	for _, arg := range args {
+		for _, flag := range flags {
+			if arg == flag || strings.HasPrefix(arg, flag+"=") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flagValue returns the value of the last occurrence of any of the given flags in the argument list (given either as
+// --flag value or as --flag=value), or an empty string when none is present.
+func flagValue(args []string, flags ...string) string {
+	var value string
+	for i, arg := range args {
+		for _, flag := range flags {
+			switch {
+			case arg == flag && i+1 < len(args):
+				value = args[i+1]
+			case strings.HasPrefix(arg, flag+"="):
+				value = strings.TrimPrefix(arg, flag+"=")
+			}
+		}
+	}
+	return value
+}
+
+// buildxDriver returns the driver (e.g. "docker" or "docker-container") of the builder used for the given build
+// arguments, which is either the one given with --builder or the current one.
+func buildxDriver(buildArgs []string) (string, error) {
+	args := []string{"inspect"}
+	if builder := flagValue(buildArgs, "--builder"); builder != "" {
+		args = append(args, builder)
+	}
+
+	var out bytes.Buffer
+	if err := runDockerCmdWithOutput(&out, "buildx", args...); err != nil {
+		return "", fmt.Errorf("unable to inspect the buildx builder: %w: %s", err, strings.TrimSpace(out.String()))
+	}
+	return parseBuildxDriver(out.String()), nil
+}
+
+// parseBuildxDriver reads the driver of a builder from the output of `docker buildx inspect`.
+func parseBuildxDriver(inspect string) string {
+	for _, line := range strings.Split(inspect, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "Driver:"); found {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// parseBuildxMetadata reads the image ID and name from the file written by `docker buildx build --metadata-file`.
+func parseBuildxMetadata(contents []byte) (*buildxResult, error) {
+	var metadata struct {
+		ConfigDigest string `json:"containerimage.config.digest"`
+		Name         string `json:"image.name"`
+	}
+	if err := json.Unmarshal(contents, &metadata); err != nil {
+		return nil, fmt.Errorf("unable to read build metadata: %w", err)
+	}
+	if metadata.ConfigDigest == "" {
+		return nil, fmt.Errorf("build metadata does not describe an image")
+	}
+
+	// several names may be given (one for each tag)
+	name, _, _ := strings.Cut(metadata.Name, ",")
+
+	return &buildxResult{
+		id:   metadata.ConfigDigest,
+		name: name,
+	}, nil
+}
+
+// parseBuildkitSteps returns the Dockerfile steps from BuildKit's plain progress output, in the order they were
+// started, noting which steps were satisfied from the build cache.
+func parseBuildkitSteps(progress string) []image.BuildStep {
+	var steps []image.BuildStep
+	stepIndexes := make(map[string]int)
+	for _, line := range strings.Split(progress, "\n") {
+		line = strings.TrimSpace(line)
+		if match := buildkitStepPattern.FindStringSubmatch(line); match != nil {
+			// the name of a step is repeated when its output resumes after another step's output
+			if _, exists := stepIndexes[match[1]]; !exists {
+				stepIndexes[match[1]] = len(steps)
+				steps = append(steps, image.BuildStep{Instruction: match[2]})
+			}
+			continue
+		}
+		if match := buildkitCachedPattern.FindStringSubmatch(line); match != nil {
+			if idx, exists := stepIndexes[match[1]]; exists {
+				steps[idx].Cached = true
+			}
+		}
+	}
+	return steps
+}
@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"golang.org/x/net/context"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+// buildxResolver builds images with `docker buildx build`, noting which layers were reused from the build cache.
+// Images are fetched from (and files extracted with) the docker engine.
+type buildxResolver struct {
+	engine *engineResolver
+	// loaded indicates that the last built image was loaded into the docker engine
+	loaded bool
+}
+
+func NewResolverFromBuildx() *buildxResolver {
+	return &buildxResolver{
+		engine: NewResolverFromEngine(),
+	}
+}
+
+// Name returns the name of the resolver to display to the user.
+func (r *buildxResolver) Name() string {
+	return "docker-buildx"
+}
+
+func (r *buildxResolver) Fetch(ctx context.Context, id string) (*image.Image, error) {
+	return r.engine.Fetch(ctx, id)
+}
+
+func (r *buildxResolver) Build(ctx context.Context, args []string) (*image.Image, error) {
+	dir, err := os.MkdirTemp("", "dive-buildx-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	result, err := buildImageFromBuildx(afero.NewOsFs(), args, image.BuildOutput(ctx), dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var img *image.Image
+	r.loaded = result.archive == ""
+	if r.loaded {
+		img, err = r.engine.Fetch(ctx, result.id)
+	} else {
+		img, err = r.fetchArchive(ctx, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	image.ApplyBuildSteps(img.Layers, result.steps)
+	return img, nil
+}
+
+func (r *buildxResolver) fetchArchive(ctx context.Context, result *buildxResult) (*image.Image, error) {
+	reader, err := os.Open(result.archive)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	archive, err := NewImageArchiveWithContext(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	name := result.name
+	if name == "" {
+		name = result.id
+	}
+	return archive.ToImage(name)
+}
+
+func (r *buildxResolver) Extract(ctx context.Context, id string, l string, p string) error {
+	if !r.loaded {
+		return fmt.Errorf("unable to extract from image '%s': the image is only available in the docker engine when built with --load", id)
+	}
+	return r.engine.Extract(ctx, id, l, p)
+}
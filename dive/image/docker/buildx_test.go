@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+func TestIsFlagSet(t *testing.T) {
+	assert.True(t, isFlagSet([]string{"--load", "."}, "--load"))
+	assert.True(t, isFlagSet([]string{"--output=type=docker", "."}, "-o", "--output"))
+	assert.False(t, isFlagSet([]string{"--output-dir", "."}, "--output"))
+	assert.False(t, isFlagSet([]string{"."}, "--push"))
+}
+
+func TestFlagValue(t *testing.T) {
+	assert.Equal(t, "ci", flagValue([]string{"--builder", "ci", "."}, "--builder"))
+	assert.Equal(t, "ci", flagValue([]string{"--builder=ci", "."}, "--builder"))
+	assert.Equal(t, "", flagValue([]string{".", "--builder"}, "--builder"))
+}
+
+func TestParseBuildxDriver(t *testing.T) {
+	inspect := `Name:          default
+Driver:        docker
+Last Activity: 2024-11-02 10:31:27 +0000 UTC
+
+Nodes:
+Name:      default
+Endpoint:  default
+Status:    running
+`
+	assert.Equal(t, "docker", parseBuildxDriver(inspect))
+	assert.Equal(t, "docker-container", parseBuildxDriver("Name: ci\nDriver: docker-container\n"))
+	assert.Equal(t, "", parseBuildxDriver(""))
+}
+
+func TestParseBuildxMetadata(t *testing.T) {
+	metadata := `{
+  "buildx.build.ref": "default/default/x1",
+  "containerimage.config.digest": "sha256:0b2c8a1f",
+  "containerimage.digest": "sha256:7f3d9e2a",
+  "image.name": "docker.io/library/app:latest,docker.io/library/app:1.0"
+}`
+
+	result, err := parseBuildxMetadata([]byte(metadata))
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:0b2c8a1f", result.id)
+	assert.Equal(t, "docker.io/library/app:latest", result.name)
+
+	_, err = parseBuildxMetadata([]byte(`{"buildx.build.ref": "default/default/x1"}`))
+	assert.Error(t, err)
+}
+
+func TestParseBuildkitSteps(t *testing.T) {
+	progress := `#0 building with "default" instance using docker driver
+
+#1 [internal] load build definition from Dockerfile
+#1 transferring dockerfile: 142B done
+#1 DONE 0.0s
+
+#2 [internal] load metadata for docker.io/library/alpine:3.20
+#2 DONE 0.6s
+
+#3 [1/4] FROM docker.io/library/alpine:3.20@sha256:1e42bbe2
+#3 CACHED
+
+#4 [2/4] RUN apk add --no-cache curl
+#4 CACHED
+
+#5 [3/4] COPY app /app
+#5 DONE 0.1s
+
+#6 [4/4] RUN make -C /app
+#6 0.212 make: Entering directory '/app'
+#6 [4/4] RUN make -C /app
+#6 DONE 1.3s
+`
+
+	expected := []image.BuildStep{
+		{Instruction: "FROM docker.io/library/alpine:3.20@sha256:1e42bbe2", Cached: true},
+		{Instruction: "RUN apk add --no-cache curl", Cached: true},
+		{Instruction: "COPY app /app"},
+		{Instruction: "RUN make -C /app"},
+	}
+	assert.Equal(t, expected, parseBuildkitSteps(progress))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/wagoodman/dive/internal/log"
 	"github.com/wagoodman/dive/internal/utils"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -11,6 +12,19 @@ import (
 
 // runDockerCmd runs a given Docker command in the current tty
 func runDockerCmd(cmdStr string, args ...string) error {
+	return runDockerCmdWithOutput(nil, cmdStr, args...)
+}
+
+// runDockerCmdWithOutput runs a given Docker command, writing all output to the given writer (or to the current tty
+// when no writer is given)
+func runDockerCmdWithOutput(out io.Writer, cmdStr string, args ...string) error {
+	return runDockerCmdWithCapture(out, nil, cmdStr, args...)
+}
+
+// runDockerCmdWithCapture runs a given Docker command like runDockerCmdWithOutput, additionally capturing all output in
+// the given writer (e.g. to parse the progress of a build). The tty input is only attached when no output writer is
+// given, so that a command with captured output does not compete for input.
+func runDockerCmdWithCapture(out, capture io.Writer, cmdStr string, args ...string) error {
 
 	if !isDockerClientBinaryAvailable() {
 		return fmt.Errorf("cannot find docker client executable")
@@ -24,9 +38,24 @@ func runDockerCmd(cmdStr string, args ...string) error {
 	cmd := exec.Command("docker", allArgs...)
 	cmd.Env = os.Environ()
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	switch {
+	case capture != nil:
+		if out == nil {
+			out = os.Stderr
+			cmd.Stdin = os.Stdin
+		}
+		// a single writer, so that stdout and stderr are not copied concurrently
+		w := io.MultiWriter(out, capture)
+		cmd.Stdout = w
+		cmd.Stderr = w
+	case out != nil:
+		cmd.Stdout = out
+		cmd.Stderr = out
+	default:
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+	}
 
 	return cmd.Run()
 }
@@ -3,11 +3,43 @@ package docker
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wagoodman/dive/dive/image"
 )
 
 type config struct {
-	History []historyEntry `json:"history"`
-	RootFs  rootFs         `json:"rootfs"`
+	History      []historyEntry  `json:"history"`
+	RootFs       rootFs          `json:"rootfs"`
+	Architecture string          `json:"architecture"`
+	OS           string          `json:"os"`
+	Variant      string          `json:"variant"`
+	Created      string          `json:"created"`
+	Author       string          `json:"author"`
+	Config       containerConfig `json:"config"`
+}
+
+// containerConfig is the runtime configuration of containers started from the image
+type containerConfig struct {
+	User         string              `json:"User"`
+	WorkingDir   string              `json:"WorkingDir"`
+	Env          []string            `json:"Env"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	Cmd          []string            `json:"Cmd"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+	Volumes      map[string]struct{} `json:"Volumes"`
+	Labels       map[string]string   `json:"Labels"`
+	StopSignal   string              `json:"StopSignal"`
+	Healthcheck  *healthcheck        `json:"Healthcheck"`
+}
+
+type healthcheck struct {
+	Test        []string      `json:"Test"`
+	Interval    time.Duration `json:"Interval"`
+	Timeout     time.Duration `json:"Timeout"`
+	StartPeriod time.Duration `json:"StartPeriod"`
+	Retries     int           `json:"Retries"`
 }
 
 type rootFs struct {
@@ -44,6 +76,57 @@ func newConfig(configBytes []byte) config {
 	return imageConfig
 }
 
+func (c config) metadata() image.Metadata {
+	metadata := image.Metadata{
+		Architecture: c.Architecture,
+		OS:           c.OS,
+		Variant:      c.Variant,
+		Created:      c.Created,
+		Author:       c.Author,
+		User:         c.Config.User,
+		WorkingDir:   c.Config.WorkingDir,
+		Env:          nonNil(c.Config.Env),
+		Entrypoint:   nonNil(c.Config.Entrypoint),
+		Cmd:          nonNil(c.Config.Cmd),
+		ExposedPorts: sortedKeys(c.Config.ExposedPorts),
+		Volumes:      sortedKeys(c.Config.Volumes),
+		Labels:       c.Config.Labels,
+		StopSignal:   c.Config.StopSignal,
+	}
+
+	if metadata.Labels == nil {
+		metadata.Labels = make(map[string]string)
+	}
+
+	if hc := c.Config.Healthcheck; hc != nil {
+		metadata.Healthcheck = &image.Healthcheck{
+			Test:        hc.Test,
+			Interval:    hc.Interval,
+			Timeout:     hc.Timeout,
+			StartPeriod: hc.StartPeriod,
+			Retries:     hc.Retries,
+		}
+	}
+
+	return metadata
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 func isConfig(configBytes []byte) bool {
 	var imageConfig config
 	err := json.Unmarshal(configBytes, &imageConfig)
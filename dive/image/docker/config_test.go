@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+func Test_Config_Metadata(t *testing.T) {
+	configBytes := []byte(`{
+  "architecture": "arm64",
+  "os": "linux",
+  "variant": "v8",
+  "created": "2024-01-02T03:04:05Z",
+  "author": "someone",
+  "config": {
+    "User": "app:app",
+    "WorkingDir": "/app",
+    "Env": ["PATH=/usr/bin", "APP_ENV=production"],
+    "Entrypoint": ["/app/server"],
+    "Cmd": ["--port", "8080"],
+    "ExposedPorts": {"8080/tcp": {}, "443/tcp": {}},
+    "Volumes": {"/data": {}},
+    "Labels": {"org.opencontainers.image.source": "https://github.com/wagoodman/dive"},
+    "StopSignal": "SIGTERM",
+    "Healthcheck": {"Test": ["CMD", "/app/healthcheck"], "Interval": 30000000000, "Timeout": 5000000000, "Retries": 3}
+  },
+  "rootfs": {"type": "layers", "diff_ids": []},
+  "history": []
+}`)
+
+	actual := newConfig(configBytes).metadata()
+
+	expected := image.Metadata{
+		Architecture: "arm64",
+		OS:           "linux",
+		Variant:      "v8",
+		Created:      "2024-01-02T03:04:05Z",
+		Author:       "someone",
+		User:         "app:app",
+		WorkingDir:   "/app",
+		Env:          []string{"PATH=/usr/bin", "APP_ENV=production"},
+		Entrypoint:   []string{"/app/server"},
+		Cmd:          []string{"--port", "8080"},
+		ExposedPorts: []string{"443/tcp", "8080/tcp"},
+		Volumes:      []string{"/data"},
+		Labels:       map[string]string{"org.opencontainers.image.source": "https://github.com/wagoodman/dive"},
+		StopSignal:   "SIGTERM",
+		Healthcheck: &image.Healthcheck{
+			Test:     []string{"CMD", "/app/healthcheck"},
+			Interval: 30 * time.Second,
+			Timeout:  5 * time.Second,
+			Retries:  3,
+		},
+	}
+
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, "linux/arm64/v8", actual.Platform())
+	assert.False(t, actual.RunsAsRoot())
+}
+
+func Test_Config_Metadata_Empty(t *testing.T) {
+	actual := newConfig([]byte(`{"rootfs": {"type": "layers", "diff_ids": []}, "history": []}`)).metadata()
+
+	assert.Empty(t, actual.Platform())
+	assert.True(t, actual.RunsAsRoot())
+	assert.NotNil(t, actual.Env)
+	assert.NotNil(t, actual.Labels)
+	assert.Nil(t, actual.Healthcheck)
+}
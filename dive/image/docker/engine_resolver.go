@@ -38,15 +38,23 @@ func (r *engineResolver) Fetch(ctx context.Context, id string) (*image.Image, er
 	}
 	defer reader.Close()
 
-	img, err := NewImageArchive(reader)
+	img, err := NewImageArchiveWithContext(ctx, reader)
 	if err != nil {
 		return nil, err
 	}
+
+	tags, err := r.fetchHistoryTags(ctx, id)
+	if err != nil {
+		log.WithFields("image", id, "error", err).Debug("unable to fetch image history tags")
+	} else {
+		img.SetHistoryTags(tags)
+	}
+
 	return img.ToImage(id)
 }
 
 func (r *engineResolver) Build(ctx context.Context, args []string) (*image.Image, error) {
-	id, err := buildImageFromCli(afero.NewOsFs(), args)
+	id, err := buildImageFromCli(afero.NewOsFs(), args, image.BuildOutput(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -66,44 +74,30 @@ func (r *engineResolver) Extract(ctx context.Context, id string, l string, p str
 	return fmt.Errorf("unable to extract from image '%s': %+v", id, err)
 }
 
-func (r *engineResolver) fetchArchive(ctx context.Context, id string) (io.ReadCloser, error) {
-	var err error
-	var dockerClient *client.Client
-
-	host, err := determineDockerHost()
+// fetchHistoryTags returns the tags of each history entry of the given image (in chronological order), which names
+// the intermediate images that are known to the engine (e.g. the base image).
+func (r *engineResolver) fetchHistoryTags(ctx context.Context, id string) ([][]string, error) {
+	dockerClient, err := newDockerClient()
 	if err != nil {
-		return nil, fmt.Errorf("could not determine docker host: %v", err)
+		return nil, err
 	}
-	clientOpts := []client.Opt{client.FromEnv}
-	clientOpts = append(clientOpts, client.WithHost(host))
+	defer dockerClient.Close()
 
-	switch strings.Split(host, ":")[0] {
-	case "ssh":
-		helper, err := connhelper.GetConnectionHelper(host)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get docker connection helper: %w", err)
-		}
-		clientOpts = append(clientOpts, func(c *client.Client) error {
-			httpClient := &http.Client{
-				Transport: &http.Transport{
-					DialContext: helper.Dialer,
-				},
-			}
-			return client.WithHTTPClient(httpClient)(c)
-		})
-
-		clientOpts = append(clientOpts, client.WithHost(host))
-		clientOpts = append(clientOpts, client.WithDialContext(helper.Dialer))
-
-	default:
+	history, err := dockerClient.ImageHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-		if os.Getenv("DOCKER_TLS_VERIFY") != "" && os.Getenv("DOCKER_CERT_PATH") == "" {
-			os.Setenv("DOCKER_CERT_PATH", "~/.docker")
-		}
+	// the engine reports history in reverse chronological order
+	tags := make([][]string, len(history))
+	for idx, item := range history {
+		tags[len(history)-1-idx] = item.Tags
 	}
+	return tags, nil
+}
 
-	clientOpts = append(clientOpts, client.WithAPIVersionNegotiation())
-	dockerClient, err = client.NewClientWithOpts(clientOpts...)
+func (r *engineResolver) fetchArchive(ctx context.Context, id string) (io.ReadCloser, error) {
+	dockerClient, err := newDockerClient()
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +130,44 @@ func (r *engineResolver) fetchArchive(ctx context.Context, id string) (io.ReadCl
 	return readCloser, nil
 }
 
+// newDockerClient creates a client for the docker engine at the currently configured docker host.
+func newDockerClient() (*client.Client, error) {
+	host, err := determineDockerHost()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine docker host: %v", err)
+	}
+	clientOpts := []client.Opt{client.FromEnv}
+	clientOpts = append(clientOpts, client.WithHost(host))
+
+	switch strings.Split(host, ":")[0] {
+	case "ssh":
+		helper, err := connhelper.GetConnectionHelper(host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get docker connection helper: %w", err)
+		}
+		clientOpts = append(clientOpts, func(c *client.Client) error {
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					DialContext: helper.Dialer,
+				},
+			}
+			return client.WithHTTPClient(httpClient)(c)
+		})
+
+		clientOpts = append(clientOpts, client.WithHost(host))
+		clientOpts = append(clientOpts, client.WithDialContext(helper.Dialer))
+
+	default:
+
+		if os.Getenv("DOCKER_TLS_VERIFY") != "" && os.Getenv("DOCKER_CERT_PATH") == "" {
+			os.Setenv("DOCKER_CERT_PATH", "~/.docker")
+		}
+	}
+
+	clientOpts = append(clientOpts, client.WithAPIVersionNegotiation())
+	return client.NewClientWithOpts(clientOpts...)
+}
+
 // determineDockerHost tries to the determine the docker host that we should connect to
 // in the following order of decreasing precedence:
 //   - value of "DOCKER_HOST" environment variable
@@ -4,29 +4,117 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/klauspost/compress/zstd"
 
+	"github.com/wagoodman/dive/dive/catalog"
 	"github.com/wagoodman/dive/dive/filetree"
 	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
 )
 
 type ImageArchive struct {
 	manifest manifest
 	config   config
-	layerMap map[string]*filetree.FileTree
+	// configDigest is the digest of the image config, which identifies the image content (the image ID)
+	configDigest string
+	layerMap     map[string]*filetree.FileTree
+	layerBlobs   map[string]layerBlob
+	// layerPackageFiles holds the contents of package databases and manifests found in each layer (see catalog)
+	layerPackageFiles map[string]map[string][]byte
+	// attestations are found as separate manifests within the index (these are not part of the image content)
+	attestations []image.Attestation
+	// tags holds the tags of each history entry (in chronological order, as in the config), when known by the engine
+	tags [][]string
 }
 
+// historyTags returns the tags known for the history entry at the given index (if any).
+func (img *ImageArchive) historyTags(histIdx int) []string {
+	if histIdx >= len(img.tags) {
+		return nil
+	}
+	return img.tags[histIdx]
+}
+
+// SetHistoryTags records the tags of each history entry, given in the same (chronological) order as the image config
+// history. Tags that do not line up with the image config history are ignored.
+func (img *ImageArchive) SetHistoryTags(tags [][]string) {
+	if len(tags) != len(img.config.History) {
+		log.WithFields("tags", len(tags), "history", len(img.config.History)).Debug("history tags do not match the image history, ignoring")
+		return
+	}
+	img.tags = tags
+}
+
+func toEmptyLayers(entries []historyEntry) []image.EmptyLayer {
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make([]image.EmptyLayer, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.toEmptyLayer())
+	}
+	return result
+}
+
+func appendUnique(values []string, additions ...string) []string {
+	for _, addition := range additions {
+		if addition == "" || addition == "<none>:<none>" || slices.Contains(values, addition) {
+			continue
+		}
+		values = append(values, addition)
+	}
+	return values
+}
+
+// layerBlob describes how a layer is stored within the image archive, which is what is transferred when pulling.
+type layerBlob struct {
+	size        uint64
+	compression string
+}
+
+// NewImageArchive reads an image from the given archive (without the contents of package files).
 func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
+	return NewImageArchiveWithContext(context.Background(), tarFile)
+}
+
+// NewImageArchiveWithContext reads an image from the given archive, keeping the contents of package databases and
+// manifests for cataloging only when requested (see image.WithPackageFiles).
+func NewImageArchiveWithContext(ctx context.Context, tarFile io.ReadCloser) (*ImageArchive, error) {
+	keepPackageFiles := image.PackageFilesRequested(ctx)
+
 	img := &ImageArchive{
-		layerMap: make(map[string]*filetree.FileTree),
+		layerMap:   make(map[string]*filetree.FileTree),
+		layerBlobs: make(map[string]layerBlob),
+
+		layerPackageFiles: make(map[string]map[string][]byte),
+	}
+
+	addLayer := func(tree *filetree.FileTree, packageFiles map[string][]byte, header *tar.Header, compression string) {
+		if compression == image.CompressionGzip && isEstargz(tree) {
+			compression = image.CompressionEstargz
+		}
+		// an uncompressed layer is compressed when pushed to a registry, so what is pulled is unknown
+		var size uint64
+		if compression != image.CompressionNone {
+			size = uint64(header.Size)
+		}
+		img.layerMap[tree.Name] = tree
+		img.layerPackageFiles[tree.Name] = packageFiles
+		img.layerBlobs[tree.Name] = layerBlob{
+			size:        size,
+			compression: compression,
+		}
 	}
 
 	tarReader := tar.NewReader(tarFile)
@@ -55,13 +143,13 @@ func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
 			if strings.HasSuffix(name, ".tar") {
 				currentLayer++
 				layerReader := tar.NewReader(tarReader)
-				tree, err := processLayerTar(name, layerReader)
+				tree, packageFiles, err := processLayerTar(name, layerReader, keepPackageFiles)
 				if err != nil {
 					return img, err
 				}
 
 				// add the layer to the image
-				img.layerMap[tree.Name] = tree
+				addLayer(tree, packageFiles, header, image.CompressionNone)
 			} else if strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, "tgz") {
 				currentLayer++
 
@@ -75,13 +163,13 @@ func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
 				layerReader := tar.NewReader(gz)
 
 				// Process layer
-				tree, err := processLayerTar(name, layerReader)
+				tree, packageFiles, err := processLayerTar(name, layerReader, keepPackageFiles)
 				if err != nil {
 					return img, err
 				}
 
 				// add the layer to the image
-				img.layerMap[tree.Name] = tree
+				addLayer(tree, packageFiles, header, image.CompressionGzip)
 			} else if strings.HasSuffix(name, ".json") || strings.HasPrefix(name, "sha256:") {
 				fileBuffer, err := io.ReadAll(tarReader)
 				if err != nil {
@@ -111,11 +199,11 @@ func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
 				gzipReader, err := gzip.NewReader(originalReader())
 				if err == nil {
 					layerReader := tar.NewReader(gzipReader)
-					tree, err := processLayerTar(name, layerReader)
+					tree, packageFiles, err := processLayerTar(name, layerReader, keepPackageFiles)
 					if err == nil {
 						currentLayer++
 						// add the layer to the image
-						img.layerMap[tree.Name] = tree
+						addLayer(tree, packageFiles, header, image.CompressionGzip)
 						continue
 					}
 				}
@@ -124,22 +212,22 @@ func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
 				zstdReader, err := zstd.NewReader(originalReader())
 				if err == nil {
 					layerReader := tar.NewReader(zstdReader)
-					tree, err := processLayerTar(name, layerReader)
+					tree, packageFiles, err := processLayerTar(name, layerReader, keepPackageFiles)
 					if err == nil {
 						currentLayer++
 						// add the layer to the image
-						img.layerMap[tree.Name] = tree
+						addLayer(tree, packageFiles, header, image.CompressionZstd)
 						continue
 					}
 				}
 
 				// Try reading a plain tar layer
 				layerReader := tar.NewReader(originalReader())
-				tree, err := processLayerTar(name, layerReader)
+				tree, packageFiles, err := processLayerTar(name, layerReader, keepPackageFiles)
 				if err == nil {
 					currentLayer++
 					// add the layer to the image
-					img.layerMap[tree.Name] = tree
+					addLayer(tree, packageFiles, header, image.CompressionNone)
 					continue
 				}
 
@@ -160,14 +248,33 @@ func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
 		}
 	}
 
+	var index imageIndex
+	if indexContent, exists := jsonFiles["index.json"]; exists {
+		index = newImageIndex(indexContent, jsonFiles)
+		img.attestations = index.attestations
+	}
+
 	manifestContent, exists := jsonFiles["manifest.json"]
 	if exists {
 		img.manifest = newManifest(manifestContent)
+	} else if imageManifest, ok := index.imageManifest(jsonFiles); ok {
+		// the OCI index describes the image precisely (and tells images apart from attestations)
+		var layerPaths []string
+		for _, layer := range imageManifest.Layers {
+			layerPaths = append(layerPaths, blobPath(layer.Digest))
+		}
+		img.manifest = manifest{
+			ConfigPath:    blobPath(imageManifest.Config.Digest),
+			LayerTarPaths: layerPaths,
+		}
 	} else {
 		// manifest.json is not part of the OCI spec, docker includes it for compatibility
 		// Provide compatibility by finding the config and using our layerMap
 		var configPath string
 		for path, content := range jsonFiles {
+			if index.attestationBlobs[path] {
+				continue
+			}
 			if isConfig(content) {
 				configPath = path
 				break
@@ -193,40 +300,51 @@ func NewImageArchive(tarFile io.ReadCloser) (*ImageArchive, error) {
 	}
 
 	img.config = newConfig(configContent)
+	img.configDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(configContent))
 
 	return img, nil
 }
 
-func processLayerTar(name string, reader *tar.Reader) (*filetree.FileTree, error) {
+func processLayerTar(name string, reader *tar.Reader, keepPackageFiles bool) (*filetree.FileTree, map[string][]byte, error) {
 	tree := filetree.NewFileTree()
 	tree.Name = name
 
-	fileInfos, err := getFileList(reader)
+	fileInfos, packageFiles, err := getFileList(reader, keepPackageFiles)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	filetree.ResolveHardlinks(fileInfos)
 
 	for _, element := range fileInfos {
 		tree.FileSize += uint64(element.Size)
 
 		_, _, err := tree.AddPath(element.Path, element)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return tree, nil
+	return tree, packageFiles, nil
 }
 
-func getFileList(tarReader *tar.Reader) ([]filetree.FileInfo, error) {
+// isEstargz indicates if the given (gzip compressed) layer is an eStargz layer, which carries a table of contents.
+func isEstargz(tree *filetree.FileTree) bool {
+	_, err := tree.GetNode("/stargz.index.json")
+	return err == nil
+}
+
+// getFileList reads all entries of a layer tar. When requested, the contents of package databases and manifests are
+// kept as well (keyed by absolute path) so the installed software can be cataloged later.
+func getFileList(tarReader *tar.Reader, keepPackageFiles bool) ([]filetree.FileInfo, map[string][]byte, error) {
 	var files []filetree.FileInfo
+	packageFiles := make(map[string][]byte)
 
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// always ensure relative path notations are not parsed as part of the filename
@@ -237,14 +355,24 @@ func getFileList(tarReader *tar.Reader) ([]filetree.FileInfo, error) {
 
 		switch header.Typeflag {
 		case tar.TypeXGlobalHeader:
-			return nil, fmt.Errorf("unexpected tar file: (XGlobalHeader): type=%v name=%s", header.Typeflag, name)
+			return nil, nil, fmt.Errorf("unexpected tar file: (XGlobalHeader): type=%v name=%s", header.Typeflag, name)
 		case tar.TypeXHeader:
-			return nil, fmt.Errorf("unexpected tar file (XHeader): type=%v name=%s", header.Typeflag, name)
+			return nil, nil, fmt.Errorf("unexpected tar file (XHeader): type=%v name=%s", header.Typeflag, name)
 		default:
-			files = append(files, filetree.NewFileInfoFromTarHeader(tarReader, header, name))
+			var contents io.Reader = tarReader
+			filePath := "/" + strings.TrimPrefix(name, "/")
+			if keepPackageFiles && header.Typeflag == tar.TypeReg && header.Size <= catalog.MaxPackageFileSize && catalog.IsPackageFile(filePath) {
+				content, err := io.ReadAll(tarReader)
+				if err != nil {
+					return nil, nil, err
+				}
+				packageFiles[filePath] = content
+				contents = bytes.NewReader(content)
+			}
+			files = append(files, filetree.NewFileInfoFromTarHeader(contents, header, name))
 		}
 	}
-	return files, nil
+	return files, packageFiles, nil
 }
 
 func (img *ImageArchive) ToImage(id string) (*image.Image, error) {
@@ -264,39 +392,77 @@ func (img *ImageArchive) ToImage(id string) (*image.Image, error) {
 	layers := make([]*image.Layer, 0)
 
 	// note that the engineResolver config stores images in reverse chronological order, so iterate backwards through layers
-	// as you iterate chronologically through history (ignoring history items that have no layer contents)
+	// as you iterate chronologically through history. History items that have no layer contents (e.g. ENV or LABEL) are
+	// kept with the layer that precedes them (or, before the first layer, as the steps preceding it).
 	// Note: history is not required metadata in a docker image!
+	history := img.config.History
 	histIdx := 0
 	for idx, tree := range trees {
-		// ignore empty layers, we are only observing layers with content
+		var emptyEntries []historyEntry
+		var tags []string
+		for histIdx < len(history) && history[histIdx].EmptyLayer {
+			emptyEntries = append(emptyEntries, history[histIdx])
+			tags = append(tags, img.historyTags(histIdx)...)
+			histIdx++
+		}
+
+		if len(layers) > 0 {
+			previous := layers[len(layers)-1]
+			previous.EmptyLayers = append(previous.EmptyLayers, toEmptyLayers(emptyEntries)...)
+			previous.Names = appendUnique(previous.Names, tags...)
+			emptyEntries, tags = nil, nil
+		}
+
 		historyObj := historyEntry{
 			CreatedBy: "(missing)",
 		}
-		for nextHistIdx := histIdx; nextHistIdx < len(img.config.History); nextHistIdx++ {
-			if !img.config.History[nextHistIdx].EmptyLayer {
-				histIdx = nextHistIdx
-				break
-			}
-		}
-		if histIdx < len(img.config.History) && !img.config.History[histIdx].EmptyLayer {
-			historyObj = img.config.History[histIdx]
+		if histIdx < len(history) {
+			historyObj = history[histIdx]
+			tags = append(img.historyTags(histIdx), tags...)
 			histIdx++
 		}
 
 		historyObj.Size = tree.FileSize
 
+		// the diff-id is always known from the rootfs, even when history is missing
+		if historyObj.ID == "" && idx < len(img.config.RootFs.DiffIds) {
+			historyObj.ID = img.config.RootFs.DiffIds[idx]
+		}
+
 		dockerLayer := layer{
 			history: historyObj,
 			index:   idx,
 			tree:    tree,
+			blob:    img.layerBlobs[img.manifest.LayerTarPaths[idx]],
+			files:   img.layerPackageFiles[img.manifest.LayerTarPaths[idx]],
+			names:   appendUnique(nil, tags...),
+		}
+		imgLayer := dockerLayer.ToLayer()
+		imgLayer.PrecedingEmptyLayers = toEmptyLayers(emptyEntries)
+		layers = append(layers, imgLayer)
+	}
+
+	if len(layers) > 0 {
+		last := layers[len(layers)-1]
+		for ; histIdx < len(history); histIdx++ {
+			if !history[histIdx].EmptyLayer {
+				continue
+			}
+			last.EmptyLayers = append(last.EmptyLayers, history[histIdx].toEmptyLayer())
+			last.Names = appendUnique(last.Names, img.historyTags(histIdx)...)
 		}
-		layers = append(layers, dockerLayer.ToLayer())
+
+		// the repo tags in the manifest name the whole image, which is represented by the last layer
+		last.Names = appendUnique(last.Names, img.manifest.RepoTags...)
 	}
 
 	return &image.Image{
-		Request: id,
-		Trees:   trees,
-		Layers:  layers,
+		Request:      id,
+		Digest:       img.configDigest,
+		Trees:        trees,
+		Layers:       layers,
+		Metadata:     img.config.metadata(),
+		Attestations: img.attestations,
 	}, nil
 }
 
@@ -12,9 +12,12 @@ func Test_Analysis(t *testing.T) {
 		userSizeBytes uint64
 		wastedBytes   uint64
 		wastedPercent float64
+		compressed    uint64
+		duplicate     uint64
 		path          string
 	}{
-		"docker-image": {0.9844212134184309, 1220598, 66237, 32025, 0.4834911001404049, "../../../.data/test-docker-image.tar"},
+		// the layers are uncompressed in the archive, so the compressed (wire) size is unknown
+		"docker-image": {0.9844212134184309, 1220598, 66237, 32025, 0.4834911001404049, 0, 25620, "../../../.data/test-docker-image.tar"},
 	}
 
 	for name, test := range table {
@@ -36,6 +39,14 @@ func Test_Analysis(t *testing.T) {
 			t.Errorf("%s.%s: expected wastedPercent=%v, got %v", t.Name(), name, test.wastedPercent, result.WastedUserPercent)
 		}
 
+		if result.CompressedSizeBytes != test.compressed {
+			t.Errorf("%s.%s: expected compressedSizeBytes=%v, got %v", t.Name(), name, test.compressed, result.CompressedSizeBytes)
+		}
+
+		if result.DuplicateBytes != test.duplicate {
+			t.Errorf("%s.%s: expected duplicateBytes=%v, got %v", t.Name(), name, test.duplicate, result.DuplicateBytes)
+		}
+
 		if result.Efficiency != test.efficiency {
 			t.Errorf("%s.%s: expected efficiency=%v, got %v", t.Name(), name, test.efficiency, result.Efficiency)
 		}
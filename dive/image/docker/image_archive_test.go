@@ -0,0 +1,278 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+func Test_NewImageArchive_LayerCompression(t *testing.T) {
+	tests := []struct {
+		name        string
+		compress    func(t *testing.T, layer []byte) []byte
+		files       []string
+		compression string
+	}{
+		{
+			name:        "plain",
+			compress:    func(_ *testing.T, layer []byte) []byte { return layer },
+			files:       []string{"etc/hosts"},
+			compression: image.CompressionNone,
+		},
+		{
+			name:        "gzip",
+			compress:    gzipBytes,
+			files:       []string{"etc/hosts"},
+			compression: image.CompressionGzip,
+		},
+		{
+			name:        "estargz",
+			compress:    gzipBytes,
+			files:       []string{"etc/hosts", "stargz.index.json"},
+			compression: image.CompressionEstargz,
+		},
+		{
+			name: "zstd",
+			compress: func(t *testing.T, layer []byte) []byte {
+				var buf bytes.Buffer
+				w, err := zstd.NewWriter(&buf)
+				require.NoError(t, err)
+				_, err = w.Write(layer)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+				return buf.Bytes()
+			},
+			files:       []string{"etc/hosts"},
+			compression: image.CompressionZstd,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blob := test.compress(t, tarBytes(t, test.files))
+			archive := tarBytes(t, nil,
+				entry{"blobs/sha256/config", []byte(`{"rootfs":{"type":"layers","diff_ids":["sha256:abc"]},"history":[{"created_by":"COPY . ."}]}`)},
+				entry{"blobs/sha256/layer", blob},
+				entry{"manifest.json", []byte(`[{"Config":"blobs/sha256/config","Layers":["blobs/sha256/layer"]}]`)},
+			)
+
+			img, err := NewImageArchive(io.NopCloser(bytes.NewReader(archive)))
+			require.NoError(t, err)
+
+			result, err := img.ToImage("test")
+			require.NoError(t, err)
+			require.Len(t, result.Layers, 1)
+
+			layer := result.Layers[0]
+			require.Equal(t, test.compression, layer.Compression)
+			if test.compression == image.CompressionNone {
+				// an uncompressed layer is compressed when pushed, so the size pulled is unknown
+				require.Zero(t, layer.CompressedSize)
+			} else {
+				require.Equal(t, uint64(len(blob)), layer.CompressedSize)
+			}
+		})
+	}
+}
+
+func Test_NewImageArchive_PackageFiles(t *testing.T) {
+	archive := tarBytes(t, nil,
+		entry{"blobs/sha256/config", []byte(`{"rootfs":{"type":"layers","diff_ids":["sha256:abc"]},"history":[{"created_by":"COPY . ."}]}`)},
+		entry{"blobs/sha256/layer", tarBytes(t, []string{"etc/hosts", "etc/os-release"})},
+		entry{"manifest.json", []byte(`[{"Config":"blobs/sha256/config","Layers":["blobs/sha256/layer"]}]`)},
+	)
+
+	packageFiles := func(ctx context.Context) map[string][]byte {
+		img, err := NewImageArchiveWithContext(ctx, io.NopCloser(bytes.NewReader(archive)))
+		require.NoError(t, err)
+		result, err := img.ToImage("test")
+		require.NoError(t, err)
+		require.Len(t, result.Layers, 1)
+		return result.Layers[0].PackageFiles
+	}
+
+	// package files are only kept when requested
+	require.Empty(t, packageFiles(context.Background()))
+	require.Equal(t, map[string][]byte{"/etc/os-release": []byte("etc/os-release")}, packageFiles(image.WithPackageFiles(context.Background())))
+}
+
+type entry struct {
+	name    string
+	content []byte
+}
+
+// tarBytes creates a tar with the given (empty) files followed by the given entries.
+func tarBytes(t *testing.T, files []string, entries ...entry) []byte {
+	t.Helper()
+	for _, f := range files {
+		entries = append(entries, entry{name: f, content: []byte(f)})
+	}
+
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, e := range entries {
+		require.NoError(t, w.WriteHeader(&tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}))
+		_, err := w.Write(e.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, layer []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(layer)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func Test_ToImage_History(t *testing.T) {
+	config := `{
+  "rootfs": {"type": "layers", "diff_ids": ["sha256:base", "sha256:app"]},
+  "history": [
+    {"created": "2024-01-01T00:00:00Z", "created_by": "/bin/sh -c #(nop) ARG VERSION", "empty_layer": true},
+    {"created": "2024-01-01T00:00:01Z", "created_by": "/bin/sh -c #(nop) ADD file:abc in / "},
+    {"created": "2024-01-01T00:00:02Z", "created_by": "/bin/sh -c #(nop)  CMD [\"/bin/sh\"]", "empty_layer": true},
+    {"created": "2024-01-01T00:01:00Z", "created_by": "ENV APP=1", "empty_layer": true, "author": "someone"},
+    {"created": "2024-01-01T00:05:00Z", "created_by": "/bin/sh -c make install", "author": "someone"},
+    {"created": "2024-01-01T00:05:01Z", "created_by": "CMD [\"app\"]", "empty_layer": true}
+  ]
+}`
+	archive := tarBytes(t, nil,
+		entry{"blobs/sha256/config", []byte(config)},
+		entry{"blobs/sha256/base", tarBytes(t, []string{"bin/sh"})},
+		entry{"blobs/sha256/app", tarBytes(t, []string{"usr/bin/app"})},
+		entry{"manifest.json", []byte(`[{"Config":"blobs/sha256/config","RepoTags":["app:latest"],"Layers":["blobs/sha256/base","blobs/sha256/app"]}]`)},
+	)
+
+	img, err := NewImageArchive(io.NopCloser(bytes.NewReader(archive)))
+	require.NoError(t, err)
+
+	// as reported by the engine, the base image is tagged on its last history entry
+	img.SetHistoryTags([][]string{nil, nil, {"alpine:3.19"}, nil, nil, {"app:latest", "app:1.0"}})
+
+	result, err := img.ToImage("test")
+	require.NoError(t, err)
+	require.Len(t, result.Layers, 2)
+
+	base, app := result.Layers[0], result.Layers[1]
+
+	require.Equal(t, "#(nop) ADD file:abc in / ", base.Command)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), base.Created)
+	require.Equal(t, []string{"alpine:3.19"}, base.Names)
+	// the leading ARG precedes the first layer rather than following it
+	require.Equal(t, []image.EmptyLayer{
+		{Command: "ARG VERSION", Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, base.PrecedingEmptyLayers)
+	require.Equal(t, []image.EmptyLayer{
+		{Command: `CMD ["/bin/sh"]`, Created: time.Date(2024, 1, 1, 0, 0, 2, 0, time.UTC)},
+		{Command: "ENV APP=1", Created: time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC), Author: "someone"},
+	}, base.EmptyLayers)
+
+	require.Equal(t, "make install", app.Command)
+	require.Equal(t, "someone", app.Author)
+	require.Equal(t, []string{"app:latest", "app:1.0"}, app.Names)
+	require.Equal(t, []image.EmptyLayer{
+		{Command: `CMD ["app"]`, Created: time.Date(2024, 1, 1, 0, 5, 1, 0, time.UTC)},
+	}, app.EmptyLayers)
+}
+
+func Test_NewImageArchive_Attestations(t *testing.T) {
+	provenance := `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "predicate": {
+    "materials": [
+      {"uri": "pkg:docker/alpine@3.19?platform=linux%2Famd64", "digest": {"sha256": "aaaa"}},
+      {"uri": "https://github.com/wagoodman/dive.git", "digest": {"sha1": "bbbb"}}
+    ]
+  }
+}`
+	sbom := `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "predicateType": "https://spdx.dev/Document",
+  "predicate": {"spdxVersion": "SPDX-2.3", "packages": [{"name": "busybox"}, {"name": "musl"}, {"name": "zlib"}]}
+}`
+	attestationManifest := `{
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "sha256:attconfig"},
+  "layers": [
+    {"mediaType": "application/vnd.in-toto+json", "digest": "sha256:provenance", "size": 400},
+    {"mediaType": "application/vnd.in-toto+json", "digest": "sha256:sbom", "size": 200}
+  ]
+}`
+	imageManifest := `{
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "sha256:config"},
+  "layers": [
+    {"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": "sha256:layer1"},
+    {"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": "sha256:layer2"}
+  ]
+}`
+	nestedIndex := `{
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:othermanifest", "platform": {"architecture": "arm64", "os": "linux"}},
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:attmanifest", "annotations": {"vnd.docker.reference.type": "attestation-manifest", "vnd.docker.reference.digest": "sha256:manifest"}},
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:manifest", "platform": {"architecture": "amd64", "os": "linux"}}
+  ]
+}`
+	index := `{"manifests": [{"mediaType": "application/vnd.oci.image.index.v1+json", "digest": "sha256:index"}]}`
+
+	archive := tarBytes(t, nil,
+		// the attestation config is listed first and looks like an image config, but must not be used as one
+		entry{"blobs/sha256/attconfig", []byte(`{"architecture":"unknown","os":"unknown","rootfs":{"type":"layers","diff_ids":["sha256:provenance","sha256:sbom"]}}`)},
+		entry{"blobs/sha256/provenance", []byte(provenance)},
+		entry{"blobs/sha256/sbom", []byte(sbom)},
+		entry{"blobs/sha256/attmanifest", []byte(attestationManifest)},
+		entry{"blobs/sha256/layer1", tarBytes(t, []string{"bin/sh"})},
+		entry{"blobs/sha256/layer2", tarBytes(t, []string{"usr/bin/app"})},
+		entry{"blobs/sha256/config", []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":["sha256:diff1","sha256:diff2"]},"history":[{"created_by":"ADD base"},{"created_by":"COPY app"}]}`)},
+		entry{"blobs/sha256/manifest", []byte(imageManifest)},
+		entry{"blobs/sha256/index", []byte(nestedIndex)},
+		entry{"index.json", []byte(index)},
+	)
+
+	img, err := NewImageArchive(io.NopCloser(bytes.NewReader(archive)))
+	require.NoError(t, err)
+
+	result, err := img.ToImage("test")
+	require.NoError(t, err)
+
+	require.Equal(t, "linux/amd64", result.Metadata.Platform())
+	require.Len(t, result.Layers, 2)
+	require.Equal(t, "ADD base", result.Layers[0].Command)
+	require.Equal(t, "COPY app", result.Layers[1].Command)
+
+	require.Equal(t, []image.Attestation{
+		{
+			Kind:          image.AttestationProvenance,
+			PredicateType: "https://slsa.dev/provenance/v0.2",
+			Subject:       "sha256:manifest",
+			SizeBytes:     400,
+			Materials: []image.Material{
+				{URI: "pkg:docker/alpine@3.19?platform=linux%2Famd64", Digest: "sha256:aaaa"},
+				{URI: "https://github.com/wagoodman/dive.git", Digest: "sha1:bbbb"},
+			},
+		},
+		{
+			Kind:          image.AttestationSBOM,
+			PredicateType: "https://spdx.dev/Document",
+			Subject:       "sha256:manifest",
+			SizeBytes:     200,
+			SBOMFormat:    "spdx",
+			PackageCount:  3,
+		},
+	}, result.Attestations)
+}
@@ -0,0 +1,236 @@
+package docker
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
+)
+
+const (
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// BuildKit marks attestation manifests within the index with these annotations
+	annotationReferenceType   = "vnd.docker.reference.type"
+	annotationReferenceDigest = "vnd.docker.reference.digest"
+	referenceTypeAttestation  = "attestation-manifest"
+
+	predicateTypeSLSAPrefix = "https://slsa.dev/provenance/"
+	predicateTypeSPDX       = "https://spdx.dev/Document"
+	predicateTypeCycloneDX  = "https://cyclonedx.org/bom"
+)
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociIndex struct {
+	MediaType string       `json:"mediaType"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    descriptor   `json:"config"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// inTotoStatement is the envelope of every attestation layer, the predicate depends on the predicate type.
+type inTotoStatement struct {
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// imageIndex is the result of walking the OCI index of an image archive.
+type imageIndex struct {
+	manifests    []ociManifest
+	attestations []image.Attestation
+	// attestationBlobs are all blobs that belong to attestations (which must never be mistaken for image content)
+	attestationBlobs map[string]bool
+}
+
+// blobPath is the path within an OCI layout archive where the blob with the given digest is stored.
+func blobPath(digest string) string {
+	algorithm, encoded, ok := strings.Cut(digest, ":")
+	if !ok {
+		return ""
+	}
+	return path.Join("blobs", algorithm, encoded)
+}
+
+// newImageIndex walks the given index (and any nested indexes) collecting all image manifests and attestations that
+// are present in the archive. Manifests of other platforms are commonly referenced but not saved, so are skipped.
+func newImageIndex(indexContent []byte, jsonFiles map[string][]byte) imageIndex {
+	result := imageIndex{
+		attestationBlobs: make(map[string]bool),
+	}
+
+	var walk func(content []byte)
+	walk = func(content []byte) {
+		var index ociIndex
+		if err := json.Unmarshal(content, &index); err != nil {
+			log.WithFields("error", err).Debug("unable to parse image index")
+			return
+		}
+
+		for _, desc := range index.Manifests {
+			content, exists := jsonFiles[blobPath(desc.Digest)]
+			if !exists {
+				continue
+			}
+
+			switch {
+			case desc.MediaType == mediaTypeOCIIndex || desc.MediaType == mediaTypeDockerManifestList:
+				walk(content)
+			case desc.Annotations[annotationReferenceType] == referenceTypeAttestation:
+				result.addAttestations(desc, content, jsonFiles)
+			default:
+				var manifest ociManifest
+				if err := json.Unmarshal(content, &manifest); err != nil {
+					log.WithFields("digest", desc.Digest, "error", err).Debug("unable to parse image manifest")
+					continue
+				}
+				result.manifests = append(result.manifests, manifest)
+			}
+		}
+	}
+	walk(indexContent)
+
+	return result
+}
+
+// imageManifest returns the first image manifest for which the config is present in the archive.
+func (idx imageIndex) imageManifest(jsonFiles map[string][]byte) (ociManifest, bool) {
+	for _, manifest := range idx.manifests {
+		if _, exists := jsonFiles[blobPath(manifest.Config.Digest)]; exists {
+			return manifest, true
+		}
+	}
+	return ociManifest{}, false
+}
+
+func (idx *imageIndex) addAttestations(desc descriptor, content []byte, jsonFiles map[string][]byte) {
+	idx.attestationBlobs[blobPath(desc.Digest)] = true
+
+	var manifest ociManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		log.WithFields("digest", desc.Digest, "error", err).Debug("unable to parse attestation manifest")
+		return
+	}
+	idx.attestationBlobs[blobPath(manifest.Config.Digest)] = true
+
+	for _, layer := range manifest.Layers {
+		idx.attestationBlobs[blobPath(layer.Digest)] = true
+
+		statementContent, exists := jsonFiles[blobPath(layer.Digest)]
+		if !exists {
+			continue
+		}
+
+		attestation, err := newAttestation(statementContent)
+		if err != nil {
+			log.WithFields("digest", layer.Digest, "error", err).Debug("unable to parse attestation")
+			continue
+		}
+		attestation.Subject = desc.Annotations[annotationReferenceDigest]
+		attestation.SizeBytes = uint64(layer.Size)
+
+		idx.attestations = append(idx.attestations, attestation)
+	}
+}
+
+// newAttestation summarizes the given in-toto statement.
+func newAttestation(content []byte) (image.Attestation, error) {
+	var statement inTotoStatement
+	if err := json.Unmarshal(content, &statement); err != nil {
+		return image.Attestation{}, err
+	}
+
+	attestation := image.Attestation{
+		Kind:          image.AttestationUnknown,
+		PredicateType: statement.PredicateType,
+	}
+
+	switch {
+	case strings.HasPrefix(statement.PredicateType, predicateTypeSLSAPrefix):
+		attestation.Kind = image.AttestationProvenance
+		materials, err := provenanceMaterials(statement.Predicate)
+		if err != nil {
+			return image.Attestation{}, err
+		}
+		attestation.Materials = materials
+	case statement.PredicateType == predicateTypeSPDX:
+		var predicate struct {
+			Packages []json.RawMessage `json:"packages"`
+		}
+		if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+			return image.Attestation{}, err
+		}
+		attestation.Kind = image.AttestationSBOM
+		attestation.SBOMFormat = "spdx"
+		attestation.PackageCount = len(predicate.Packages)
+	case statement.PredicateType == predicateTypeCycloneDX:
+		var predicate struct {
+			Components []json.RawMessage `json:"components"`
+		}
+		if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+			return image.Attestation{}, err
+		}
+		attestation.Kind = image.AttestationSBOM
+		attestation.SBOMFormat = "cyclonedx"
+		attestation.PackageCount = len(predicate.Components)
+	}
+
+	return attestation, nil
+}
+
+type provenanceMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// provenanceMaterials extracts the build inputs from either a SLSA v0.2 ("materials") or v1 ("resolvedDependencies")
+// provenance predicate.
+func provenanceMaterials(raw json.RawMessage) ([]image.Material, error) {
+	var predicate struct {
+		Materials       []provenanceMaterial `json:"materials"`
+		BuildDefinition struct {
+			ResolvedDependencies []provenanceMaterial `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+	}
+	if err := json.Unmarshal(raw, &predicate); err != nil {
+		return nil, err
+	}
+
+	materials := make([]image.Material, 0)
+	for _, m := range append(predicate.Materials, predicate.BuildDefinition.ResolvedDependencies...) {
+		materials = append(materials, image.Material{
+			URI:    m.URI,
+			Digest: materialDigest(m.Digest),
+		})
+	}
+	return materials, nil
+}
+
+// materialDigest picks a single digest to represent the material, preferring sha256.
+func materialDigest(digests map[string]string) string {
+	if value, ok := digests["sha256"]; ok {
+		return "sha256:" + value
+	}
+	if len(digests) == 0 {
+		return ""
+	}
+	algorithms := make([]string, 0, len(digests))
+	for algorithm := range digests {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	return algorithms[0] + ":" + digests[algorithms[0]]
+}
@@ -2,9 +2,11 @@ package docker
 
 import (
 	"strings"
+	"time"
 
 	"github.com/wagoodman/dive/dive/filetree"
 	"github.com/wagoodman/dive/dive/image"
+	"github.com/wagoodman/dive/internal/log"
 )
 
 // Layer represents a Docker image layer and metadata
@@ -12,19 +14,49 @@ type layer struct {
 	history historyEntry
 	index   int
 	tree    *filetree.FileTree
+	blob    layerBlob
+	names   []string
+	files   map[string][]byte
 }
 
 // String represents a layer in a columnar format.
 func (l *layer) ToLayer() *image.Layer {
 	id := strings.Split(l.tree.Name, "/")[0]
 	return &image.Layer{
-		Id:      id,
-		Index:   l.index,
-		Command: strings.TrimPrefix(l.history.CreatedBy, "/bin/sh -c "),
-		Size:    l.history.Size,
-		Tree:    l.tree,
-		// todo: query docker api for tags
-		Names:  []string{"(unavailable)"},
-		Digest: l.history.ID,
+		Id:             id,
+		Index:          l.index,
+		Command:        strings.TrimPrefix(l.history.CreatedBy, "/bin/sh -c "),
+		Size:           l.history.Size,
+		CompressedSize: l.blob.size,
+		Compression:    l.blob.compression,
+		Tree:           l.tree,
+		Names:          l.names,
+		Digest:         l.history.ID,
+		Created:        parseCreated(l.history.Created),
+		Author:         l.history.Author,
+		PackageFiles:   l.files,
 	}
 }
+
+// toEmptyLayer converts a history entry that did not produce any layer content.
+func (h historyEntry) toEmptyLayer() image.EmptyLayer {
+	command := strings.TrimPrefix(h.CreatedBy, "/bin/sh -c ")
+	command = strings.TrimSpace(strings.TrimPrefix(command, "#(nop)"))
+	return image.EmptyLayer{
+		Command: command,
+		Created: parseCreated(h.Created),
+		Author:  h.Author,
+	}
+}
+
+func parseCreated(created string) time.Time {
+	if created == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		log.WithFields("created", created, "error", err).Trace("unable to parse history timestamp")
+		return time.Time{}
+	}
+	return t
+}
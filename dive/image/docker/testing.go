@@ -2,8 +2,12 @@ package docker
 
 import (
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 	"golang.org/x/net/context"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/wagoodman/dive/dive/image"
@@ -32,3 +36,19 @@ func TestAnalysisFromArchive(t testing.TB, path string) *image.Analysis {
 	require.NoError(t, err, "unable to analyze image")
 	return result
 }
+
+var repoRootCache atomic.String
+
+// TestRepoPath resolves the given path (e.g. a test archive under .data) relative to the root of the repo.
+func TestRepoPath(t testing.TB, path string) string {
+	t.Helper()
+	root := repoRootCache.Load()
+	if root == "" {
+		// use git to find the root of the repo
+		out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+		require.NoError(t, err, "failed to get repo root")
+		root = strings.TrimSpace(string(out))
+		repoRootCache.Store(root)
+	}
+	return filepath.Join(root, path)
+}
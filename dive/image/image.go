@@ -6,6 +6,11 @@ import (
 
 type Image struct {
 	Request string
-	Trees   []*filetree.FileTree
-	Layers  []*Layer
+	// Digest identifies the image content (the digest of the image config, also known as the image ID), if known
+	Digest   string
+	Trees    []*filetree.FileTree
+	Layers   []*Layer
+	Metadata Metadata
+	// Attestations are the provenance and SBOM statements that were shipped alongside the image (if any)
+	Attestations []Attestation
 }
@@ -3,6 +3,7 @@ package image
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 
@@ -10,7 +11,15 @@ import (
 )
 
 const (
-	LayerFormat = "%7s  %s"
+	LayerFormat = "%7s  %7s  %s"
+)
+
+// compression algorithms of layer blobs, as found in the image archive
+const (
+	CompressionNone    = "none"
+	CompressionGzip    = "gzip"
+	CompressionZstd    = "zstd"
+	CompressionEstargz = "estargz"
 )
 
 type Layer struct {
@@ -18,9 +27,36 @@ type Layer struct {
 	Index   int
 	Command string
 	Size    uint64
-	Tree    *filetree.FileTree
-	Names   []string
-	Digest  string
+	// CompressedSize is the size of the layer blob as stored in the image archive (what is pulled over the wire), which
+	// is unknown (zero) when the archive holds the layer uncompressed
+	CompressedSize uint64
+	Compression    string
+	Tree           *filetree.FileTree
+	Names          []string
+	Digest         string
+	Created        time.Time
+	Author         string
+	// PackageFiles holds the contents of the package databases and manifests added by this layer (keyed by path), which
+	// are only read when requested (see WithPackageFiles)
+	PackageFiles map[string][]byte
+	// EmptyLayers are the build steps recorded after this layer (and before the next) that did not change the filesystem
+	EmptyLayers []EmptyLayer
+	// PrecedingEmptyLayers are the build steps recorded before the first layer that did not change the filesystem (e.g.
+	// an ARG declared before FROM), which are only set on the first layer
+	PrecedingEmptyLayers []EmptyLayer
+	// BuildCache indicates if the layer was reused from the build cache or rebuilt (only known for images built by dive)
+	BuildCache BuildCache
+}
+
+// EmptyLayer is a build history entry that only changed image metadata (e.g. ENV, LABEL or CMD), so has no layer content.
+type EmptyLayer struct {
+	Command string
+	Created time.Time
+	Author  string
+}
+
+func (l EmptyLayer) String() string {
+	return fmt.Sprintf(LayerFormat, "", "", strings.Replace(l.Command, "\n", "↵", -1))
 }
 
 func (l *Layer) ShortId() string {
@@ -34,6 +70,14 @@ func (l *Layer) ShortId() string {
 	return id
 }
 
+// CompressedBytes renders a compressed (wire) size, which is unknown when zero.
+func CompressedBytes(size uint64) string {
+	if size == 0 {
+		return "unknown"
+	}
+	return humanize.Bytes(size)
+}
+
 func (l *Layer) commandPreview() string {
 	// Layers using heredocs can be multiple lines; rendering relies on
 	// Layer.String to be a single line.
@@ -44,9 +88,11 @@ func (l *Layer) String() string {
 	if l.Index == 0 {
 		return fmt.Sprintf(LayerFormat,
 			humanize.Bytes(l.Size),
+			CompressedBytes(l.CompressedSize),
 			"FROM "+l.ShortId())
 	}
 	return fmt.Sprintf(LayerFormat,
 		humanize.Bytes(l.Size),
+		CompressedBytes(l.CompressedSize),
 		l.commandPreview())
 }
@@ -0,0 +1,52 @@
+package image
+
+import (
+	"strings"
+	"time"
+)
+
+// Metadata is the runtime configuration and descriptive information found in the image config.
+type Metadata struct {
+	Architecture string            `json:"architecture"`
+	OS           string            `json:"os"`
+	Variant      string            `json:"variant,omitempty"`
+	Created      string            `json:"created"`
+	Author       string            `json:"author,omitempty"`
+	User         string            `json:"user"`
+	WorkingDir   string            `json:"workingDir"`
+	Env          []string          `json:"env"`
+	Entrypoint   []string          `json:"entrypoint"`
+	Cmd          []string          `json:"cmd"`
+	ExposedPorts []string          `json:"exposedPorts"`
+	Volumes      []string          `json:"volumes"`
+	Labels       map[string]string `json:"labels"`
+	StopSignal   string            `json:"stopSignal,omitempty"`
+	Healthcheck  *Healthcheck      `json:"healthcheck,omitempty"`
+}
+
+// Healthcheck describes how the container runtime checks that a container is still working.
+type Healthcheck struct {
+	Test        []string      `json:"test"`
+	Interval    time.Duration `json:"interval"`
+	Timeout     time.Duration `json:"timeout"`
+	StartPeriod time.Duration `json:"startPeriod"`
+	Retries     int           `json:"retries"`
+}
+
+// Platform describes the os/architecture(/variant) the image was built for.
+func (m Metadata) Platform() string {
+	parts := []string{m.OS, m.Architecture}
+	if m.Variant != "" {
+		parts = append(parts, m.Variant)
+	}
+	if m.OS == "" && m.Architecture == "" {
+		return ""
+	}
+	return strings.Join(parts, "/")
+}
+
+// RunsAsRoot indicates if a container started from the image runs as the root user (the default when no user is set).
+func (m Metadata) RunsAsRoot() bool {
+	user := strings.SplitN(strings.TrimSpace(m.User), ":", 2)[0]
+	return user == "" || user == "root" || user == "0"
+}
@@ -0,0 +1,17 @@
+package image
+
+import "context"
+
+type packageFilesKey struct{}
+
+// WithPackageFiles returns a context that requests images to be read along with the contents of their package
+// databases and manifests (see Layer.PackageFiles), which are otherwise skipped since cataloging is rarely needed.
+func WithPackageFiles(ctx context.Context) context.Context {
+	return context.WithValue(ctx, packageFilesKey{}, true)
+}
+
+// PackageFilesRequested indicates if the contents of package files should be kept when reading an image.
+func PackageFilesRequested(ctx context.Context) bool {
+	requested, _ := ctx.Value(packageFilesKey{}).(bool)
+	return requested
+}
@@ -3,27 +3,70 @@
 package podman
 
 import (
+	"bytes"
+	"io"
 	"os"
+	"regexp"
+	"strings"
+
+	"github.com/wagoodman/dive/dive/image"
+)
+
+var (
+	// e.g. "STEP 2/4: RUN apk add curl", or "[2/2] STEP 2/4: RUN apk add curl" for multi-stage builds
+	podmanStepPattern   = regexp.MustCompile(`^(\[\d+/\d+\] )?STEP \d+/\d+: (.+)$`)
+	podmanCachedPattern = regexp.MustCompile(`^--> Using cache `)
 )
 
-func buildImageFromCli(buildArgs []string) (string, error) {
+// buildImageFromCli runs `podman build` with the given arguments, writing the build output to the given writer (or to
+// the current tty when no writer is given), and returns the ID of the built image. When requested, the build steps
+// are parsed from the build output.
+func buildImageFromCli(buildArgs []string, out io.Writer, withSteps bool) (string, []image.BuildStep, error) {
 	iidfile, err := os.CreateTemp("/tmp", "dive.*.iid")
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer os.Remove(iidfile.Name())
 	defer iidfile.Close()
 
+	var progress bytes.Buffer
+	var capture io.Writer
+	if withSteps {
+		capture = &progress
+	}
+
 	allArgs := append([]string{"--iidfile", iidfile.Name()}, buildArgs...)
-	err = runPodmanCmd("build", allArgs...)
+	err = runPodmanCmdWithCapture(out, capture, "build", allArgs...)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	imageId, err := os.ReadFile(iidfile.Name())
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return string(imageId), nil
+	var steps []image.BuildStep
+	if withSteps {
+		steps = parseBuildSteps(progress.String())
+	}
+
+	return string(imageId), steps, nil
+}
+
+// parseBuildSteps returns the Dockerfile steps from the output of `podman build`, noting which steps were satisfied
+// from the build cache.
+func parseBuildSteps(output string) []image.BuildStep {
+	var steps []image.BuildStep
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := podmanStepPattern.FindStringSubmatch(line); match != nil {
+			steps = append(steps, image.BuildStep{Instruction: match[2]})
+			continue
+		}
+		if podmanCachedPattern.MatchString(line) && len(steps) > 0 {
+			steps[len(steps)-1].Cached = true
+		}
+	}
+	return steps
 }
@@ -12,8 +12,16 @@ import (
 	"strings"
 )
 
-// runPodmanCmd runs a given Podman command in the current tty
-func runPodmanCmd(cmdStr string, args ...string) error {
+// runPodmanCmdWithOutput runs a given Podman command, writing all output to the given writer (or to the current tty
+// when no writer is given)
+func runPodmanCmdWithOutput(out io.Writer, cmdStr string, args ...string) error {
+	return runPodmanCmdWithCapture(out, nil, cmdStr, args...)
+}
+
+// runPodmanCmdWithCapture runs a given Podman command like runPodmanCmdWithOutput, additionally capturing all output in
+// the given writer (e.g. to parse the progress of a build). The tty input is only attached when no output writer is
+// given, so that a command with captured output does not compete for input.
+func runPodmanCmdWithCapture(out, capture io.Writer, cmdStr string, args ...string) error {
 	if !isPodmanClientBinaryAvailable() {
 		return fmt.Errorf("cannot find podman client executable")
 	}
@@ -26,9 +34,24 @@ func runPodmanCmd(cmdStr string, args ...string) error {
 	cmd := exec.Command("podman", allArgs...)
 	cmd.Env = os.Environ()
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	switch {
+	case capture != nil:
+		if out == nil {
+			out = os.Stdout
+			cmd.Stdin = os.Stdin
+		}
+		// a single writer, so that stdout and stderr are not copied concurrently
+		w := io.MultiWriter(out, capture)
+		cmd.Stdout = w
+		cmd.Stderr = w
+	case out != nil:
+		cmd.Stdout = out
+		cmd.Stderr = out
+	default:
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+	}
 
 	return cmd.Run()
 }
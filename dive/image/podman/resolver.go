@@ -11,29 +11,44 @@ import (
 	"github.com/wagoodman/dive/dive/image/docker"
 )
 
-type resolver struct{}
+type resolver struct {
+	// reportCache notes which layers of built images were reused from the build cache
+	reportCache bool
+}
 
 func NewResolverFromEngine() *resolver {
 	return &resolver{}
 }
 
+// NewResolverFromBuildx creates a resolver that notes which layers of built images were reused from the build cache
+// (podman supports the `buildx build` arguments of docker natively). Unlike with docker, built images are still read
+// back from the podman engine, since `podman build` cannot write build metadata (--metadata-file).
+func NewResolverFromBuildx() *resolver {
+	return &resolver{reportCache: true}
+}
+
 // Name returns the name of the resolver to display to the user.
 func (r *resolver) Name() string {
 	return "podman"
 }
 
 func (r *resolver) Build(ctx context.Context, args []string) (*image.Image, error) {
-	id, err := buildImageFromCli(args)
+	id, steps, err := buildImageFromCli(args, image.BuildOutput(ctx), r.reportCache)
+	if err != nil {
+		return nil, err
+	}
+	img, err := r.Fetch(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	return r.Fetch(ctx, id)
+	image.ApplyBuildSteps(img.Layers, steps)
+	return img, nil
 }
 
 func (r *resolver) Fetch(ctx context.Context, id string) (*image.Image, error) {
 	// todo: add podman fetch attempt via varlink first...
 
-	img, err := r.resolveFromDockerArchive(id)
+	img, err := r.resolveFromDockerArchive(ctx, id)
 	if err == nil {
 		return img, err
 	}
@@ -56,13 +71,13 @@ func (r *resolver) Extract(ctx context.Context, id string, l string, p string) e
 	return fmt.Errorf("unable to extract from image %q: %+v", id, err)
 }
 
-func (r *resolver) resolveFromDockerArchive(id string) (*image.Image, error) {
+func (r *resolver) resolveFromDockerArchive(ctx context.Context, id string) (*image.Image, error) {
 	err, reader := streamPodmanCmd("image", "save", id)
 	if err != nil {
 		return nil, err
 	}
 
-	img, err := docker.NewImageArchive(io.NopCloser(reader))
+	img, err := docker.NewImageArchiveWithContext(ctx, io.NopCloser(reader))
 	if err != nil {
 		return nil, err
 	}
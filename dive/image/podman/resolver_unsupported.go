@@ -16,6 +16,10 @@ func NewResolverFromEngine() *resolver {
 	return &resolver{}
 }
 
+func NewResolverFromBuildx() *resolver {
+	return &resolver{}
+}
+
 // Name returns the name of the resolver to display to the user.
 func (r *resolver) Name() string {
 	return "podman"
@@ -2,7 +2,6 @@ package parser
 
 import (
 	"fmt"
-	"github.com/wagoodman/dive/dive/image"
 	"github.com/wagoodman/dive/internal/bus/event"
 	"github.com/wagoodman/dive/internal/bus/event/payload"
 	"github.com/wagoodman/go-partybus"
@@ -54,17 +53,17 @@ func ParseTaskStarted(e partybus.Event) (progress.StagedProgressable, *payload.G
 	return mon, &source, nil
 }
 
-func ParseExploreAnalysis(e partybus.Event) (image.Analysis, image.ContentReader, error) {
+func ParseExploreAnalysis(e partybus.Event) (*payload.Explore, error) {
 	if err := checkEventType(e.Type, event.ExploreAnalysis); err != nil {
-		return image.Analysis{}, nil, err
+		return nil, err
 	}
 
 	ex, ok := e.Value.(payload.Explore)
 	if !ok {
-		return image.Analysis{}, nil, newPayloadErr(e.Type, "Value", e.Value)
+		return nil, newPayloadErr(e.Type, "Value", e.Value)
 	}
 
-	return ex.Analysis, ex.Content, nil
+	return &ex, nil
 }
 
 func ParseReport(e partybus.Event) (string, string, error) {
@@ -5,4 +5,15 @@ import "github.com/wagoodman/dive/dive/image"
 type Explore struct {
 	Analysis image.Analysis
 	Content  image.ContentReader
+	// Rebuilds reports each rebuild of the image while watching the build context (nil when not watching)
+	Rebuilds <-chan Rebuild
+}
+
+// Rebuild reports the progress of a rebuild of the explored image. A rebuild is first reported when it starts (with
+// only the Trigger set), then again once it has completed (with either the Analysis or the Err set).
+type Rebuild struct {
+	// Trigger is the changed path that caused the rebuild
+	Trigger  string
+	Analysis *image.Analysis
+	Err      error
 }
@@ -61,3 +61,12 @@ func ExploreAnalysis(analysis image.Analysis, reader image.ContentReader) {
 		Value: payload.Explore{Analysis: analysis, Content: reader},
 	})
 }
+
+// WatchAnalysis is ExploreAnalysis for an image that is rebuilt whenever its build context changes, where the
+// explored analysis is refreshed with the result of each rebuild.
+func WatchAnalysis(analysis image.Analysis, reader image.ContentReader, rebuilds <-chan payload.Rebuild) {
+	Publish(partybus.Event{
+		Type:  event.ExploreAnalysis,
+		Value: payload.Explore{Analysis: analysis, Content: reader, Rebuilds: rebuilds},
+	})
+}